@@ -0,0 +1,246 @@
+// Command summarydb-plan simulates the windowing of a stream before it is
+// created, and reports how many windows it keeps, how much storage they
+// take and the expected error of sum queries over time.
+//
+//	summarydb-plan -seq exponential:1.5 -rate 10000 -horizon 720h \
+//		-step 24h -query 1h@480h
+//
+// reports the windows of 30 days of 10k inserts/sec, and the error of a
+// 1-hour sum query 20 days ago.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"summarydb/core"
+	"summarydb/stats"
+	"summarydb/window"
+	"text/tabwriter"
+	"time"
+)
+
+type queryFlags []string
+
+func (queries *queryFlags) String() string {
+	return strings.Join(*queries, ",")
+}
+
+func (queries *queryFlags) Set(value string) error {
+	*queries = append(*queries, value)
+	return nil
+}
+
+func parseInt64s(args string) ([]int64, error) {
+	values := make([]int64, 0)
+	for _, arg := range strings.Split(args, ",") {
+		value, err := strconv.ParseInt(strings.TrimSpace(arg), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// Parses "name:args", e.g. exponential:1.5, power:1,1,1,1, uniform:100,
+// polynomial:1,0,1 or table:1000x1,500x10,0x1000.
+func parseLengthsSequence(spec string) (window.LengthsSequence, error) {
+	parts := strings.SplitN(spec, ":", 2)
+	if len(parts) != 2 {
+		return nil, errors.New("sequence must be name:args")
+	}
+	name, args := parts[0], parts[1]
+	switch name {
+	case "exponential":
+		base, err := strconv.ParseFloat(args, 64)
+		if err != nil {
+			return nil, err
+		}
+		return window.NewExponentialLengthsSequence(base), nil
+	case "power":
+		values, err := parseInt64s(args)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != 4 {
+			return nil, errors.New("power takes p,q,R,S")
+		}
+		return window.NewPowerLengthsSequence(values[0], values[1], values[2], values[3]), nil
+	case "uniform":
+		length, err := strconv.ParseInt(args, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		seq, err := window.NewUniformLengthsSequence(length)
+		if err != nil {
+			return nil, err
+		}
+		return seq, nil
+	case "polynomial":
+		coefficients, err := parseInt64s(args)
+		if err != nil {
+			return nil, err
+		}
+		seq, err := window.NewPolynomialLengthsSequence(coefficients)
+		if err != nil {
+			return nil, err
+		}
+		return seq, nil
+	case "table":
+		tiers := make([]window.LengthsTier, 0)
+		for _, arg := range strings.Split(args, ",") {
+			values, err := parseInt64s(strings.Replace(arg, "x", ",", 1))
+			if err != nil {
+				return nil, err
+			}
+			if len(values) != 2 {
+				return nil, errors.New("table tiers are countxlength")
+			}
+			tiers = append(tiers, window.LengthsTier{Count: values[0], Length: values[1]})
+		}
+		seq, err := window.NewTableLengthsSequence(tiers)
+		if err != nil {
+			return nil, err
+		}
+		return seq, nil
+	}
+	return nil, errors.New("unknown sequence: " + name)
+}
+
+func parseCIMethod(name string) (stats.CIMethod, error) {
+	switch name {
+	case "normal":
+		return stats.NormalCI, nil
+	case "student-t":
+		return stats.StudentTCI, nil
+	case "chebyshev":
+		return stats.ChebyshevCI, nil
+	case "hoeffding":
+		return stats.HoeffdingCI, nil
+	}
+	return stats.NormalCI, errors.New("unknown ci method: " + name)
+}
+
+// Parses "length@age", both durations.
+func parseQuery(spec string, unit time.Duration) (*core.PlanQuery, error) {
+	parts := strings.SplitN(spec, "@", 2)
+	if len(parts) != 2 {
+		return nil, errors.New("query must be length@age")
+	}
+	length, err := time.ParseDuration(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	age, err := time.ParseDuration(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	return &core.PlanQuery{
+		Age:    int64(age / unit),
+		Length: int64(length / unit),
+	}, nil
+}
+
+func run() error {
+	var queries queryFlags
+	seqSpec := flag.String("seq", "exponential:2", "lengths sequence, name:args")
+	decay := flag.String("decay", "count", "decay windows by count or time")
+	rate := flag.Float64("rate", 1000, "inserts per second")
+	unit := flag.Duration("unit", time.Second, "duration of one timestamp unit")
+	horizon := flag.Duration("horizon", 30*24*time.Hour, "time to simulate")
+	step := flag.Duration("step", 24*time.Hour, "interval between reports")
+	mean := flag.Float64("mean", 1, "mean of the inserted values")
+	fields := flag.Int("fields", 0, "fields of the inserted records, 0 for values")
+	confidence := flag.Float64("confidence", 0.95, "confidence level of the error")
+	ciName := flag.String("ci", "normal", "normal, student-t, chebyshev or hoeffding")
+	flag.Var(&queries, "query", "sum query as length@age, e.g. 1h@480h (repeatable)")
+	flag.Parse()
+
+	seq, err := parseLengthsSequence(*seqSpec)
+	if err != nil {
+		return err
+	}
+	windowing := window.NewWindowing(seq)
+	switch *decay {
+	case "count":
+	case "time":
+		windowing = window.NewTimeWindowing(windowing)
+	default:
+		return errors.New("decay must be count or time")
+	}
+	ciMethod, err := parseCIMethod(*ciName)
+	if err != nil {
+		return err
+	}
+	if *unit <= 0 {
+		return errors.New("unit must be positive")
+	}
+
+	config := &core.PlanConfig{
+		Rate:      *rate * unit.Seconds(),
+		Horizon:   int64(*horizon / *unit),
+		Step:      int64(*step / *unit),
+		MeanValue: *mean,
+		Fields:    *fields,
+		Queries:   make([]*core.PlanQuery, 0, len(queries)),
+		Params: &core.QueryParams{
+			ConfidenceLevel: *confidence,
+			SDMultiplier:    1,
+			CIMethod:        ciMethod,
+		},
+	}
+	for _, spec := range queries {
+		query, err := parseQuery(spec, *unit)
+		if err != nil {
+			return err
+		}
+		config.Queries = append(config.Queries, query)
+	}
+
+	plan, err := core.SimulateWindowing(windowing, config)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d bytes per window, %d per raw entry\n\n", plan.BytesPerWindow, plan.BytesPerEntry)
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprint(w, "time\telements\twindows\tbytes\traw bytes")
+	for _, spec := range queries {
+		fmt.Fprintf(w, "\t%s error", spec)
+	}
+	fmt.Fprintln(w)
+	for _, snapshot := range plan.Snapshots {
+		fmt.Fprintf(w, "%v\t%d\t%d\t%d\t%d",
+			time.Duration(snapshot.Time)*(*unit),
+			snapshot.Elements,
+			snapshot.Windows,
+			snapshot.Bytes,
+			snapshot.RawBytes)
+		// Queries older than the snapshot are left out of it.
+		estimates := make(map[*core.PlanQuery]*core.PlanQueryEstimate)
+		for _, estimate := range snapshot.Queries {
+			estimates[estimate.Query] = estimate
+		}
+		for _, query := range config.Queries {
+			estimate, ok := estimates[query]
+			if ok {
+				fmt.Fprintf(w, "\t%.4f%%", 100*estimate.RelativeError)
+			} else {
+				fmt.Fprint(w, "\t-")
+			}
+		}
+		fmt.Fprintln(w)
+	}
+	return w.Flush()
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "summarydb-plan:", err)
+		os.Exit(1)
+	}
+}
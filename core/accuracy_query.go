@@ -0,0 +1,193 @@
+package core
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"summarydb/stats"
+)
+
+// Share of the error of an accuracy-targeted query caused by a single
+// summary window that only partially overlaps the query range.
+type ErrorContribution struct {
+	// Span of the summary window.
+	TimeStart int64
+	TimeEnd   int64
+	// Part of [t0, t1] that falls inside the window.
+	OverlapStart int64
+	OverlapEnd   int64
+	// Estimated contribution of the window to the answer.
+	Estimate float64
+	Variance float64
+	// Half-width of the confidence interval of this window alone.
+	HalfWidth float64
+	// Fraction of the total half-width attributed to this window.
+	Share float64
+	// Set when this window alone exceeds the error budget.
+	ExceedsBudget bool
+}
+
+type AccuracyResult struct {
+	Result *AggResult
+	// Half-width of the confidence interval relative to the estimate.
+	RelativeError    float64
+	MaxRelativeError float64
+	TargetMet        bool
+	// Set when the summary estimate missed the target and the partially
+	// overlapping windows were replaced by landmarks holding their raw
+	// values, which makes the answer exact.
+	FromLandmarks bool
+	// Windows responsible for the error of the summary estimate, largest
+	// first. Empty when the summary estimate meets the target.
+	Contributions []*ErrorContribution
+}
+
+// Reads back the raw values of the elements numbered [countStart, countEnd].
+type RawValueReader func(countStart, countEnd int64) ([]Landmark, error)
+
+// Replaces the summary windows straddling t0 or t1 by landmark windows
+// holding their raw values. Folded windows are kept, the raw values miss
+// those folded in.
+func promotePartialWindows(
+	summaryWindows []*SummaryWindow,
+	landmarkWindows []*LandmarkWindow,
+	t0, t1 int64,
+	readRaw RawValueReader) ([]*SummaryWindow, []*LandmarkWindow, error) {
+	remaining := make([]*SummaryWindow, 0, len(summaryWindows))
+	promoted := make([]*LandmarkWindow, 0, len(landmarkWindows)+2)
+	promoted = append(promoted, landmarkWindows...)
+	for _, window := range summaryWindows {
+		if (t0 <= window.TimeStart && window.TimeEnd <= t1) || window.Folded {
+			remaining = append(remaining, window)
+			continue
+		}
+		landmarks, err := readRaw(window.CountStart, window.CountEnd)
+		if err != nil {
+			return nil, nil, err
+		}
+		landmarkWindow := NewLandmarkWindow(window.TimeStart)
+		landmarkWindow.Landmarks = landmarks
+		landmarkWindow.Close(window.TimeEnd)
+		promoted = append(promoted, landmarkWindow)
+	}
+	return remaining, promoted, nil
+}
+
+func relativeError(result *AggResult, value float64) float64 {
+	halfWidth := result.error / 2
+	if halfWidth == 0 {
+		return 0
+	}
+	if value == 0 {
+		return math.Inf(1)
+	}
+	return halfWidth / math.Abs(value)
+}
+
+func getErrorContributions(
+	op SumEstimatedOp,
+	summaryWindows []*SummaryWindow,
+	landmarkWindows []*LandmarkWindow,
+	t0, t1 int64,
+	value, maxRelativeError float64,
+	params *QueryParams) []*ErrorContribution {
+	_, firstWindow, _, lastWindow := getSumWindowInfos(t0, t1,
+		summaryWindows, landmarkWindows, op.GetSummaryData, op.GetLandmarkData)
+
+	infos := []*WindowInfo{firstWindow}
+	if len(summaryWindows) > 1 {
+		infos = append(infos, lastWindow)
+	}
+
+	contributions := make([]*ErrorContribution, 0)
+	totalHalfWidth := 0.0
+	for _, info := range infos {
+		if info.Overlap <= 0 || info.Overlap >= info.Length {
+			continue
+		}
+		bounds := &stats.Bounds{}
+		meanvar := &stats.Stats{}
+		UpdateEstimate(bounds, meanvar, info)
+		ci := stats.ConvertStatsBoundsToCIWithMethod(
+			bounds,
+			meanvar,
+			params.SDMultiplier,
+			params.ConfidenceLevel,
+			params.CIMethod)
+
+		halfWidth := (ci.UpperCI - ci.LowerCI) / 2
+		totalHalfWidth += halfWidth
+		contributions = append(contributions, &ErrorContribution{
+			TimeStart:     info.Start,
+			TimeEnd:       info.End,
+			OverlapStart:  stats.Int64Max(info.Start, t0),
+			OverlapEnd:    stats.Int64Min(info.End, t1),
+			Estimate:      meanvar.Mean,
+			Variance:      meanvar.Var,
+			HalfWidth:     halfWidth,
+			ExceedsBudget: halfWidth > maxRelativeError*math.Abs(value),
+		})
+	}
+
+	for _, contribution := range contributions {
+		if totalHalfWidth > 0 {
+			contribution.Share = contribution.HalfWidth / totalHalfWidth
+		}
+	}
+	sort.Slice(contributions, func(i, j int) bool {
+		return contributions[i].HalfWidth > contributions[j].HalfWidth
+	})
+	return contributions
+}
+
+// Answers a query over [t0, t1] and checks its relative error against
+// maxRelativeError. If the summary windows cannot meet the target, the
+// windows blowing the error budget are reported and, when readRaw is not
+// nil, the query is answered again from their raw values.
+func QueryWithAccuracy(
+	op Op,
+	summaryWindows []*SummaryWindow,
+	landmarkWindows []*LandmarkWindow,
+	t0, t1 int64,
+	maxRelativeError float64,
+	params *QueryParams,
+	readRaw RawValueReader) (*AccuracyResult, error) {
+	sumOp, ok := op.(SumEstimatedOp)
+	if !ok {
+		return nil, errors.New("op does not support accuracy-targeted queries")
+	}
+	if maxRelativeError < 0 {
+		return nil, errors.New("max relative error must be non-negative")
+	}
+
+	result := op.Query(summaryWindows, landmarkWindows, t0, t1, params)
+	value := sumOp.GetSummaryData(result.value)
+	accuracy := &AccuracyResult{
+		Result:           result,
+		RelativeError:    relativeError(result, value),
+		MaxRelativeError: maxRelativeError,
+		Contributions:    make([]*ErrorContribution, 0),
+	}
+	if accuracy.RelativeError <= maxRelativeError {
+		accuracy.TargetMet = true
+		return accuracy, nil
+	}
+
+	accuracy.Contributions = getErrorContributions(sumOp,
+		summaryWindows, landmarkWindows, t0, t1, value, maxRelativeError, params)
+	if readRaw == nil {
+		return accuracy, nil
+	}
+
+	summaryWindows, landmarkWindows, err := promotePartialWindows(
+		summaryWindows, landmarkWindows, t0, t1, readRaw)
+	if err != nil {
+		return nil, err
+	}
+	result = op.Query(summaryWindows, landmarkWindows, t0, t1, params)
+	accuracy.Result = result
+	accuracy.RelativeError = relativeError(result, sumOp.GetSummaryData(result.value))
+	accuracy.TargetMet = accuracy.RelativeError <= maxRelativeError
+	accuracy.FromLandmarks = true
+	return accuracy, nil
+}
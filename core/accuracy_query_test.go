@@ -0,0 +1,102 @@
+package core
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func getAccuracyTestWindows() []*SummaryWindow {
+	summaryWindows := make([]*SummaryWindow, 0)
+	for i := int64(0); i < 5; i++ {
+		summaryWindow := NewSummaryWindow(i*10, (i+1)*10-1, i*10, (i+1)*10-1)
+		summaryWindow.Data.Count.Value = 10
+		summaryWindows = append(summaryWindows, summaryWindow)
+	}
+	return summaryWindows
+}
+
+func TestQueryWithAccuracy_TargetMet(t *testing.T) {
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+
+	// Aligned to window boundaries, so the answer is exact.
+	result, err := QueryWithAccuracy(NewCountOp(), getAccuracyTestWindows(),
+		[]*LandmarkWindow{}, 10, 39, 0.02, params, nil)
+	assert.NoError(t, err)
+	assert.True(t, result.TargetMet)
+	assert.False(t, result.FromLandmarks)
+	assert.Equal(t, 30.0, result.Result.value.Count.Value)
+	assert.Equal(t, 0.0, result.RelativeError)
+	assert.Empty(t, result.Contributions)
+}
+
+func TestQueryWithAccuracy_Contributions(t *testing.T) {
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+
+	// Cuts through the first and the last window, the first one barely.
+	result, err := QueryWithAccuracy(NewCountOp(), getAccuracyTestWindows(),
+		[]*LandmarkWindow{}, 5, 48, 0.02, params, nil)
+	assert.NoError(t, err)
+	assert.False(t, result.TargetMet)
+	assert.Greater(t, result.RelativeError, 0.02)
+	assert.Len(t, result.Contributions, 2)
+
+	first := result.Contributions[0]
+	assert.Equal(t, int64(0), first.TimeStart)
+	assert.Equal(t, int64(9), first.TimeEnd)
+	assert.Equal(t, int64(5), first.OverlapStart)
+	assert.Equal(t, int64(9), first.OverlapEnd)
+	assert.Equal(t, 5.0, first.Estimate)
+	assert.Equal(t, 2.5, first.Variance)
+	assert.True(t, first.ExceedsBudget)
+
+	last := result.Contributions[1]
+	assert.Equal(t, int64(40), last.TimeStart)
+	assert.Equal(t, int64(40), last.OverlapStart)
+	assert.Equal(t, int64(48), last.OverlapEnd)
+	assert.InDelta(t, 0.9, last.Variance, 1e-9)
+	assert.Greater(t, first.HalfWidth, last.HalfWidth)
+	assert.InDelta(t, 1.0, first.Share+last.Share, 1e-9)
+
+	// A looser target is met by the same estimate.
+	result, err = QueryWithAccuracy(NewCountOp(), getAccuracyTestWindows(),
+		[]*LandmarkWindow{}, 5, 48, 0.5, params, nil)
+	assert.NoError(t, err)
+	assert.True(t, result.TargetMet)
+}
+
+func TestQueryWithAccuracy_RawFallback(t *testing.T) {
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	// Element i of every window is at timestamp i, with value 1.
+	readRaw := func(countStart, countEnd int64) ([]Landmark, error) {
+		landmarks := make([]Landmark, 0)
+		for i := countStart; i <= countEnd; i++ {
+			landmarks = append(landmarks, Landmark{Timestamp: i, Value: 1})
+		}
+		return landmarks, nil
+	}
+
+	result, err := QueryWithAccuracy(NewCountOp(), getAccuracyTestWindows(),
+		[]*LandmarkWindow{}, 5, 48, 0.02, params, readRaw)
+	assert.NoError(t, err)
+	assert.True(t, result.TargetMet)
+	assert.True(t, result.FromLandmarks)
+	assert.Equal(t, 0.0, result.RelativeError)
+	assert.Equal(t, 44.0, result.Result.value.Count.Value)
+	// The windows that missed the target are still reported.
+	assert.Len(t, result.Contributions, 2)
+
+	readFailure := func(countStart, countEnd int64) ([]Landmark, error) {
+		return nil, errors.New("no raw values")
+	}
+	_, err = QueryWithAccuracy(NewCountOp(), getAccuracyTestWindows(),
+		[]*LandmarkWindow{}, 5, 48, 0.02, params, readFailure)
+	assert.Error(t, err)
+}
+
+func TestQueryWithAccuracy_UnsupportedOp(t *testing.T) {
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	_, err := QueryWithAccuracy(NewMaxOp(), getAccuracyTestWindows(),
+		[]*LandmarkWindow{}, 5, 48, 0.02, params, nil)
+	assert.Error(t, err)
+}
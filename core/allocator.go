@@ -47,6 +47,13 @@ func (iba *IngestBufferAllocator) SetMaxBuffers(maxBuffers int64) {
 	iba.cv.L.Unlock()
 }
 
+// Occupancy returns the number of buffers allocated, and the maximum.
+func (iba *IngestBufferAllocator) Occupancy() (int64, int64) {
+	iba.cv.L.Lock()
+	defer iba.cv.L.Unlock()
+	return iba.currentBuffers, iba.maxBuffers
+}
+
 // --- FOR TESTING ---
 
 type TestAllocator struct {
@@ -0,0 +1,230 @@
+package core
+
+import (
+	"errors"
+	"sync"
+)
+
+// BackpressurePolicy decides what AppendAsync does when the queue in front of
+// the stream is full.
+type BackpressurePolicy int
+
+const (
+	// Waits for room in the queue.
+	BlockOnBackpressure BackpressurePolicy = iota
+	// Fails the append with ErrBackpressure.
+	RejectOnBackpressure
+	// Drops the oldest queued value to make room.
+	DropOldestOnBackpressure
+	// Drops the value being appended.
+	DropNewestOnBackpressure
+)
+
+var ErrBackpressure = errors.New("append queue is full")
+
+// AppendMetrics describes how far the stream is behind its producers.
+type AppendMetrics struct {
+	// Values queued by AppendAsync, not yet appended.
+	QueueDepth    int
+	QueueCapacity int
+	// Values dropped or rejected as the queue was full.
+	NumDropped  uint64
+	NumRejected uint64
+	// Ingest buffers allocated out of the ones configured. Appends block
+	// while they are all in use.
+	BuffersInUse int64
+	MaxBuffers   int64
+}
+
+// asyncAppender queues values for a goroutine appending them to the stream.
+type asyncAppender struct {
+	queue  chan Landmark
+	policy BackpressurePolicy
+
+	mutex sync.Mutex
+	cv    *sync.Cond
+	// Values queued or being appended.
+	pending int
+	// First failed append since the last drain.
+	err         error
+	closed      bool
+	numDropped  uint64
+	numRejected uint64
+}
+
+func newAsyncAppender(queueSize int, policy BackpressurePolicy) *asyncAppender {
+	async := &asyncAppender{
+		queue:       make(chan Landmark, queueSize),
+		policy:      policy,
+		pending:     0,
+		err:         nil,
+		closed:      false,
+		numDropped:  0,
+		numRejected: 0,
+	}
+	async.cv = sync.NewCond(&async.mutex)
+	return async
+}
+
+func (async *asyncAppender) run(stream *Stream) {
+	for entry := range async.queue {
+		async.done(stream.Append(entry.Timestamp, entry.Value))
+	}
+}
+
+// done accounts for a value leaving the queue.
+func (async *asyncAppender) done(err error) {
+	async.mutex.Lock()
+	async.pending--
+	if err != nil && async.err == nil {
+		async.err = err
+	}
+	if async.pending == 0 {
+		async.cv.Broadcast()
+	}
+	async.mutex.Unlock()
+}
+
+func (async *asyncAppender) count(counter *uint64) {
+	async.mutex.Lock()
+	*counter++
+	async.mutex.Unlock()
+}
+
+func (async *asyncAppender) append(timestamp int64, value float64) error {
+	async.mutex.Lock()
+	if async.closed {
+		async.mutex.Unlock()
+		return errors.New("stream is closed")
+	}
+	async.pending++
+	async.mutex.Unlock()
+
+	entry := Landmark{Timestamp: timestamp, Value: value}
+	switch async.policy {
+	case BlockOnBackpressure:
+		async.queue <- entry
+		return nil
+	case DropOldestOnBackpressure:
+		for {
+			select {
+			case async.queue <- entry:
+				return nil
+			default:
+			}
+			select {
+			case <-async.queue:
+				async.count(&async.numDropped)
+				async.done(nil)
+			default:
+			}
+		}
+	}
+	select {
+	case async.queue <- entry:
+		return nil
+	default:
+	}
+	async.done(nil)
+	if async.policy == RejectOnBackpressure {
+		async.count(&async.numRejected)
+		return ErrBackpressure
+	}
+	async.count(&async.numDropped)
+	return nil
+}
+
+// drain waits for the queue to empty, and returns the first error appending
+// the values failed with since the last drain.
+func (async *asyncAppender) drain() error {
+	async.mutex.Lock()
+	defer async.mutex.Unlock()
+	for async.pending > 0 {
+		async.cv.Wait()
+	}
+	err := async.err
+	async.err = nil
+	return err
+}
+
+// close drains the queue, and stops the goroutine once it is empty.
+func (async *asyncAppender) close() error {
+	async.mutex.Lock()
+	if async.closed {
+		async.mutex.Unlock()
+		return nil
+	}
+	async.closed = true
+	async.mutex.Unlock()
+	err := async.drain()
+	close(async.queue)
+	return err
+}
+
+// reopen returns the appender for the next run of the stream, a new one with
+// the same queue size and policy if this one was closed.
+func (async *asyncAppender) reopen() *asyncAppender {
+	async.mutex.Lock()
+	defer async.mutex.Unlock()
+	if !async.closed {
+		return async
+	}
+	reopened := newAsyncAppender(cap(async.queue), async.policy)
+	reopened.numDropped = async.numDropped
+	reopened.numRejected = async.numRejected
+	return reopened
+}
+
+// SetAsyncAppend puts a queue of queueSize values in front of the stream, for
+// AppendAsync. policy decides what becomes of values appended while it is
+// full.
+func (stream *Stream) SetAsyncAppend(queueSize int, policy BackpressurePolicy) *Stream {
+	stream.async = newAsyncAppender(queueSize, policy)
+	return stream
+}
+
+// AppendAsync queues the value to be appended in the background, and returns
+// without waiting for the pipeline. Errors appending it are returned by
+// DrainAsync.
+func (stream *Stream) AppendAsync(timestamp int64, value float64) error {
+	if stream.async == nil {
+		return errors.New("async append not set")
+	}
+	if stream.readOnly {
+		return ErrReadOnly
+	}
+	if !stream.running {
+		return errors.New("stream is not running")
+	}
+	return stream.async.append(timestamp, value)
+}
+
+// DrainAsync waits for the values queued by AppendAsync to be appended, and
+// returns the first error any of them failed with since the last call.
+func (stream *Stream) DrainAsync() error {
+	if stream.async == nil {
+		return errors.New("async append not set")
+	}
+	return stream.async.drain()
+}
+
+func (stream *Stream) AppendMetrics() *AppendMetrics {
+	buffersInUse, maxBuffers := stream.pipeline.ingester.allocator.Occupancy()
+	metrics := &AppendMetrics{
+		QueueDepth:    0,
+		QueueCapacity: 0,
+		NumDropped:    0,
+		NumRejected:   0,
+		BuffersInUse:  buffersInUse,
+		MaxBuffers:    maxBuffers,
+	}
+	if stream.async != nil {
+		stream.async.mutex.Lock()
+		metrics.QueueDepth = len(stream.async.queue)
+		metrics.QueueCapacity = cap(stream.async.queue)
+		metrics.NumDropped = stream.async.numDropped
+		metrics.NumRejected = stream.async.numRejected
+		stream.async.mutex.Unlock()
+	}
+	return metrics
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"summarydb/window"
+	"testing"
+)
+
+func newAsyncTestStream(t *testing.T, dbPath string, policy BackpressurePolicy) (*DB, *Stream) {
+	err := os.RemoveAll(dbPath)
+	assert.NoError(t, err)
+	db, err := New(dbPath)
+	assert.NoError(t, err)
+	exp := window.NewExponentialLengthsSequence(2)
+	stream, err := db.NewStream([]string{"count", "sum"}, exp)
+	assert.NoError(t, err)
+	stream.SetConfig(&StoreConfig{
+		EachBufferSize:  8,
+		NumBuffer:       2,
+		WindowsPerMerge: 1,
+	})
+	stream.SetAsyncAppend(2, policy)
+	err = stream.Run()
+	assert.NoError(t, err)
+	return db, stream
+}
+
+func TestStream_AppendAsync_Block(t *testing.T) {
+	db, stream := newAsyncTestStream(t, "testdb_async_block", BlockOnBackpressure)
+	for i := 0; i < 200; i++ {
+		err := stream.AppendAsync(int64(i), 1)
+		assert.NoError(t, err)
+	}
+	err := stream.DrainAsync()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(200), stream.Statistics().NumValues)
+
+	metrics := stream.AppendMetrics()
+	assert.Equal(t, 0, metrics.QueueDepth)
+	assert.Equal(t, 2, metrics.QueueCapacity)
+	assert.Equal(t, int64(2), metrics.MaxBuffers)
+	assert.Equal(t, uint64(0), metrics.NumDropped)
+	err = db.Close()
+	assert.NoError(t, err)
+}
+
+// Appends timestamps 1 to 10 while the stream is held, so that the queue
+// fills up.
+func appendAsyncWhileHeld(t *testing.T, stream *Stream) []error {
+	errs := make([]error, 0)
+	stream.appendMutex.Lock()
+	for i := 1; i <= 10; i++ {
+		errs = append(errs, stream.AppendAsync(int64(i), 1))
+	}
+	assert.Equal(t, 2, stream.AppendMetrics().QueueDepth)
+	stream.appendMutex.Unlock()
+	err := stream.DrainAsync()
+	assert.NoError(t, err)
+	return errs
+}
+
+func TestStream_AppendAsync_Reject(t *testing.T) {
+	db, stream := newAsyncTestStream(t, "testdb_async_reject", RejectOnBackpressure)
+	errs := appendAsyncWhileHeld(t, stream)
+	rejected := uint64(0)
+	for _, err := range errs {
+		if err != nil {
+			assert.Equal(t, ErrBackpressure, err)
+			rejected++
+		}
+	}
+	assert.Equal(t, ErrBackpressure, errs[9])
+	metrics := stream.AppendMetrics()
+	assert.Equal(t, rejected, metrics.NumRejected)
+	assert.Equal(t, 10-rejected, stream.Statistics().NumValues)
+	err := db.Close()
+	assert.NoError(t, err)
+}
+
+func TestStream_AppendAsync_DropOldest(t *testing.T) {
+	db, stream := newAsyncTestStream(t, "testdb_async_drop_oldest", DropOldestOnBackpressure)
+	errs := appendAsyncWhileHeld(t, stream)
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	statistics := stream.Statistics()
+	assert.Equal(t, int64(10), statistics.LastArrivalTimestamp)
+	assert.Equal(t, 10-statistics.NumValues, stream.AppendMetrics().NumDropped)
+	err := db.Close()
+	assert.NoError(t, err)
+}
+
+func TestStream_AppendAsync_DropNewest(t *testing.T) {
+	db, stream := newAsyncTestStream(t, "testdb_async_drop_newest", DropNewestOnBackpressure)
+	errs := appendAsyncWhileHeld(t, stream)
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	statistics := stream.Statistics()
+	assert.Less(t, statistics.LastArrivalTimestamp, int64(10))
+	assert.Equal(t, 10-statistics.NumValues, stream.AppendMetrics().NumDropped)
+
+	// Closing appends the values still queued.
+	err := stream.AppendAsync(20, 1)
+	assert.NoError(t, err)
+	err = db.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20), stream.Statistics().LastArrivalTimestamp)
+}
+
+func TestStream_AppendAsync_NotRunning(t *testing.T) {
+	dbPath := "testdb_async_not_running"
+	err := os.RemoveAll(dbPath)
+	assert.NoError(t, err)
+	db, err := New(dbPath)
+	assert.NoError(t, err)
+	exp := window.NewExponentialLengthsSequence(2)
+	stream, err := db.NewStream([]string{"count", "sum"}, exp)
+	assert.NoError(t, err)
+	stream.SetAsyncAppend(2, BlockOnBackpressure)
+	err = stream.AppendAsync(0, 1)
+	assert.Error(t, err)
+
+	// Closed as by a previous run, which the next one starts over from.
+	err = stream.async.close()
+	assert.NoError(t, err)
+	err = stream.Run()
+	assert.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		err := stream.AppendAsync(int64(i), 1)
+		assert.NoError(t, err)
+	}
+	err = stream.DrainAsync()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), stream.Statistics().NumValues)
+	assert.Equal(t, 2, stream.AppendMetrics().QueueCapacity)
+	err = db.Close()
+	assert.NoError(t, err)
+}
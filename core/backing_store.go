@@ -19,15 +19,28 @@ type BackingStore struct {
 	summaryCache  *ristretto.Cache
 }
 
+const (
+	DefaultSummaryCacheSize  int64 = 1 << 28
+	DefaultLandmarkCacheSize int64 = 1 << 25
+)
+
 func NewBackingStore(backend storage.Backend, cacheEnabled bool) *BackingStore {
+	return NewBackingStoreWithCacheSizes(backend, cacheEnabled,
+		DefaultSummaryCacheSize, DefaultLandmarkCacheSize)
+}
+
+// NewBackingStoreWithCacheSizes sets the maximum cost, in bytes, of the
+// caches of summary and landmark windows.
+func NewBackingStoreWithCacheSizes(backend storage.Backend, cacheEnabled bool,
+	summaryCacheSize int64, landmarkCacheSize int64) *BackingStore {
 	landmarkCache, _ := ristretto.NewCache(&ristretto.Config{
 		NumCounters: 1e3,
-		MaxCost:     1 << 25,
+		MaxCost:     landmarkCacheSize,
 		BufferItems: 64,
 	})
 	summaryCache, _ := ristretto.NewCache(&ristretto.Config{
 		NumCounters: 1e6,
-		MaxCost:     1 << 28,
+		MaxCost:     summaryCacheSize,
 		BufferItems: 64,
 	})
 	// TODO: Cache for heap?
@@ -61,9 +74,17 @@ func (store *BackingStore) Get(streamID, windowID int64) (*SummaryWindow, error)
 	return window, nil
 }
 
+// Sets in ristretto are applied asynchronously, and a set for a key which
+// is already admitted is rejected. Deleting first makes sure a pending set
+// queued by an earlier Get cannot shadow the new value.
+func setCache(cache *ristretto.Cache, key []byte, value interface{}) {
+	cache.Del(key)
+	cache.Set(key, value, 1)
+}
+
 func (store *BackingStore) Put(streamID, windowID int64, window *SummaryWindow) error {
 	if store.cacheEnabled {
-		store.summaryCache.Set(storage.GetKey(false, streamID, windowID), window, 1)
+		setCache(store.summaryCache, storage.GetKey(false, streamID, windowID), window)
 	}
 	buf, err := SummaryWindowToBytes(window)
 	if err != nil {
@@ -79,16 +100,25 @@ func (store *BackingStore) Delete(streamID, windowID int64) error {
 	return store.backend.Delete(streamID, windowID)
 }
 
+// Drops windows rewritten behind the back of the store, e.g. by a migration.
+func (store *BackingStore) EvictCache(streamID int64, windowIDs []int64) {
+	if !store.cacheEnabled {
+		return
+	}
+	for _, swid := range windowIDs {
+		store.summaryCache.Del(storage.GetKey(false, streamID, swid))
+	}
+}
+
 func (store *BackingStore) MergeWindows(
 	streamID int64,
 	mergedWindow *SummaryWindow,
 	deletedWindowIDs []int64) error {
 
 	if store.cacheEnabled {
-		store.summaryCache.Set(
+		setCache(store.summaryCache,
 			storage.GetKey(false, streamID, mergedWindow.Id()),
-			mergedWindow,
-			1)
+			mergedWindow)
 
 		for _, swid := range deletedWindowIDs {
 			store.summaryCache.Del(storage.GetKey(false, streamID, swid))
@@ -123,7 +153,7 @@ func (store *BackingStore) GetLandmark(streamID, windowID int64) (*LandmarkWindo
 
 func (store *BackingStore) PutLandmark(streamID, windowID int64, window *LandmarkWindow) error {
 	if store.cacheEnabled {
-		store.landmarkCache.Set(storage.GetKey(true, streamID, windowID), window, 1)
+		setCache(store.landmarkCache, storage.GetKey(true, streamID, windowID), window)
 	}
 	buf, err := LandmarkWindowToBytes(window)
 	if err != nil {
@@ -191,7 +221,7 @@ func (store *BackingStore) WriterBrew(
 	streamID int64, count int64, timestamp int64,
 	windowID int64, window *SummaryWindow) error {
 	if store.cacheEnabled {
-		store.summaryCache.Set(storage.GetKey(false, streamID, windowID), window, 1)
+		setCache(store.summaryCache, storage.GetKey(false, streamID, windowID), window)
 	}
 	buf, err := SummaryWindowToBytes(window)
 	if err != nil {
@@ -207,9 +237,9 @@ func (store *BackingStore) MergerBrew(
 
 	if store.cacheEnabled {
 		for _, pm := range pendingMerges {
-			store.summaryCache.Set(
+			setCache(store.summaryCache,
 				storage.GetKey(false, streamID, pm.MergedWindow.Id()),
-				pm.MergedWindow, 1)
+				pm.MergedWindow)
 
 			for _, swid := range pm.DeletedIDs {
 				store.summaryCache.Del(storage.GetKey(false, streamID, swid))
@@ -240,3 +270,79 @@ func (store *BackingStore) MergerBrew(
 		pendingMergesBuffer,
 		heapBuf, indexBuf)
 }
+
+func (store *BackingStore) LandmarkBrew(
+	streamID int64, landmark *LandmarkWindow,
+	windows []*SummaryWindow) error {
+
+	if store.cacheEnabled {
+		setCache(store.landmarkCache, storage.GetKey(true, streamID, landmark.Id()), landmark)
+		for _, window := range windows {
+			setCache(store.summaryCache, storage.GetKey(false, streamID, window.Id()), window)
+		}
+	}
+
+	landmarkBuf, err := LandmarkWindowToBytes(landmark)
+	if err != nil {
+		return err
+	}
+	windowBufs, err := summaryWindowsToBytes(windows)
+	if err != nil {
+		return err
+	}
+	return store.backend.LandmarkBrew(streamID, landmark.Id(), landmarkBuf, windowBufs)
+}
+
+func (store *BackingStore) LandmarkFoldBrew(
+	streamID int64, landmarkID int64,
+	windows []*SummaryWindow) error {
+
+	if store.cacheEnabled {
+		store.landmarkCache.Del(storage.GetKey(true, streamID, landmarkID))
+		for _, window := range windows {
+			setCache(store.summaryCache, storage.GetKey(false, streamID, window.Id()), window)
+		}
+	}
+
+	windowBufs, err := summaryWindowsToBytes(windows)
+	if err != nil {
+		return err
+	}
+	return store.backend.LandmarkFoldBrew(streamID, landmarkID, windowBufs)
+}
+
+func (store *BackingStore) TruncateBrew(
+	streamID int64, windowIDs []int64, landmarkIDs []int64,
+	heap *tree.MinHeap, index *MergerIndex) error {
+
+	if store.cacheEnabled {
+		for _, swid := range windowIDs {
+			store.summaryCache.Del(storage.GetKey(false, streamID, swid))
+		}
+		for _, lwid := range landmarkIDs {
+			store.landmarkCache.Del(storage.GetKey(true, streamID, lwid))
+		}
+	}
+
+	heapBuf, err := HeapToBytes(heap)
+	if err != nil {
+		return err
+	}
+	indexBuf, err := MergerIndexToBytes(index)
+	if err != nil {
+		return err
+	}
+	return store.backend.TruncateBrew(streamID, windowIDs, landmarkIDs, heapBuf, indexBuf)
+}
+
+func summaryWindowsToBytes(windows []*SummaryWindow) (map[int64][]byte, error) {
+	windowBufs := make(map[int64][]byte, len(windows))
+	for _, window := range windows {
+		buf, err := SummaryWindowToBytes(window)
+		if err != nil {
+			return nil, err
+		}
+		windowBufs[window.Id()] = buf
+	}
+	return windowBufs, nil
+}
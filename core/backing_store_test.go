@@ -8,6 +8,7 @@ import (
 	"summarydb/storage"
 	"summarydb/tree"
 	"testing"
+	"time"
 )
 
 func GetSummaryWindow() *SummaryWindow {
@@ -37,6 +38,20 @@ func TestSummaryWindowSerialization(t *testing.T) {
 	assert.Equal(t, window, newWindow)
 }
 
+func TestSummaryWindowSerialization_Fields(t *testing.T) {
+	window := GetSummaryWindow()
+	window.Fields = []*DataTable{NewDataTable(), NewDataTable()}
+	window.Fields[0].Sum.Value = 1.5
+	window.Fields[1].Max.Value = 2.5
+	window.Folded = true
+	buf, err := SummaryWindowToBytes(window)
+	assert.NoError(t, err)
+	newWindow, err := BytesToSummaryWindow(buf)
+	assert.NoError(t, err)
+
+	assert.Equal(t, window, newWindow)
+}
+
 func TestLandmarkWindowSerialization(t *testing.T) {
 	window := GetLandmarkWindow()
 	buf, err := LandmarkWindowToBytes(window)
@@ -45,6 +60,13 @@ func TestLandmarkWindowSerialization(t *testing.T) {
 	assert.NoError(t, err)
 
 	assert.Equal(t, window, newWindow)
+
+	window.promote(10, 20)
+	buf, err = LandmarkWindowToBytes(window)
+	assert.NoError(t, err)
+	newWindow, err = BytesToLandmarkWindow(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, window, newWindow)
 }
 
 func TestInMemory(t *testing.T) {
@@ -67,6 +89,32 @@ func TestInMemory(t *testing.T) {
 	assert.Equal(t, landmarkWindow, newLandmarkWindow)
 }
 
+func TestCache_PendingSet(t *testing.T) {
+	backend := storage.NewInMemoryBackend()
+	store := NewBackingStore(backend, true)
+	oldWindow := GetSummaryWindow()
+	buf, err := SummaryWindowToBytes(oldWindow)
+	assert.NoError(t, err)
+	err = backend.Put(0, 1, buf)
+	assert.NoError(t, err)
+
+	// The miss queues a set of the old window, still pending when the new
+	// one is put.
+	window, err := store.Get(0, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, oldWindow.Data.Count.Value, window.Data.Count.Value)
+	newWindow := GetSummaryWindow()
+	newWindow.Data.Count.Value = 15.15
+	err = store.Put(0, 1, newWindow)
+	assert.NoError(t, err)
+
+	// Sets are applied in the background.
+	time.Sleep(100 * time.Millisecond)
+	window, err = store.Get(0, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 15.15, window.Data.Count.Value)
+}
+
 func GetIdentity() func(int) int {
 	return func(i int) int {
 		return i
@@ -185,16 +233,18 @@ func TestMergerIndexSerialization(t *testing.T) {
 		for i := 100; i >= 0; i -= 1 {
 			index.Put(int64(i), int64(2*i+1))
 		}
+		index.SetFirstCStart(7)
 		buf, err = MergerIndexToBytes(index)
 		assert.NoError(t, err)
 	}
 	{
 		index, err := BytesToMergerIndex(buf)
 		assert.NoError(t, err)
+		assert.Equal(t, int64(7), index.GetCStart(0))
 		idx := int64(0)
 		index.indexMap.Map(func(key tree.RbKey, val interface{}) bool {
 			assert.Equal(t, idx, key)
-			assert.Equal(t, 2*idx+1, val.(*MergerIndexItem).cEnd)
+			assert.Equal(t, 2*idx+1, val.(*MergerIndexItem).end)
 			idx += 1
 			return false
 		})
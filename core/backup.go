@@ -0,0 +1,362 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/dgraph-io/badger/v2"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"summarydb/storage"
+)
+
+// A backup starts with backupMagic, followed by records each starting with
+// their kind: the keys and values of the Badger store, then the WAL files,
+// then backupKindEnd.
+const backupMagic = "summarydb-backup-1"
+
+const (
+	backupKindKeyValue byte = iota
+	backupKindFile
+	backupKindEnd
+)
+
+// WAL file captured by a backup, read from path up to size.
+type backupFile struct {
+	name string
+	path string
+	size int64
+}
+
+// Backup writes a snapshot of the DB to w, which Restore turns back into a
+// DB. The streams are flushed and held while the snapshot is taken, then
+// ingestion goes on as the snapshot is written out: the windows, heaps,
+// merger indexes and metadata of the Badger store, and the WAL of each
+// stream up to the snapshot.
+//
+// Values held back by ReorderOutOfOrder are not in the WAL, so not in the
+// backup either.
+func (db *DB) Backup(w io.Writer) error {
+	backend, ok := db.backend.(*storage.BadgerBackend)
+	if !ok {
+		return errors.New("backup needs a badger backend")
+	}
+	snapshot, files, linkDir, err := db.snapshot(backend)
+	if linkDir != "" {
+		defer os.RemoveAll(linkDir)
+	}
+	if err != nil {
+		return err
+	}
+	defer snapshot.Discard()
+
+	bw := bufio.NewWriter(w)
+	_, err = bw.WriteString(backupMagic)
+	if err != nil {
+		return err
+	}
+	err = snapshot.Iterate(func(key, value []byte) error {
+		err := bw.WriteByte(backupKindKeyValue)
+		if err != nil {
+			return err
+		}
+		err = writeBackupBytes(bw, key)
+		if err != nil {
+			return err
+		}
+		return writeBackupBytes(bw, value)
+	})
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		err = writeBackupFile(bw, file)
+		if err != nil {
+			return err
+		}
+	}
+	err = bw.WriteByte(backupKindEnd)
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Flushes and holds every stream while the Badger snapshot is taken, and the
+// WAL files are linked to linkDir, so that they can be read once the streams
+// go on. A read-only DB is not written to, its WAL files are read in place.
+func (db *DB) snapshot(backend *storage.BadgerBackend) (
+	*storage.BadgerSnapshot, []backupFile, string, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	ids := make([]int64, 0, len(db.streams))
+	for id := range db.streams {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		stream := db.streams[id]
+		stream.appendMutex.Lock()
+		defer stream.appendMutex.Unlock()
+	}
+
+	linkDir := ""
+	if !db.readOnly {
+		var err error
+		linkDir, err = ioutil.TempDir(db.dirName, "backup-")
+		if err != nil {
+			return nil, nil, "", err
+		}
+	}
+	files := make([]backupFile, 0)
+	for _, id := range ids {
+		stream := db.streams[id]
+		if stream.running {
+			err := stream.flush()
+			if err != nil {
+				return nil, nil, linkDir, err
+			}
+		}
+		if !db.readOnly {
+			// The stream metadata carries the arrival statistics.
+			err := db.WriteStream(stream)
+			if err != nil {
+				return nil, nil, linkDir, err
+			}
+		}
+		walFiles, err := linkWAL(db.dirName, id, linkDir)
+		if err != nil {
+			return nil, nil, linkDir, err
+		}
+		files = append(files, walFiles...)
+	}
+	return backend.Snapshot(), files, linkDir, nil
+}
+
+// Lists the WAL files of a stream with their current size, hard linked to
+// linkDir unless empty. Writes go on at the end of the last file, and
+// truncating the WAL replaces its files rather than changing them.
+func linkWAL(dirName string, id int64, linkDir string) ([]backupFile, error) {
+	walDir := walPath(dirName, id)
+	fis, err := ioutil.ReadDir(walDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	files := make([]backupFile, 0, len(fis))
+	for _, fi := range fis {
+		if fi.IsDir() {
+			continue
+		}
+		name := path.Join(path.Base(walDir), fi.Name())
+		filePath := path.Join(walDir, fi.Name())
+		if linkDir != "" {
+			linkPath := path.Join(linkDir, path.Base(walDir)+"-"+fi.Name())
+			err = os.Link(filePath, linkPath)
+			if err != nil {
+				return nil, err
+			}
+			filePath = linkPath
+		}
+		files = append(files, backupFile{name: name, path: filePath, size: fi.Size()})
+	}
+	return files, nil
+}
+
+func writeBackupFile(bw *bufio.Writer, file backupFile) error {
+	f, err := os.Open(file.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	err = bw.WriteByte(backupKindFile)
+	if err != nil {
+		return err
+	}
+	err = writeBackupBytes(bw, []byte(file.name))
+	if err != nil {
+		return err
+	}
+	err = binary.Write(bw, binary.LittleEndian, uint64(file.size))
+	if err != nil {
+		return err
+	}
+	_, err = io.CopyN(bw, f, file.size)
+	return err
+}
+
+func writeBackupBytes(bw *bufio.Writer, buf []byte) error {
+	err := binary.Write(bw, binary.LittleEndian, uint64(len(buf)))
+	if err != nil {
+		return err
+	}
+	_, err = bw.Write(buf)
+	return err
+}
+
+// The buffer grows as the bytes are read, rather than being allocated from a
+// size which may be corrupt.
+func readBackupBytes(br *bufio.Reader) ([]byte, error) {
+	var size uint64
+	err := binary.Read(br, binary.LittleEndian, &size)
+	if err != nil {
+		return nil, err
+	}
+	if size > math.MaxInt64 {
+		return nil, errors.New("corrupt backup")
+	}
+	var buf bytes.Buffer
+	_, err = io.CopyN(&buf, br, int64(size))
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Restore writes the DB backed up to r by DB.Backup into dirName, which must
+// not hold a DB already. The restored DB is then opened with Open. On error,
+// dirName is left as it was found: removed if it didn't exist, else empty.
+func Restore(r io.Reader, dirName string) error {
+	fis, err := ioutil.ReadDir(dirName)
+	if err == nil && len(fis) > 0 {
+		return errors.New("directory is not empty")
+	}
+	created := os.IsNotExist(err)
+	err = restoreDir(r, dirName)
+	if err != nil {
+		if created {
+			_ = os.RemoveAll(dirName)
+		} else {
+			clearDir(dirName)
+		}
+	}
+	return err
+}
+
+func clearDir(dirName string) {
+	fis, _ := ioutil.ReadDir(dirName)
+	for _, fi := range fis {
+		_ = os.RemoveAll(path.Join(dirName, fi.Name()))
+	}
+}
+
+func restoreDir(r io.Reader, dirName string) error {
+	err := os.MkdirAll(dirName, 0777)
+	if err != nil {
+		return err
+	}
+	badgerDb, err := badger.Open(DefaultOptions().Badger(path.Join(dirName, "badger")))
+	if err != nil {
+		return err
+	}
+	backend := storage.NewBadgerBacked(badgerDb)
+	err = restore(bufio.NewReader(r), dirName, backend)
+	closeErr := backend.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+func restore(br *bufio.Reader, dirName string, backend *storage.BadgerBackend) error {
+	magic := make([]byte, len(backupMagic))
+	_, err := io.ReadFull(br, magic)
+	if err != nil || string(magic) != backupMagic {
+		return errors.New("not a backup")
+	}
+	loader := backend.NewLoader()
+	err = loadBackup(br, dirName, loader)
+	if err != nil {
+		loader.Cancel()
+		return err
+	}
+	return loader.Flush()
+}
+
+func loadBackup(br *bufio.Reader, dirName string, loader *storage.BadgerLoader) error {
+	for {
+		kind, err := br.ReadByte()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+		switch kind {
+		case backupKindKeyValue:
+			key, err := readBackupBytes(br)
+			if err != nil {
+				return err
+			}
+			value, err := readBackupBytes(br)
+			if err != nil {
+				return err
+			}
+			err = loader.Set(key, value)
+			if err != nil {
+				return err
+			}
+		case backupKindFile:
+			err := restoreFile(br, dirName)
+			if err != nil {
+				return err
+			}
+		case backupKindEnd:
+			return nil
+		default:
+			return errors.New("corrupt backup")
+		}
+	}
+}
+
+func restoreFile(br *bufio.Reader, dirName string) error {
+	name, err := readBackupBytes(br)
+	if err != nil {
+		return err
+	}
+	var size uint64
+	err = binary.Read(br, binary.LittleEndian, &size)
+	if err != nil {
+		return err
+	}
+	// Only WAL files, inside dirName.
+	fileName := string(name)
+	if size > math.MaxInt64 || !strings.HasPrefix(fileName, "wal-") ||
+		path.Clean(fileName) != fileName || strings.Contains(fileName, "..") {
+		return errors.New("corrupt backup")
+	}
+	filePath := path.Join(dirName, fileName)
+	err = os.MkdirAll(path.Dir(filePath), 0777)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	_, err = io.CopyN(f, br, int64(size))
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	err = f.Sync()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -29,6 +29,14 @@ func (op *CountOp) Merge(retData *DataTable, values []DataTable) {
 	}
 }
 
+func (op *CountOp) GetSummaryData(table *DataTable) float64 {
+	return table.Count.Value
+}
+
+func (op *CountOp) GetLandmarkData(_ float64) float64 {
+	return 1.0
+}
+
 func (op *CountOp) EmptyQuery() *AggResult {
 	return &AggResult{
 		value: NewDataTable(),
@@ -44,18 +52,15 @@ func (op *CountOp) Query(windows []*SummaryWindow,
 	bounds, meanvar := GetSumStats(t0, t1,
 		windows,
 		landmarkWindows,
-		func(table *DataTable) float64 {
-			return table.Count.Value
-		},
-		func(_ float64) float64 {
-			return 1.0
-		})
+		op.GetSummaryData,
+		op.GetLandmarkData)
 
-	ci := stats.ConvertStatsBoundsToCI(
+	ci := stats.ConvertStatsBoundsToCIWithMethod(
 		bounds,
 		meanvar,
 		params.SDMultiplier,
-		params.ConfidenceLevel)
+		params.ConfidenceLevel,
+		params.CIMethod)
 
 	aggData := NewDataTable()
 	aggData.Count.Value = ci.Mean
@@ -6,6 +6,7 @@ import (
 	"github.com/dgraph-io/badger/v2"
 	"os"
 	"path"
+	"sort"
 	"summarydb/protos"
 	"summarydb/storage"
 	"summarydb/window"
@@ -14,40 +15,65 @@ import (
 
 type DB struct {
 	dirName         string
+	options         *Options
 	backend         storage.Backend
 	mds             storage.MetadataStore
 	streams         map[int64]*Stream
 	mu              sync.Mutex
 	streamIdCounter int64
+	// Streams by name, and by label for FindStreams.
+	names  map[string]int64
+	labels *labelIndex
+	// Operators and windowing of the streams created by AppendWithLabels,
+	// nil operators when they are not created.
+	autoCreateOperators []string
+	autoCreateSeq       window.LengthsSequence
+	// Retention of the streams which don't set their own, and the job
+	// applying it in the background, nil unless started.
+	defaultRetention int64
+	retention        *retentionJob
+	// Set by OpenReadOnly, the DB only serves queries.
+	readOnly bool
 }
 
 func New(dirName string) (*DB, error) {
+	return NewWithOptions(dirName, DefaultOptions())
+}
+
+func NewWithOptions(dirName string, options *Options) (*DB, error) {
 	err := os.MkdirAll(dirName, 0777)
 	if err != nil {
 		return nil, err
 	}
 	dbPath := path.Join(dirName, "badger")
-	badgerOptions := badger.DefaultOptions(dbPath).WithTruncate(true)
-	badgerDb, err := badger.Open(badgerOptions)
-	badgerBackend := storage.NewBadgerBacked(badgerDb)
+	badgerDb, err := badger.Open(options.Badger(dbPath))
 	if err != nil {
 		return nil, err
 	}
+	return newDB(dirName, options, badgerDb), nil
+}
 
-	db := &DB{
+func newDB(dirName string, options *Options, badgerDb *badger.DB) *DB {
+	return &DB{
 		dirName:         dirName,
-		backend:         badgerBackend,
+		options:         options,
+		backend:         storage.NewBadgerBacked(badgerDb),
 		mds:             storage.NewBadgerMetadataStore(badgerDb),
 		streams:         make(map[int64]*Stream),
 		mu:              sync.Mutex{},
 		streamIdCounter: 0,
+		names:           make(map[string]int64),
+		labels:          newLabelIndex(),
+		readOnly:        false,
 	}
-
-	return db, nil
 }
 
 func Open(path string) (*DB, error) {
-	db, err := New(path)
+	return OpenWithOptions(path, DefaultOptions())
+}
+
+func OpenWithOptions(path string, options *Options) (*DB, error) {
+	db, err := NewWithOptions(path, options)
 	if err != nil {
 		return nil, err
 	}
@@ -59,16 +85,25 @@ func Open(path string) (*DB, error) {
 }
 
 func (db *DB) NewStream(operatorNames []string, seq window.LengthsSequence) (*Stream, error) {
+	return db.NewStreamWithWindowing(operatorNames, window.NewWindowing(seq))
+}
+
+// NewStreamWithWindowing creates a stream with an explicit windowing, e.g.
+// window.TimeWindowing to decay windows by time instead of count.
+func (db *DB) NewStreamWithWindowing(operatorNames []string,
+	windowing window.Windowing) (*Stream, error) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
+	if db.readOnly {
+		return nil, ErrReadOnly
+	}
 	streamId := db.streamIdCounter
 	db.streamIdCounter++
-	windowing := window.NewGenericWindowing(seq)
 	stream, err := NewStreamWithId(db.dirName, streamId, operatorNames, windowing)
 	if err != nil {
 		return nil, err
 	}
-	stream.SetBackend(db.backend, true)
+	db.setUpStream(stream, true)
 	db.streams[streamId] = stream
 
 	err = db.WriteDBAndStream(stream)
@@ -79,6 +114,195 @@ func (db *DB) NewStream(operatorNames []string, seq window.LengthsSequence) (*St
 	return stream, nil
 }
 
+// NewRecordStream creates a stream of records with the given fields, see
+// Stream.AppendRecord.
+func (db *DB) NewRecordStream(fields []Field, seq window.LengthsSequence) (*Stream, error) {
+	if db.readOnly {
+		return nil, ErrReadOnly
+	}
+	err := validateFields(fields)
+	if err != nil {
+		return nil, err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	streamId := db.streamIdCounter
+	db.streamIdCounter++
+	stream, err := NewStreamWithId(db.dirName, streamId, []string{}, window.NewWindowing(seq))
+	if err != nil {
+		return nil, err
+	}
+	stream.setFields(fields)
+	db.setUpStream(stream, true)
+	db.streams[streamId] = stream
+
+	err = db.WriteDBAndStream(stream)
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// NewLabelledStream creates a stream with a unique name and labels, which can
+// be looked up with GetStreamByName and FindStreams. An empty name defaults
+// to the labels, as with AppendWithLabels.
+func (db *DB) NewLabelledStream(name string, labels Labels, operatorNames []string,
+	seq window.LengthsSequence) (*Stream, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.newLabelledStream(name, labels, operatorNames, seq)
+}
+
+func (db *DB) newLabelledStream(name string, labels Labels, operatorNames []string,
+	seq window.LengthsSequence) (*Stream, error) {
+	if db.readOnly {
+		return nil, ErrReadOnly
+	}
+	err := validateLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = labels.String()
+	}
+	if name == "" {
+		return nil, errors.New("no name or labels")
+	}
+	if _, ok := db.names[name]; ok {
+		return nil, errors.New("stream " + name + " already exists")
+	}
+	streamId := db.streamIdCounter
+	db.streamIdCounter++
+	stream, err := NewStreamWithId(db.dirName, streamId, operatorNames, window.NewWindowing(seq))
+	if err != nil {
+		return nil, err
+	}
+	stream.name = name
+	stream.labels = make(Labels, len(labels))
+	for k, v := range labels {
+		stream.labels[k] = v
+	}
+	db.setUpStream(stream, true)
+	db.streams[streamId] = stream
+	db.names[name] = streamId
+	db.labels.add(streamId, stream.labels)
+
+	err = db.WriteDBAndStream(stream)
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// GetStreamByName returns the stream created with the given name.
+func (db *DB) GetStreamByName(name string) (*Stream, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	streamId, ok := db.names[name]
+	if !ok {
+		return nil, errors.New("stream not found")
+	}
+	return db.streams[streamId], nil
+}
+
+// FindStreams returns the streams whose labels match a selector such as
+// "metric=cpu, host=~a|b, env!=test", see ParseSelector. Streams created
+// without labels only match negations.
+func (db *DB) FindStreams(selector string) ([]*Stream, error) {
+	matchers, err := ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	return db.FindStreamsMatching(matchers...), nil
+}
+
+// FindStreamsMatching returns the streams matched by every matcher, ordered
+// by id.
+func (db *DB) FindStreamsMatching(matchers ...*Matcher) []*Stream {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	all := make(map[int64]bool, len(db.streams))
+	for id := range db.streams {
+		all[id] = true
+	}
+	ids := make([]int64, 0)
+	for id := range db.labels.find(matchers, all) {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	streams := make([]*Stream, len(ids))
+	for i, id := range ids {
+		streams[i] = db.streams[id]
+	}
+	return streams
+}
+
+// SetAutoCreate makes AppendWithLabels create the streams it doesn't find,
+// with the given operators and windowing.
+func (db *DB) SetAutoCreate(operatorNames []string, seq window.LengthsSequence) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.autoCreateOperators = operatorNames
+	db.autoCreateSeq = seq
+}
+
+// AppendWithLabels appends to the stream whose labels are exactly labels,
+// whatever its name. It is created, named after the labels, and run on the
+// first append if SetAutoCreate was called.
+func (db *DB) AppendWithLabels(labels Labels, timestamp int64, value float64) error {
+	db.mu.Lock()
+	stream, err := db.streamWithLabels(labels)
+	if err != nil {
+		db.mu.Unlock()
+		return err
+	}
+	if stream == nil {
+		if db.autoCreateOperators == nil {
+			db.mu.Unlock()
+			return errors.New("stream not found")
+		}
+		stream, err = db.newLabelledStream("", labels, db.autoCreateOperators, db.autoCreateSeq)
+		if err == nil {
+			err = stream.Run()
+		}
+		if err != nil {
+			db.mu.Unlock()
+			return err
+		}
+	}
+	db.mu.Unlock()
+	return stream.Append(timestamp, value)
+}
+
+// streamWithLabels returns the stream whose labels are exactly labels, nil
+// when there is none.
+func (db *DB) streamWithLabels(labels Labels) (*Stream, error) {
+	var found *Stream = nil
+	for id := range db.labels.lookup(labels) {
+		stream := db.streams[id]
+		if len(stream.labels) != len(labels) {
+			continue
+		}
+		if found != nil {
+			return nil, errors.New("several streams have labels " + labels.String())
+		}
+		found = stream
+	}
+	return found, nil
+}
+
+// Gives a stream the backend and options of the DB, and the default config
+// when it is new.
+func (db *DB) setUpStream(stream *Stream, isNew bool) {
+	stream.SetBackingStore(NewBackingStoreWithCacheSizes(db.backend,
+		db.options.CacheEnabled, db.options.SummaryCacheSize, db.options.LandmarkCacheSize))
+	stream.SetWALSync(db.options.SyncWAL)
+	if isNew && db.options.StreamConfig != nil {
+		config := *db.options.StreamConfig
+		stream.SetConfig(&config)
+	}
+}
+
 func (db *DB) GetStream(streamId int64) (*Stream, error) {
 	stream, ok := db.streams[streamId]
 	if !ok {
@@ -87,12 +311,137 @@ func (db *DB) GetStream(streamId int64) (*Stream, error) {
 	return stream, nil
 }
 
+// MigrateStream changes the operators or the windowing of a stream which is
+// not running, see Stream.Migrate.
+func (db *DB) MigrateStream(streamId int64, migration *StreamMigration) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.readOnly {
+		return ErrReadOnly
+	}
+	stream, ok := db.streams[streamId]
+	if !ok {
+		return errors.New("stream not found")
+	}
+	return stream.Migrate(migration, db.mds)
+}
+
+// SetStreamConfig sets the buffering of a stream which is not running, and
+// persists it with the stream.
+func (db *DB) SetStreamConfig(streamId int64, config *StoreConfig) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.readOnly {
+		return ErrReadOnly
+	}
+	stream, ok := db.streams[streamId]
+	if !ok {
+		return errors.New("stream not found")
+	}
+	if stream.running {
+		return errors.New("cannot configure a running stream")
+	}
+	stream.SetConfig(config)
+	return db.WriteStream(stream)
+}
+
+// SetLandmarkTriggers sets the rules opening landmarks on a stream, and
+// persists them with the stream.
+func (db *DB) SetLandmarkTriggers(streamId int64, triggers *LandmarkTriggers) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.readOnly {
+		return ErrReadOnly
+	}
+	stream, ok := db.streams[streamId]
+	if !ok {
+		return errors.New("stream not found")
+	}
+	stream.SetLandmarkTriggers(triggers)
+	return db.WriteStream(stream)
+}
+
+// SetLandmarkRetention sets the age after which the landmarks of a stream
+// are folded into its summary windows, and persists it with the stream.
+func (db *DB) SetLandmarkRetention(streamId int64, maxAge int64) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.readOnly {
+		return ErrReadOnly
+	}
+	stream, ok := db.streams[streamId]
+	if !ok {
+		return errors.New("stream not found")
+	}
+	stream.SetLandmarkRetention(maxAge)
+	return db.WriteStream(stream)
+}
+
+// SetOutOfOrderPolicy sets what becomes of the late values appended to a
+// stream, and persists it with the stream.
+func (db *DB) SetOutOfOrderPolicy(streamId int64, policy OutOfOrderPolicy, reorderWindow int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.readOnly {
+		return ErrReadOnly
+	}
+	stream, ok := db.streams[streamId]
+	if !ok {
+		return errors.New("stream not found")
+	}
+	stream.SetOutOfOrderPolicy(policy, reorderWindow)
+	return db.WriteStream(stream)
+}
+
+// DeleteStream closes a stream and drops all of it: its windows and
+// metadata, in a single commit, then its WAL.
+func (db *DB) DeleteStream(streamId int64) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.readOnly {
+		return ErrReadOnly
+	}
+	stream, ok := db.streams[streamId]
+	if !ok {
+		return errors.New("stream not found")
+	}
+	err := stream.Close()
+	if err != nil && err != storage.ErrClosed {
+		return err
+	}
+	delete(db.streams, streamId)
+	if stream.name != "" {
+		delete(db.names, stream.name)
+		db.labels.remove(streamId, stream.labels)
+	}
+
+	dbBuf, err := db.Serialize()
+	if err != nil {
+		return err
+	}
+	err = db.mds.DeleteDBAndStream(dbBuf, streamId)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(walPath(db.dirName, streamId))
+}
+
 func (db *DB) Close() error {
+	db.StopRetention()
 	for _, stream := range db.streams {
 		err := stream.Close()
 		if err != nil {
 			return err
 		}
+		if db.readOnly {
+			continue
+		}
+		// The stream metadata carries the arrival statistics, which change
+		// with every append.
+		err = db.WriteStream(stream)
+		if err != nil {
+			return err
+		}
 	}
 	return db.backend.Close()
 }
@@ -109,6 +458,14 @@ func (db *DB) WriteDBAndStream(stream *Stream) error {
 	return db.mds.PutDBAndStream(dbBuf, stream.streamId, streamBuf)
 }
 
+func (db *DB) WriteStream(stream *Stream) error {
+	streamBuf, err := stream.Serialize()
+	if err != nil {
+		return err
+	}
+	return db.mds.PutStream(stream.streamId, streamBuf)
+}
+
 func (db *DB) ReadDB() error {
 	buf, err := db.mds.GetDB()
 	if err != nil {
@@ -128,24 +485,32 @@ func (db *DB) ReadDB() error {
 		return err
 	}
 
+	db.streamIdCounter = dbProto.NextStreamId()
+	db.defaultRetention = dbProto.DefaultRetention()
 	for i := 0; i < streamIds.Len(); i++ {
 		streamId := streamIds.At(i)
+		if streamId >= db.streamIdCounter {
+			db.streamIdCounter = streamId + 1
+		}
 		streamBuf, err := db.mds.GetStream(streamId)
 		if err != nil {
 			return err
 		}
-		stream, err := DeserializeStream(db.dirName, streamBuf)
+		stream, err := deserializeStream(db.dirName, streamBuf, db.readOnly)
 		if err != nil {
 			return err
 		}
 		db.streams[streamId] = stream
-		stream.SetBackend(db.backend, true)
+		if stream.name != "" {
+			db.names[stream.name] = streamId
+			db.labels.add(streamId, stream.labels)
+		}
+		db.setUpStream(stream, false)
 		err = stream.PrimeUp()
 		if err != nil {
 			return err
 		}
 	}
-	db.streamIdCounter = int64(streamIds.Len())
 	return nil
 }
 
@@ -168,6 +533,8 @@ func (db *DB) Serialize() ([]byte, error) {
 		streamIdsProto.Set(it, id)
 		it += 1
 	}
+	dbProto.SetNextStreamId(db.streamIdCounter)
+	dbProto.SetDefaultRetention(db.defaultRetention)
 
 	buf, err := msg.Marshal()
 	if err != nil {
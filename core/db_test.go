@@ -1,11 +1,21 @@
 package core
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
 	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"math"
 	"os"
+	"path/filepath"
+	"strconv"
 	"summarydb/window"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestBasicDB(t *testing.T) {
@@ -108,9 +118,10 @@ func TestDBWithLambda(t *testing.T) {
 			assert.Equal(t, result.error, 0.0)
 		}
 
+		// The landmark values take up elements in the summary windows too.
 		numSummaryWindows, err := stream.manager.GetSummaryWindowInRange(0, 99)
 		assert.NoError(t, err)
-		assert.Equal(t, len(numSummaryWindows), 10)
+		assert.Equal(t, len(numSummaryWindows), 9)
 	}
 }
 
@@ -197,6 +208,208 @@ func TestDBAppendAfterRead(t *testing.T) {
 	}
 }
 
+func TestDBStatistics(t *testing.T) {
+	dbPath := "testdb_statistics"
+	var streamId int64
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		exp := window.NewExponentialLengthsSequence(2)
+		stream, err := db.NewStream([]string{"count", "sum"}, exp)
+		assert.NoError(t, err)
+		err = stream.Run()
+		assert.NoError(t, err)
+		streamId = stream.streamId
+		for i := 0; i < 100; i++ {
+			err := stream.Append(int64(2*i), float64(i))
+			assert.NoError(t, err)
+		}
+		statistics := stream.Statistics()
+		assert.Equal(t, uint64(100), statistics.NumValues)
+		assert.Equal(t, int64(198), statistics.LastArrivalTimestamp)
+		assert.Equal(t, 2.0, statistics.IntervalStats.GetMean())
+		assert.InEpsilon(t, 49.5, statistics.ValueStats.GetMean(), 1e-4)
+
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.GetStream(streamId)
+		assert.NoError(t, err)
+		statistics := stream.Statistics()
+		assert.Equal(t, uint64(100), statistics.NumValues)
+		assert.True(t, statistics.HasArrivals)
+		assert.Equal(t, int64(0), statistics.FirstArrivalTimestamp)
+		assert.Equal(t, int64(198), statistics.LastArrivalTimestamp)
+		assert.Equal(t, 2.0, statistics.IntervalStats.GetMean())
+		assert.InEpsilon(t, 49.5, statistics.ValueStats.GetMean(), 1e-4)
+
+		err = stream.Run()
+		assert.NoError(t, err)
+		err = stream.Append(200, 100)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(101), stream.Statistics().NumValues)
+		// Logged without being counted, as if the stream crashed before its
+		// statistics were written.
+		for i := 101; i < 105; i++ {
+			err = stream.pipeline.appendWAL(int64(2*i), float64(i), walValue)
+			assert.NoError(t, err)
+		}
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.GetStream(streamId)
+		assert.NoError(t, err)
+		statistics := stream.Statistics()
+		assert.Equal(t, uint64(105), statistics.NumValues)
+		assert.Equal(t, int64(208), statistics.LastArrivalTimestamp)
+		assert.Equal(t, 2.0, statistics.IntervalStats.GetMean())
+		assert.InEpsilon(t, 52.0, statistics.ValueStats.GetMean(), 1e-4)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBPowerWindowing(t *testing.T) {
+	dbPath := "testdb_power_windowing"
+	var streamId int64
+	var count float64
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		power := window.NewPowerLengthsSequence(1, 1, 4, 1)
+		stream, err := db.NewStream([]string{"count"}, power)
+		assert.NoError(t, err)
+		_, ok := stream.pipeline.windowing.(*window.PowerWindowing)
+		assert.True(t, ok)
+
+		err = stream.Run()
+		assert.NoError(t, err)
+		streamId = stream.streamId
+		for i := 0; i < 500; i++ {
+			err := stream.Append(int64(i), float64(i))
+			assert.NoError(t, err)
+		}
+		result, err := stream.Query("count", 0, 499, &QueryParams{
+			ConfidenceLevel: 0.95, SDMultiplier: 1})
+		assert.NoError(t, err)
+		count = result.value.Count.Value
+		assert.Equal(t, 500.0, count)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.GetStream(streamId)
+		assert.NoError(t, err)
+		_, ok := stream.pipeline.windowing.(*window.PowerWindowing)
+		assert.True(t, ok)
+		assert.True(t, stream.pipeline.windowing.GetSeq().Equals(
+			window.NewPowerLengthsSequence(1, 1, 4, 1)))
+
+		result, err := stream.Query("count", 0, 499, &QueryParams{
+			ConfidenceLevel: 0.95, SDMultiplier: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, count, result.value.Count.Value)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBTimeDecay(t *testing.T) {
+	dbPath := "testdb_time_decay"
+	var streamId int64
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		windowing := window.NewTimeWindowing(
+			window.NewWindowing(window.NewExponentialLengthsSequence(2)))
+		stream, err := db.NewStreamWithWindowing([]string{"count"}, windowing)
+		assert.NoError(t, err)
+		err = stream.Run()
+		assert.NoError(t, err)
+		streamId = stream.streamId
+		for _, ts := range getBurstyTimestamps() {
+			err := stream.Append(ts, 1)
+			assert.NoError(t, err)
+		}
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.GetStream(streamId)
+		assert.NoError(t, err)
+		assert.True(t, window.IsTimeDecayed(stream.pipeline.windowing))
+		err = stream.Run()
+		assert.NoError(t, err)
+		for i := int64(0); i < 100; i++ {
+			err := stream.Append(200000+i*10, 1)
+			assert.NoError(t, err)
+		}
+
+		result, err := stream.Query("count", 0, 300000, params)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(len(getBurstyTimestamps())+100), result.value.Count.Value)
+
+		summaryWindows, err := stream.manager.GetSummaryWindowInRange(0, 300000)
+		assert.NoError(t, err)
+		// The sparse stretch before the burst keeps its own windows.
+		assert.GreaterOrEqual(t, countWindowsBefore(summaryWindows, 100000), 2)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBQueryWithAccuracy(t *testing.T) {
+	dbPath := "testdb_accuracy"
+	err := os.RemoveAll(dbPath)
+	assert.NoError(t, err)
+	db, err := New(dbPath)
+	assert.NoError(t, err)
+	exp := window.NewExponentialLengthsSequence(2)
+	stream, err := db.NewStream([]string{"count", "sum"}, exp)
+	assert.NoError(t, err)
+	err = stream.Run()
+	assert.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		err := stream.Append(int64(i), float64(i%7))
+		assert.NoError(t, err)
+	}
+
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	expected := 0.0
+	for i := 100; i <= 900; i++ {
+		expected += float64(i % 7)
+	}
+	result, err := stream.QueryWithAccuracy("sum", 100, 900, 0.001, params)
+	assert.NoError(t, err)
+	assert.True(t, result.TargetMet)
+	assert.True(t, result.FromLandmarks)
+	assert.NotEmpty(t, result.Contributions)
+	assert.Equal(t, expected, result.Result.value.Sum.Value)
+
+	_, err = stream.QueryWithAccuracy("max", 100, 900, 0.001, params)
+	assert.Error(t, err)
+
+	err = db.Close()
+	assert.NoError(t, err)
+}
+
 func testStub(t *testing.T,
 	dbPath string,
 	timesteps int64,
@@ -360,7 +573,7 @@ func testDurableDB(t *testing.T, dbPath string, total, split, windowsPerMerge in
 		assert.NoError(t, err)
 
 		for i := split; i < total; i++ {
-			err = stream.pipeline.appendWAL(i, float64(i))
+			err = stream.pipeline.appendWAL(i, float64(i), walValue)
 			assert.NoError(t, err)
 		}
 		err = stream.pipeline.wal.Sync()
@@ -500,3 +713,1970 @@ func BenchmarkDB_Append_Buffered(b *testing.B) {
 		b.FailNow()
 	}
 }
+
+func TestDBAppendBatch(t *testing.T) {
+	dbPath := "testdb_append_batch"
+	var batchId, singleId int64
+	config := &StoreConfig{
+		EachBufferSize:  32,
+		NumBuffer:       8,
+		WindowsPerMerge: 8,
+	}
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		exp := window.NewExponentialLengthsSequence(2)
+		batch, err := db.NewStream([]string{"count", "sum"}, exp)
+		assert.NoError(t, err)
+		batchId = batch.streamId
+		batch.SetConfig(config)
+		exp = window.NewExponentialLengthsSequence(2)
+		single, err := db.NewStream([]string{"count", "sum"}, exp)
+		assert.NoError(t, err)
+		singleId = single.streamId
+		single.SetConfig(config)
+		assert.NoError(t, batch.Run())
+		assert.NoError(t, single.Run())
+
+		timestamps := make([]int64, 0, 100)
+		values := make([]float64, 0, 100)
+		for i := int64(0); i < 1000; i++ {
+			timestamps = append(timestamps, i)
+			values = append(values, float64(i))
+			err = single.Append(i, float64(i))
+			assert.NoError(t, err)
+			if len(timestamps) == cap(timestamps) {
+				err = batch.AppendBatch(timestamps, values)
+				assert.NoError(t, err)
+				timestamps = timestamps[:0]
+				values = values[:0]
+			}
+		}
+		err = batch.AppendBatch([]int64{1000}, []float64{})
+		assert.Error(t, err)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		batch, err := db.GetStream(batchId)
+		assert.NoError(t, err)
+		single, err := db.GetStream(singleId)
+		assert.NoError(t, err)
+
+		// Same windows either way.
+		batchWindows, err := batch.manager.GetSummaryWindowInRange(0, 999)
+		assert.NoError(t, err)
+		singleWindows, err := single.manager.GetSummaryWindowInRange(0, 999)
+		assert.NoError(t, err)
+		assert.Equal(t, len(singleWindows), len(batchWindows))
+		for i := range singleWindows {
+			assert.Equal(t, singleWindows[i].CountStart, batchWindows[i].CountStart)
+			assert.Equal(t, singleWindows[i].CountEnd, batchWindows[i].CountEnd)
+			assert.Equal(t, singleWindows[i].Data.Sum.Value, batchWindows[i].Data.Sum.Value)
+		}
+
+		// The WAL holds every value.
+		values, err := batch.readRawValues(0, 999)
+		assert.NoError(t, err)
+		assert.Len(t, values, 1000)
+		assert.Equal(t, Landmark{Timestamp: 999, Value: 999}, values[999])
+		assert.Equal(t, uint64(1000), batch.Statistics().NumValues)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func benchmarkStreamAppend(b *testing.B, dbPath string, batchSize int) {
+	err := os.RemoveAll(dbPath)
+	if err != nil {
+		b.FailNow()
+	}
+	db, err := New(dbPath)
+	if err != nil {
+		b.FailNow()
+	}
+	exp := window.NewExponentialLengthsSequence(2)
+	stream, err := db.NewStream([]string{"count", "sum", "max"}, exp)
+	if err != nil {
+		b.FailNow()
+	}
+	stream.SetConfig(&StoreConfig{
+		EachBufferSize:  32,
+		NumBuffer:       8,
+		WindowsPerMerge: 8,
+	})
+	err = stream.Run()
+	if err != nil {
+		b.FailNow()
+	}
+
+	timestamps := make([]int64, 0, batchSize)
+	values := make([]float64, 0, batchSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if batchSize == 1 {
+			err = stream.Append(int64(i), float64(i))
+		} else {
+			timestamps = append(timestamps, int64(i))
+			values = append(values, float64(i))
+			if len(timestamps) == batchSize || i == b.N-1 {
+				err = stream.AppendBatch(timestamps, values)
+				timestamps = timestamps[:0]
+				values = values[:0]
+			}
+		}
+		if err != nil {
+			b.FailNow()
+		}
+	}
+	err = stream.Flush()
+	if err != nil {
+		b.FailNow()
+	}
+	b.StopTimer()
+	err = db.Close()
+	if err != nil {
+		b.FailNow()
+	}
+}
+
+func BenchmarkStream_Append(b *testing.B) {
+	benchmarkStreamAppend(b, "testdb_bm_append", 1)
+}
+
+func BenchmarkStream_AppendBatch100(b *testing.B) {
+	benchmarkStreamAppend(b, "testdb_bm_append_batch100", 100)
+}
+
+func BenchmarkStream_AppendBatch1000(b *testing.B) {
+	benchmarkStreamAppend(b, "testdb_bm_append_batch1000", 1000)
+}
+
+// Run with -race.
+func TestDBConcurrentAppend(t *testing.T) {
+	dbPath := "testdb_concurrent_append"
+	err := os.RemoveAll(dbPath)
+	assert.NoError(t, err)
+	db, err := New(dbPath)
+	assert.NoError(t, err)
+	exp := window.NewExponentialLengthsSequence(2)
+	stream, err := db.NewStream([]string{"count", "sum"}, exp)
+	assert.NoError(t, err)
+	stream.SetConfig(&StoreConfig{
+		EachBufferSize:  16,
+		NumBuffer:       4,
+		WindowsPerMerge: 4,
+	})
+	// Writers taking a timestamp and then getting to the stream after
+	// another one are late. Clamping them could repeat timestamps.
+	stream.SetOutOfOrderPolicy(DropOutOfOrder, 0)
+	err = stream.Run()
+	assert.NoError(t, err)
+
+	numWriters, numValues := 8, 500
+	var next int64 = 0
+	wg := sync.WaitGroup{}
+	for w := 0; w < numWriters; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < numValues; i++ {
+				timestamp := atomic.AddInt64(&next, 1)
+				err := stream.Append(timestamp, 1)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	// Queries flush the pipeline while the writers append.
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	for i := 0; i < 10; i++ {
+		_, err := stream.Query("count", 0, math.MaxInt64, params)
+		assert.NoError(t, err)
+	}
+	wg.Wait()
+
+	statistics := stream.Statistics()
+	assert.Equal(t, uint64(numWriters*numValues), statistics.NumValues+statistics.NumDiscarded)
+	result, err := stream.Query("sum", 0, math.MaxInt64, params)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(statistics.NumValues), result.value.Sum.Value)
+	assert.Equal(t, int64(statistics.NumValues), atomic.LoadInt64(&stream.pipeline.numElements))
+	err = db.Close()
+	assert.NoError(t, err)
+}
+
+func BenchmarkStream_AppendParallel(b *testing.B) {
+	dbPath := "testdb_bm_append_parallel"
+	err := os.RemoveAll(dbPath)
+	if err != nil {
+		b.FailNow()
+	}
+	db, err := New(dbPath)
+	if err != nil {
+		b.FailNow()
+	}
+	exp := window.NewExponentialLengthsSequence(2)
+	stream, err := db.NewStream([]string{"count", "sum", "max"}, exp)
+	if err != nil {
+		b.FailNow()
+	}
+	stream.SetConfig(&StoreConfig{
+		EachBufferSize:  32,
+		NumBuffer:       8,
+		WindowsPerMerge: 8,
+	})
+	err = stream.Run()
+	if err != nil {
+		b.FailNow()
+	}
+
+	var next int64 = 0
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			timestamp := atomic.AddInt64(&next, 1)
+			err := stream.Append(timestamp, float64(timestamp))
+			if err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+	err = stream.Flush()
+	if err != nil {
+		b.FailNow()
+	}
+	b.StopTimer()
+	err = db.Close()
+	if err != nil {
+		b.FailNow()
+	}
+}
+
+func TestDBOutOfOrderPolicies(t *testing.T) {
+	dbPath := "testdb_out_of_order"
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	streamIds := make(map[OutOfOrderPolicy]int64)
+	policies := []OutOfOrderPolicy{
+		ClampOutOfOrder, RejectOutOfOrder, DropOutOfOrder, ReorderOutOfOrder}
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		streams := make(map[OutOfOrderPolicy]*Stream)
+		for _, policy := range policies {
+			exp := window.NewExponentialLengthsSequence(2)
+			stream, err := db.NewStream([]string{"count", "sum"}, exp)
+			assert.NoError(t, err)
+			err = db.SetOutOfOrderPolicy(stream.streamId, policy, 3)
+			assert.NoError(t, err)
+			err = stream.Run()
+			assert.NoError(t, err)
+			streams[policy] = stream
+			streamIds[policy] = stream.streamId
+		}
+
+		// 5 is late by one, 1 by more than the reorder window.
+		timestamps := []int64{0, 2, 3, 4, 6, 7, 5, 8, 9, 10, 1, 11}
+		errs := make(map[OutOfOrderPolicy]int)
+		for _, timestamp := range timestamps {
+			for _, policy := range policies {
+				err := streams[policy].Append(timestamp, float64(timestamp))
+				if err != nil {
+					var outOfOrder *OutOfOrderError
+					assert.True(t, errors.As(err, &outOfOrder))
+					assert.Equal(t, timestamp, outOfOrder.Timestamp)
+					errs[policy]++
+				}
+			}
+		}
+		assert.Equal(t, map[OutOfOrderPolicy]int{RejectOutOfOrder: 2, ReorderOutOfOrder: 1}, errs)
+
+		statistics := streams[ClampOutOfOrder].Statistics()
+		assert.Equal(t, uint64(12), statistics.NumValues)
+		assert.Equal(t, uint64(2), statistics.NumOutOfOrder)
+		assert.Equal(t, uint64(0), statistics.NumDiscarded)
+		statistics = streams[DropOutOfOrder].Statistics()
+		assert.Equal(t, uint64(10), statistics.NumValues)
+		assert.Equal(t, uint64(2), statistics.NumDiscarded)
+		assert.InEpsilon(t, 2.0/12, statistics.OutOfOrderRate(), 1e-9)
+
+		// The last 3 values are still held back.
+		stream := streams[ReorderOutOfOrder]
+		result, err := stream.Query("count", 0, 11, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 8.0, result.value.Count.Value)
+		assert.Equal(t, uint64(2), stream.Statistics().NumOutOfOrder)
+		assert.Equal(t, uint64(1), stream.Statistics().NumDiscarded)
+
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		for _, policy := range policies {
+			stream, err := db.GetStream(streamIds[policy])
+			assert.NoError(t, err)
+			assert.Equal(t, policy, stream.outOfOrderPolicy)
+			assert.Equal(t, 3, stream.reorderWindow)
+		}
+
+		// Every value made it in order, 5 included.
+		stream, err := db.GetStream(streamIds[ReorderOutOfOrder])
+		assert.NoError(t, err)
+		values, err := stream.readRawValues(0, 10)
+		assert.NoError(t, err)
+		for i, value := range values {
+			expected := int64(i)
+			if i > 0 {
+				expected++
+			}
+			assert.Equal(t, expected, value.Timestamp)
+		}
+		result, err := stream.Query("sum", 0, 11, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 65.0, result.value.Sum.Value)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBFoldLateValues(t *testing.T) {
+	dbPath := "testdb_fold_late"
+	var streamId int64
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		exp := window.NewExponentialLengthsSequence(2)
+		stream, err := db.NewStream([]string{"count", "sum"}, exp)
+		assert.NoError(t, err)
+		err = db.SetOutOfOrderPolicy(stream.streamId, FoldOutOfOrder, 0)
+		assert.NoError(t, err)
+		err = stream.Run()
+		assert.NoError(t, err)
+		streamId = stream.streamId
+
+		for i := int64(10); i < 30; i++ {
+			err = stream.Append(i, 1)
+			assert.NoError(t, err)
+		}
+		err = stream.Append(13, 10)
+		assert.NoError(t, err)
+		err = stream.Append(27, 100)
+		assert.NoError(t, err)
+		// Before the first window.
+		err = stream.Append(5, 1000)
+		var outOfOrder *OutOfOrderError
+		assert.True(t, errors.As(err, &outOfOrder))
+
+		err = stream.StartLandmark(30)
+		assert.NoError(t, err)
+		for i := int64(30); i < 36; i += 2 {
+			err = stream.Append(i, 1)
+			assert.NoError(t, err)
+		}
+		err = stream.EndLandmark(35)
+		assert.NoError(t, err)
+		err = stream.Append(36, 1)
+		assert.NoError(t, err)
+		err = stream.Append(31, 5)
+		assert.NoError(t, err)
+
+		result, err := stream.Query("sum", 10, 29, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 130.0, result.value.Sum.Value)
+		statistics := stream.Statistics()
+		// Landmark values count as well.
+		assert.Equal(t, uint64(27), statistics.NumValues)
+		assert.Equal(t, uint64(4), statistics.NumOutOfOrder)
+		assert.Equal(t, uint64(1), statistics.NumDiscarded)
+
+		// Late values take up no elements.
+		summaryWindows, _, err := stream.getWindowsInRange(0, 40)
+		assert.NoError(t, err)
+		for i := 1; i < len(summaryWindows); i++ {
+			assert.Equal(t, summaryWindows[i-1].CountEnd+1, summaryWindows[i].CountStart)
+		}
+		last := summaryWindows[len(summaryWindows)-1]
+		assert.Equal(t, int64(23), last.CountEnd)
+
+		// The window before the gap is stretched to cover the late value.
+		for i := int64(50); i < 60; i++ {
+			err = stream.Append(i, 1)
+			assert.NoError(t, err)
+		}
+		err = stream.Append(45, 7)
+		assert.NoError(t, err)
+		summaryWindows, _, err = stream.getWindowsInRange(45, 45)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(45), summaryWindows[0].TimeEnd)
+		assert.True(t, summaryWindows[0].Folded)
+		result, err = stream.Query("sum", 0, 59, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 156.0, result.value.Sum.Value)
+
+		// The WAL misses the folded values.
+		_, err = stream.PromoteToLandmark(12, 14)
+		assert.Equal(t, errFoldedWindow, err)
+
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.GetStream(streamId)
+		assert.NoError(t, err)
+		result, err := stream.Query("sum", 0, 59, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 156.0, result.value.Sum.Value)
+
+		landmarkWindow, err := stream.manager.GetLandmarkWindow(30)
+		assert.NoError(t, err)
+		timestamps := make([]int64, 0)
+		for _, landmark := range landmarkWindow.Landmarks {
+			timestamps = append(timestamps, landmark.Timestamp)
+		}
+		assert.Equal(t, []int64{30, 31, 32, 34}, timestamps)
+
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBFoldLateValues_TimeDecay(t *testing.T) {
+	dbPath := "testdb_fold_late_time_decay"
+	var streamId int64
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	// With time decay the merger index holds the end timestamps, which
+	// follow the windows stretched by late values.
+	checkMergerIndex := func(stream *Stream) {
+		summaryWindows, _, err := stream.getWindowsInRange(0, 1000)
+		assert.NoError(t, err)
+		index := stream.pipeline.merger.index
+		for _, summaryWindow := range summaryWindows {
+			assert.Equal(t, summaryWindow.TimeEnd, index.GetCEnd(summaryWindow.Id()))
+		}
+	}
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		windowing := window.NewTimeWindowing(
+			window.NewWindowing(window.NewExponentialLengthsSequence(2)))
+		stream, err := db.NewStreamWithWindowing([]string{"count", "sum"}, windowing)
+		assert.NoError(t, err)
+		streamId = stream.streamId
+		err = db.SetOutOfOrderPolicy(streamId, FoldOutOfOrder, 0)
+		assert.NoError(t, err)
+		err = stream.Run()
+		assert.NoError(t, err)
+		for i := int64(0); i < 30; i++ {
+			err = stream.Append(10*i, 1)
+			assert.NoError(t, err)
+		}
+		for i := int64(0); i < 30; i++ {
+			err = stream.Append(10*i+5, 1)
+			assert.NoError(t, err)
+		}
+		err = stream.flush()
+		assert.NoError(t, err)
+		checkMergerIndex(stream)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.GetStream(streamId)
+		assert.NoError(t, err)
+		checkMergerIndex(stream)
+
+		err = stream.Run()
+		assert.NoError(t, err)
+		for i := int64(30); i < 60; i++ {
+			err = stream.Append(10*i, 1)
+			assert.NoError(t, err)
+		}
+		checkMergerIndex(stream)
+		summaryWindows, _, err := stream.getWindowsInRange(0, 1000)
+		assert.NoError(t, err)
+		for i := 1; i < len(summaryWindows); i++ {
+			assert.Less(t, summaryWindows[i-1].TimeEnd, summaryWindows[i].TimeStart)
+		}
+		result, err := stream.Query("count", 0, 1000, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 90.0, result.value.Count.Value)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBRecordStream(t *testing.T) {
+	dbPath := "testdb_records"
+	var streamId int64
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	fields := []Field{
+		{Name: "latency", Operators: []string{"count", "sum", "max"}},
+		{Name: "bytes", Operators: []string{"sum"}},
+	}
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		_, err = db.NewRecordStream([]Field{{Name: "latency", Operators: []string{"p99"}}},
+			window.NewExponentialLengthsSequence(2))
+		assert.Error(t, err)
+
+		exp := window.NewExponentialLengthsSequence(2)
+		stream, err := db.NewRecordStream(fields, exp)
+		assert.NoError(t, err)
+		stream.SetConfig(&StoreConfig{
+			EachBufferSize:  8,
+			NumBuffer:       2,
+			WindowsPerMerge: 2,
+		})
+		err = stream.Run()
+		assert.NoError(t, err)
+		streamId = stream.streamId
+
+		for i := 0; i < 100; i++ {
+			err = stream.AppendRecord(int64(i), map[string]float64{
+				"latency": float64(i),
+				"bytes":   2,
+			})
+			assert.NoError(t, err)
+		}
+		err = stream.AppendRecord(100, map[string]float64{"latency": 1})
+		assert.Error(t, err)
+		err = stream.Append(100, 1)
+		assert.Equal(t, errRecordStream, err)
+
+		result, err := stream.QueryField("latency", "sum", 0, 99, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 99.0*100/2, result.value.Sum.Value)
+		result, err = stream.QueryField("bytes", "sum", 0, 99, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 200.0, result.value.Sum.Value)
+		_, err = stream.QueryField("bytes", "max", 0, 99, params)
+		assert.Error(t, err)
+		// One element, and WAL entry, per record.
+		assert.Equal(t, int64(100), stream.pipeline.numElements)
+
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.GetStream(streamId)
+		assert.NoError(t, err)
+		assert.Equal(t, fields, stream.Fields())
+		err = stream.Run()
+		assert.NoError(t, err)
+		err = stream.AppendRecord(100, map[string]float64{"latency": 1000, "bytes": 2})
+		assert.NoError(t, err)
+
+		result, err := stream.QueryField("latency", "max", 0, 100, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 1000.0, result.value.Max.Value)
+		result, err = stream.QueryField("latency", "count", 0, 100, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 101.0, result.value.Count.Value)
+		result, err = stream.QueryField("bytes", "sum", 0, 100, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 202.0, result.value.Sum.Value)
+
+		// Late records follow the out of order policy, clamped by default.
+		late := map[string]float64{"latency": 1, "bytes": 2}
+		err = stream.AppendRecord(50, late)
+		assert.NoError(t, err)
+		for _, policy := range []OutOfOrderPolicy{RejectOutOfOrder, ReorderOutOfOrder, FoldOutOfOrder} {
+			stream.SetOutOfOrderPolicy(policy, 3)
+			err = stream.AppendRecord(50, late)
+			assert.IsType(t, &OutOfOrderError{}, err)
+		}
+		stream.SetOutOfOrderPolicy(DropOutOfOrder, 0)
+		err = stream.AppendRecord(50, late)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(102), stream.pipeline.numElements)
+		result, err = stream.QueryField("latency", "count", 0, 200, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 102.0, result.value.Count.Value)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBMigrateStream(t *testing.T) {
+	dbPath := "testdb_migrate"
+	var streamId int64
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		exp := window.NewExponentialLengthsSequence(2)
+		stream, err := db.NewStream([]string{"count"}, exp)
+		assert.NoError(t, err)
+		err = stream.Run()
+		assert.NoError(t, err)
+		streamId = stream.streamId
+		for i := int64(0); i < 1000; i++ {
+			err := stream.Append(i, float64(i))
+			assert.NoError(t, err)
+		}
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.GetStream(streamId)
+		assert.NoError(t, err)
+		err = stream.Run()
+		assert.NoError(t, err)
+		// Running streams can't be migrated.
+		err = db.MigrateStream(streamId, NewStreamMigration().AddOperators("sum"))
+		assert.Error(t, err)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		migration := NewStreamMigration().
+			AddOperators("sum", "max").
+			SetTimeDecay(true)
+		err = db.MigrateStream(streamId, migration)
+		assert.NoError(t, err)
+
+		stream, err := db.GetStream(streamId)
+		assert.NoError(t, err)
+		assert.True(t, window.IsTimeDecayed(stream.pipeline.windowing))
+		assert.Empty(t, stream.operatorsSince)
+
+		// The older windows were summarized again from the WAL.
+		result, err := stream.Query("sum", 0, 999, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 499500.0, result.value.Sum.Value)
+		result, err = stream.Query("max", 0, 999, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 999.0, result.value.Max.Value)
+
+		err = stream.Run()
+		assert.NoError(t, err)
+		for i := int64(1000); i < 1100; i++ {
+			err := stream.Append(i, 1)
+			assert.NoError(t, err)
+		}
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.GetStream(streamId)
+		assert.NoError(t, err)
+		assert.True(t, window.IsTimeDecayed(stream.pipeline.windowing))
+		assert.NotNil(t, stream.manager.operators.GetOp("max"))
+
+		result, err := stream.Query("sum", 0, 1099, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 499600.0, result.value.Sum.Value)
+		result, err = stream.Query("count", 0, 1099, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 1100.0, result.value.Count.Value)
+
+		err = db.MigrateStream(streamId, NewStreamMigration().RemoveOperators("max"))
+		assert.NoError(t, err)
+		_, err = stream.Query("max", 0, 1099, params)
+		assert.Error(t, err)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.GetStream(streamId)
+		assert.NoError(t, err)
+		assert.Nil(t, stream.manager.operators.GetOp("max"))
+		assert.NotNil(t, stream.manager.operators.GetOp("sum"))
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBMigrateStream_TruncatedWAL(t *testing.T) {
+	dbPath := "testdb_migrate_truncated"
+	var streamId int64
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		exp := window.NewExponentialLengthsSequence(2)
+		stream, err := db.NewStream([]string{"count"}, exp)
+		assert.NoError(t, err)
+		err = stream.Run()
+		assert.NoError(t, err)
+		streamId = stream.streamId
+		for i := int64(0); i < 1000; i++ {
+			err := stream.Append(i, float64(i))
+			assert.NoError(t, err)
+		}
+		err = stream.TruncateBefore(700)
+		assert.NoError(t, err)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		// The kept windows are all in the WAL.
+		err = db.MigrateStream(streamId, NewStreamMigration().AddOperators("max"))
+		assert.NoError(t, err)
+		stream, err := db.GetStream(streamId)
+		assert.NoError(t, err)
+		result, err := stream.Query("max", 0, 999, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 999.0, result.value.Max.Value)
+
+		// Not once the front of the WAL is cut past the first window.
+		err = stream.pipeline.wal.TruncateFront(990)
+		assert.NoError(t, err)
+		err = db.MigrateStream(streamId, NewStreamMigration().AddOperators("sum"))
+		assert.Equal(t, errTruncatedWAL, err)
+		assert.Nil(t, stream.manager.operators.GetOp("sum"))
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBLandmarkTriggers(t *testing.T) {
+	dbPath := "testdb_landmark_triggers"
+	var streamId int64
+	triggers := NewLandmarkTriggers(5, NewThresholdRule(math.Inf(-1), 100))
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		exp := window.NewExponentialLengthsSequence(2)
+		stream, err := db.NewStream([]string{"count", "sum"}, exp)
+		assert.NoError(t, err)
+		streamId = stream.streamId
+		err = db.SetLandmarkTriggers(streamId, triggers)
+		assert.NoError(t, err)
+		err = stream.Run()
+		assert.NoError(t, err)
+		for i := int64(0); i < 100; i++ {
+			value := 1.0
+			if i >= 50 && i < 53 {
+				value = 1000
+			}
+			err := stream.Append(i, value)
+			assert.NoError(t, err)
+		}
+
+		// Opened by the spike at 50, closed at 57 once 58 was quiet.
+		landmarkWindows, err := stream.manager.GetLandmarkWindowInRange(0, 99)
+		assert.NoError(t, err)
+		assert.Len(t, landmarkWindows, 1)
+		assert.Equal(t, int64(50), landmarkWindows[0].TimeStart)
+		assert.Equal(t, int64(57), landmarkWindows[0].TimeEnd)
+		assert.Len(t, landmarkWindows[0].Landmarks, 8)
+
+		result, err := stream.Query("sum", 0, 99, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 3000.0+97, result.value.Sum.Value)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.GetStream(streamId)
+		assert.NoError(t, err)
+		assert.NotNil(t, stream.landmarkTriggers)
+		assert.True(t, triggers.Equals(stream.landmarkTriggers))
+
+		err = stream.Run()
+		assert.NoError(t, err)
+		for i := int64(100); i < 120; i++ {
+			err := stream.Append(i, float64(i))
+			assert.NoError(t, err)
+		}
+		// Still within the quiet period of the spike at 119.
+		assert.NotNil(t, stream.landmarkWindow)
+		assert.True(t, stream.autoLandmark)
+		assert.Equal(t, int64(101), stream.landmarkWindow.TimeStart)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		// Reopened during the landmark, which still closes on its own.
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.GetStream(streamId)
+		assert.NoError(t, err)
+		assert.NotNil(t, stream.landmarkWindow)
+		assert.True(t, stream.autoLandmark)
+		assert.Equal(t, int64(101), stream.landmarkWindow.TimeStart)
+
+		err = stream.Run()
+		assert.NoError(t, err)
+		for i := int64(120); i < 130; i++ {
+			err := stream.Append(i, 1)
+			assert.NoError(t, err)
+		}
+		// Closed at 124, the quiet period after the spike at 119.
+		assert.Nil(t, stream.landmarkWindow)
+		assert.False(t, stream.autoLandmark)
+		landmarkWindows, err := stream.manager.GetLandmarkWindowInRange(101, 129)
+		assert.NoError(t, err)
+		assert.Len(t, landmarkWindows, 1)
+		assert.Equal(t, int64(124), landmarkWindows[0].TimeEnd)
+		assert.Len(t, landmarkWindows[0].Landmarks, 24)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBLandmarkTriggersBaseline(t *testing.T) {
+	dbPath := "testdb_landmark_triggers_baseline"
+	var streamId int64
+	triggers := NewLandmarkTriggers(5, NewSigmaRule(3, 10))
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		exp := window.NewExponentialLengthsSequence(2)
+		stream, err := db.NewStream([]string{"count", "sum"}, exp)
+		assert.NoError(t, err)
+		streamId = stream.streamId
+		err = db.SetLandmarkTriggers(streamId, triggers)
+		assert.NoError(t, err)
+		err = stream.Run()
+		assert.NoError(t, err)
+		for i := int64(0); i < 100; i++ {
+			err := stream.Append(i, float64(2*(i%2)))
+			assert.NoError(t, err)
+		}
+		// The values kept by the landmark leave the baseline as it was, so
+		// the landmark stays open while the shift lasts.
+		for i := int64(100); i < 300; i++ {
+			err := stream.Append(i, 100)
+			assert.NoError(t, err)
+		}
+		assert.NotNil(t, stream.landmarkWindow)
+		assert.Len(t, stream.landmarkWindow.Landmarks, 200)
+		statistics := stream.pipeline.GetStatistics()
+		assert.Equal(t, uint64(300), statistics.ValueStats.GetCount())
+		assert.Equal(t, uint64(100), statistics.BaselineStats.GetCount())
+		assert.InEpsilon(t, 1.0, statistics.BaselineStats.GetMean(), 1e-4)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.GetStream(streamId)
+		assert.NoError(t, err)
+		statistics := stream.pipeline.GetStatistics()
+		assert.Equal(t, uint64(300), statistics.ValueStats.GetCount())
+		assert.Equal(t, uint64(100), statistics.BaselineStats.GetCount())
+
+		err = stream.Run()
+		assert.NoError(t, err)
+		for i := int64(300); i < 310; i++ {
+			err := stream.Append(i, 100)
+			assert.NoError(t, err)
+		}
+		assert.NotNil(t, stream.landmarkWindow)
+		assert.Len(t, stream.landmarkWindow.Landmarks, 210)
+		for i := int64(310); i < 320; i++ {
+			err := stream.Append(i, 1)
+			assert.NoError(t, err)
+		}
+		assert.Nil(t, stream.landmarkWindow)
+		statistics = stream.pipeline.GetStatistics()
+		assert.Equal(t, uint64(320), statistics.ValueStats.GetCount())
+		assert.Equal(t, uint64(105), statistics.BaselineStats.GetCount())
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBPromoteToLandmark(t *testing.T) {
+	dbPath := "testdb_promote"
+	var streamId int64
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	sum := func(t0, t1 int64) float64 {
+		return float64((t0 + t1) * (t1 - t0 + 1) / 2)
+	}
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		exp := window.NewExponentialLengthsSequence(2)
+		stream, err := db.NewStream([]string{"count", "sum", "max"}, exp)
+		assert.NoError(t, err)
+		err = stream.Run()
+		assert.NoError(t, err)
+		streamId = stream.streamId
+		for i := int64(0); i < 1000; i++ {
+			err := stream.Append(i, float64(i))
+			assert.NoError(t, err)
+		}
+
+		landmarkWindow, err := stream.PromoteToLandmark(500, 520)
+		assert.NoError(t, err)
+		assert.Len(t, landmarkWindow.Landmarks, 21)
+		assert.Equal(t, int64(500), landmarkWindow.Landmarks[0].Timestamp)
+		assert.Equal(t, 520.0, landmarkWindow.Landmarks[20].Value)
+
+		_, err = stream.PromoteToLandmark(510, 530)
+		assert.Error(t, err)
+		_, err = stream.PromoteToLandmark(30, 20)
+		assert.Error(t, err)
+
+		// Nothing is counted twice.
+		result, err := stream.Query("count", 0, 999, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 1000.0, result.value.Count.Value)
+		result, err = stream.Query("sum", 0, 999, params)
+		assert.NoError(t, err)
+		assert.Equal(t, sum(0, 999), result.value.Sum.Value)
+
+		// The promoted range is now answered exactly.
+		result, err = stream.Query("sum", 500, 520, params)
+		assert.NoError(t, err)
+		assert.Equal(t, sum(500, 520), result.value.Sum.Value)
+		assert.Equal(t, 0.0, result.error)
+
+		for i := int64(1000); i < 1100; i++ {
+			err := stream.Append(i, float64(i))
+			assert.NoError(t, err)
+		}
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.GetStream(streamId)
+		assert.NoError(t, err)
+		landmarkWindows, err := stream.manager.GetLandmarkWindowInRange(0, 1099)
+		assert.NoError(t, err)
+		assert.Len(t, landmarkWindows, 1)
+
+		result, err := stream.Query("count", 0, 1099, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 1100.0, result.value.Count.Value)
+		result, err = stream.Query("sum", 0, 1099, params)
+		assert.NoError(t, err)
+		assert.Equal(t, sum(0, 1099), result.value.Sum.Value)
+		result, err = stream.Query("max", 0, 1099, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 1099.0, result.value.Max.Value)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBPromoteToLandmark_SharedWindow(t *testing.T) {
+	dbPath := "testdb_promote_shared"
+	var streamId int64
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		exp := window.NewExponentialLengthsSequence(2)
+		stream, err := db.NewStream([]string{"count", "sum"}, exp)
+		assert.NoError(t, err)
+		err = stream.Run()
+		assert.NoError(t, err)
+		streamId = stream.streamId
+		for i := int64(0); i < 1000; i++ {
+			err := stream.Append(i, 1)
+			assert.NoError(t, err)
+		}
+
+		_, err = stream.PromoteToLandmark(300, 309)
+		assert.NoError(t, err)
+		_, err = stream.PromoteToLandmark(312, 321)
+		assert.NoError(t, err)
+		summaryWindows, _, err := stream.getWindowsInRange(300, 321)
+		assert.NoError(t, err)
+		assert.Len(t, summaryWindows, 1)
+
+		// Neither promotion puts back the values of the other.
+		shared := summaryWindows[0]
+		values, err := stream.readRawValues(shared.CountStart, shared.CountEnd)
+		assert.NoError(t, err)
+		assert.Equal(t, int(shared.Size())-20, len(values))
+		result, err := stream.Query("sum", 0, 999, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 1000.0, result.value.Sum.Value)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		err = db.MigrateStream(streamId, NewStreamMigration().AddOperators("max"))
+		assert.NoError(t, err)
+		stream, err := db.GetStream(streamId)
+		assert.NoError(t, err)
+		result, err := stream.Query("sum", 0, 999, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 1000.0, result.value.Sum.Value)
+		result, err = stream.Query("count", 0, 999, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 1000.0, result.value.Count.Value)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBLandmarkLifecycle(t *testing.T) {
+	dbPath := "testdb_landmark_lifecycle"
+	var streamId int64
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	appendRange := func(stream *Stream, t0, t1 int64) {
+		for i := t0; i < t1; i++ {
+			err := stream.Append(i, 1)
+			assert.NoError(t, err)
+		}
+	}
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		exp := window.NewExponentialLengthsSequence(2)
+		stream, err := db.NewStream([]string{"count", "sum"}, exp)
+		assert.NoError(t, err)
+		streamId = stream.streamId
+		err = stream.Run()
+		assert.NoError(t, err)
+
+		appendRange(stream, 0, 100)
+		for _, start := range []int64{100, 200, 300} {
+			err = stream.StartLandmark(start)
+			assert.NoError(t, err)
+			appendRange(stream, start, start+10)
+			err = stream.EndLandmark(start + 9)
+			assert.NoError(t, err)
+			appendRange(stream, start+10, start+100)
+		}
+
+		landmarks, err := stream.ListLandmarks()
+		assert.NoError(t, err)
+		assert.Len(t, landmarks, 3)
+		assert.Equal(t, LandmarkInfo{TimeStart: 100, TimeEnd: 109, NumValues: 10}, *landmarks[0])
+
+		// Deleting drops the values.
+		err = stream.DeleteLandmark(200)
+		assert.NoError(t, err)
+		assert.Error(t, stream.DeleteLandmark(200))
+		result, err := stream.Query("count", 0, 399, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 390.0, result.value.Count.Value)
+
+		// Folding keeps them in the summary windows.
+		err = stream.FoldLandmark(100)
+		assert.NoError(t, err)
+		landmarks, err = stream.ListLandmarks()
+		assert.NoError(t, err)
+		assert.Len(t, landmarks, 1)
+		result, err = stream.Query("sum", 0, 399, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 390.0, result.value.Sum.Value)
+
+		err = db.SetLandmarkRetention(streamId, 200)
+		assert.NoError(t, err)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.GetStream(streamId)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(200), stream.landmarkRetention)
+		err = stream.Run()
+		assert.NoError(t, err)
+
+		// The landmark ending at 309 expires once 510 comes in.
+		appendRange(stream, 400, 510)
+		landmarks, err := stream.ListLandmarks()
+		assert.NoError(t, err)
+		assert.Len(t, landmarks, 1)
+		appendRange(stream, 510, 520)
+		landmarks, err = stream.ListLandmarks()
+		assert.NoError(t, err)
+		assert.Len(t, landmarks, 0)
+
+		result, err := stream.Query("count", 0, 519, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 510.0, result.value.Count.Value)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBLandmarkFlag(t *testing.T) {
+	dbPath := "testdb_landmark_flag"
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	err := os.RemoveAll(dbPath)
+	assert.NoError(t, err)
+	db, err := New(dbPath)
+	assert.NoError(t, err)
+	exp := window.NewExponentialLengthsSequence(2)
+	stream, err := db.NewStream([]string{"count"}, exp)
+	assert.NoError(t, err)
+	err = stream.Run()
+	assert.NoError(t, err)
+
+	// A value equal to the smallest float is summarized like any other.
+	for i := int64(0); i < 10; i++ {
+		err = stream.Append(i, -math.MaxFloat64)
+		assert.NoError(t, err)
+	}
+	err = stream.StartLandmark(10)
+	assert.NoError(t, err)
+	for i := int64(10); i < 15; i++ {
+		err = stream.Append(i, float64(i))
+		assert.NoError(t, err)
+	}
+	err = stream.EndLandmark(14)
+	assert.NoError(t, err)
+
+	result, err := stream.Query("count", 0, 14, params)
+	assert.NoError(t, err)
+	assert.Equal(t, 15.0, result.value.Count.Value)
+	assert.Equal(t, uint64(15), stream.Statistics().NumValues)
+	err = db.Close()
+	assert.NoError(t, err)
+}
+
+func TestDBLandmarkDurability(t *testing.T) {
+	dbPath := "testdb_landmark_durability"
+	var streamId int64
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	reopen := func() (*DB, *Stream) {
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.GetStream(streamId)
+		assert.NoError(t, err)
+		return db, stream
+	}
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		exp := window.NewExponentialLengthsSequence(2)
+		stream, err := db.NewStream([]string{"count", "sum"}, exp)
+		assert.NoError(t, err)
+		streamId = stream.streamId
+		err = stream.Run()
+		assert.NoError(t, err)
+		for i := int64(0); i < 60; i++ {
+			if i == 50 {
+				err = stream.StartLandmark(i)
+				assert.NoError(t, err)
+			}
+			err = stream.Append(i, float64(i))
+			assert.NoError(t, err)
+		}
+		// Logged, but never handed to the pipeline or the landmark window.
+		for i := int64(60); i < 65; i++ {
+			err = stream.pipeline.appendWAL(i, float64(i), walLandmark)
+			assert.NoError(t, err)
+		}
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, stream := reopen()
+		assert.NotNil(t, stream.landmarkWindow)
+		assert.Equal(t, int64(50), stream.landmarkWindow.TimeStart)
+		assert.Len(t, stream.landmarkWindow.Landmarks, 15)
+		// Opened by StartLandmark, it is left for EndLandmark to close.
+		assert.False(t, stream.autoLandmark)
+
+		err := stream.Run()
+		assert.NoError(t, err)
+		err = stream.EndLandmark(64)
+		assert.NoError(t, err)
+		for i := int64(65); i < 100; i++ {
+			err = stream.Append(i, float64(i))
+			assert.NoError(t, err)
+		}
+
+		// Every value is counted once, and the summary windows count the
+		// landmark values as elements.
+		result, err := stream.Query("count", 0, 99, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 100.0, result.value.Count.Value)
+		result, err = stream.Query("sum", 0, 99, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 99.0*100/2, result.value.Sum.Value)
+		summaryWindows, err := stream.manager.GetSummaryWindowInRange(0, 99)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(99), summaryWindows[len(summaryWindows)-1].CountEnd)
+
+		err = stream.StartLandmark(100)
+		assert.NoError(t, err)
+		err = stream.Append(100, 100)
+		assert.NoError(t, err)
+		err = stream.EndLandmark(100)
+		assert.NoError(t, err)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		// Ended landmarks stay ended.
+		db, stream := reopen()
+		assert.Nil(t, stream.landmarkWindow)
+		landmarks, err := stream.ListLandmarks()
+		assert.NoError(t, err)
+		assert.Len(t, landmarks, 2)
+		result, err := stream.Query("count", 0, 100, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 101.0, result.value.Count.Value)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBLabelledStreams(t *testing.T) {
+	dbPath := "testdb_labels"
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	names := func(streams []*Stream) []string {
+		result := make([]string, 0, len(streams))
+		for _, stream := range streams {
+			result = append(result, stream.Name())
+		}
+		return result
+	}
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		exp := window.NewExponentialLengthsSequence(2)
+		_, err = db.NewLabelledStream("cpu-a", Labels{"host": "a", "metric": "cpu"},
+			[]string{"count"}, exp)
+		assert.NoError(t, err)
+		_, err = db.NewLabelledStream("", Labels{"host": "b", "metric": "cpu", "env": "test"},
+			[]string{"count"}, exp)
+		assert.NoError(t, err)
+		_, err = db.NewLabelledStream("mem-a", Labels{"host": "a", "metric": "mem"},
+			[]string{"count"}, exp)
+		assert.NoError(t, err)
+		_, err = db.NewStream([]string{"count"}, exp)
+		assert.NoError(t, err)
+		_, err = db.NewLabelledStream("cpu-a", Labels{"host": "c"}, []string{"count"}, exp)
+		assert.Error(t, err)
+		for _, stream := range db.streams {
+			err = stream.Run()
+			assert.NoError(t, err)
+			err = stream.Append(0, 1)
+			assert.NoError(t, err)
+		}
+
+		stream, err := db.GetStreamByName("env=test,host=b,metric=cpu")
+		assert.NoError(t, err)
+		assert.Equal(t, Labels{"host": "b", "metric": "cpu", "env": "test"}, stream.Labels())
+
+		streams, err := db.FindStreams("metric=cpu")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"cpu-a", "env=test,host=b,metric=cpu"}, names(streams))
+		streams, err = db.FindStreams("host=a, metric=~c.*|m.*")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"cpu-a", "mem-a"}, names(streams))
+		streams, err = db.FindStreams("metric=cpu, env!=test")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"cpu-a"}, names(streams))
+		// The unlabelled stream has no metric, matched as an empty one.
+		streams, err = db.FindStreams("metric!~cpu|mem")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{""}, names(streams))
+		_, err = db.FindStreams("metric~cpu")
+		assert.Error(t, err)
+
+		// Streams are found by their labels, whatever their name, and only
+		// when they have no other labels.
+		err = db.AppendWithLabels(Labels{"host": "a", "metric": "cpu"}, 1, 1)
+		assert.NoError(t, err)
+		stream, err = db.GetStreamByName("cpu-a")
+		assert.NoError(t, err)
+		result, err := stream.Query("count", 0, 1, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 2.0, result.value.Count.Value)
+		err = db.AppendWithLabels(Labels{"host": "a"}, 2, 1)
+		assert.Error(t, err)
+		err = db.AppendWithLabels(Labels{"host": "c", "metric": "cpu"}, 0, 1)
+		assert.Error(t, err)
+		db.SetAutoCreate([]string{"count"}, exp)
+		for i := 0; i < 10; i++ {
+			err = db.AppendWithLabels(Labels{"host": "c", "metric": "cpu"}, int64(i), 1)
+			assert.NoError(t, err)
+		}
+		stream, err = db.GetStreamByName("host=c,metric=cpu")
+		assert.NoError(t, err)
+		result, err = stream.Query("count", 0, 9, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 10.0, result.value.Count.Value)
+
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		streams, err := db.FindStreams("metric=cpu, host!=b")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"cpu-a", "host=c,metric=cpu"}, names(streams))
+		assert.Equal(t, Labels{"host": "a", "metric": "cpu"}, streams[0].Labels())
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBDeleteStream(t *testing.T) {
+	dbPath := "testdb_delete"
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		exp := window.NewExponentialLengthsSequence(2)
+		for i := 0; i < 3; i++ {
+			stream, err := db.NewLabelledStream("", Labels{"host": strconv.Itoa(i)},
+				[]string{"count"}, exp)
+			assert.NoError(t, err)
+			err = stream.Run()
+			assert.NoError(t, err)
+			for j := 0; j < 100; j++ {
+				err = stream.Append(int64(j), 1)
+				assert.NoError(t, err)
+			}
+		}
+
+		err = db.DeleteStream(1)
+		assert.NoError(t, err)
+		err = db.DeleteStream(1)
+		assert.Error(t, err)
+		_, err = db.GetStream(1)
+		assert.Error(t, err)
+		_, err = db.GetStreamByName("host=1")
+		assert.Error(t, err)
+		streams, err := db.FindStreams("host=~.*")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(streams))
+		_, err = os.Stat(walPath(dbPath, 1))
+		assert.True(t, os.IsNotExist(err))
+		numWindows := 0
+		err = db.backend.IterateIndex(1, func(int64) error {
+			numWindows++
+			return nil
+		}, false)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, numWindows)
+		_, err = db.backend.GetHeap(1)
+		assert.Error(t, err)
+
+		// A closed stream can be deleted too.
+		stream, err := db.GetStream(2)
+		assert.NoError(t, err)
+		err = stream.Close()
+		assert.NoError(t, err)
+		err = db.DeleteStream(2)
+		assert.NoError(t, err)
+
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(db.streams))
+		stream, err := db.GetStream(0)
+		assert.NoError(t, err)
+		err = stream.Run()
+		assert.NoError(t, err)
+		result, err := stream.Query("count", 0, 99, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 100.0, result.value.Count.Value)
+
+		// Ids are not reused.
+		stream, err = db.NewStream([]string{"count"}, window.NewExponentialLengthsSequence(2))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), stream.streamId)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBTruncateBefore(t *testing.T) {
+	dbPath := "testdb_truncate"
+	var streamId int64
+	var keptCount float64
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	// The merges scheduled for each window follow from its span, the first
+	// one starting where the truncated windows ended.
+	checkMerges := func(stream *Stream) {
+		merger := stream.pipeline.merger
+		windows, err := stream.manager.GetSummaryWindowInRange(math.MinInt64, math.MaxInt64)
+		assert.NoError(t, err)
+		for i, summaryWindow := range windows {
+			assert.Equal(t, summaryWindow.CountStart, merger.index.GetCStart(summaryWindow.Id()))
+			if i+1 == len(windows) {
+				continue
+			}
+			item, _ := merger.index.indexMap.Get(summaryWindow.Id())
+			heapItem := item.(*MergerIndexItem).heapItem
+			mergeCount, ok := merger.windowing.GetFirstContainingTime(
+				summaryWindow.CountStart, windows[i+1].CountEnd, merger.numElements)
+			if ok {
+				assert.NotNil(t, heapItem)
+				assert.Equal(t, int(mergeCount), heapItem.Priority)
+			}
+		}
+	}
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.NewStream([]string{"count", "sum"},
+			window.NewExponentialLengthsSequence(2))
+		assert.NoError(t, err)
+		stream.SetConfig(&StoreConfig{
+			EachBufferSize:  8,
+			NumBuffer:       2,
+			WindowsPerMerge: 2,
+		})
+		err = stream.Run()
+		assert.NoError(t, err)
+		streamId = stream.streamId
+
+		for i := 0; i < 1000; i++ {
+			if i == 100 {
+				err = stream.StartLandmark(int64(i))
+				assert.NoError(t, err)
+			}
+			err = stream.Append(int64(i), 1)
+			assert.NoError(t, err)
+			if i == 110 {
+				err = stream.EndLandmark(int64(i))
+				assert.NoError(t, err)
+			}
+		}
+		err = stream.Flush()
+		assert.NoError(t, err)
+		numWindows := stream.manager.NumSummaryWindows()
+
+		err = stream.TruncateBefore(700)
+		assert.NoError(t, err)
+		assert.Less(t, stream.manager.NumSummaryWindows(), numWindows)
+		assert.Equal(t, 0, stream.manager.NumLandmarkWindows())
+		windows, err := stream.manager.GetSummaryWindowInRange(math.MinInt64, math.MaxInt64)
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, windows[0].TimeStart, int64(700))
+		assert.GreaterOrEqual(t, windows[0].TimeEnd, int64(700))
+		keptCount = float64(1000 - windows[0].CountStart)
+		first, err := stream.pipeline.wal.FirstIndex()
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(windows[0].CountStart+1), first)
+
+		result, err := stream.Query("count", 0, 999, params)
+		assert.NoError(t, err)
+		assert.Equal(t, keptCount, result.value.Count.Value)
+		checkMerges(stream)
+
+		// Appends and merges carry on.
+		for i := 1000; i < 2000; i++ {
+			err = stream.Append(int64(i), 1)
+			assert.NoError(t, err)
+		}
+		result, err = stream.Query("count", 0, 1999, params)
+		assert.NoError(t, err)
+		assert.Equal(t, keptCount+1000, result.value.Count.Value)
+		checkMerges(stream)
+
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.GetStream(streamId)
+		assert.NoError(t, err)
+		err = stream.Run()
+		assert.NoError(t, err)
+		result, err := stream.Query("sum", 0, 1999, params)
+		assert.NoError(t, err)
+		assert.Equal(t, keptCount+1000, result.value.Sum.Value)
+		checkMerges(stream)
+
+		err = stream.TruncateBefore(math.MaxInt64)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, stream.manager.NumSummaryWindows())
+		for i := 2000; i < 2100; i++ {
+			err = stream.Append(int64(i), 1)
+			assert.NoError(t, err)
+		}
+		result, err = stream.Query("count", 0, 2099, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 100.0, result.value.Count.Value)
+		checkMerges(stream)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBRetention(t *testing.T) {
+	dbPath := "testdb_retention"
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	oldestWindow := func(stream *Stream) *SummaryWindow {
+		windows, err := stream.manager.GetSummaryWindowInRange(math.MinInt64, math.MaxInt64)
+		assert.NoError(t, err)
+		return windows[0]
+	}
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		exp := window.NewExponentialLengthsSequence(2)
+		streams := make([]*Stream, 4)
+		for i := range streams {
+			streams[i], err = db.NewStream([]string{"count"}, exp)
+			assert.NoError(t, err)
+			err = streams[i].Run()
+			assert.NoError(t, err)
+		}
+		err = db.SetRetention(0, 300)
+		assert.NoError(t, err)
+		err = db.SetRetention(2, -1)
+		assert.NoError(t, err)
+		err = db.SetDefaultRetention(500)
+		assert.NoError(t, err)
+		for i := 0; i < 1000; i++ {
+			for s, stream := range streams {
+				if s == 3 && i == 200 {
+					err = stream.StartLandmark(int64(i))
+					assert.NoError(t, err)
+				}
+				err = stream.Append(int64(i), 1)
+				assert.NoError(t, err)
+			}
+		}
+
+		err = db.ApplyRetention(1000)
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, oldestWindow(streams[0]).TimeEnd, int64(700))
+		assert.Less(t, oldestWindow(streams[0]).TimeStart, int64(700))
+		// The merges carry on from where the deleted windows ended.
+		oldest := oldestWindow(streams[0])
+		assert.Equal(t, oldest.CountStart, streams[0].pipeline.merger.index.GetCStart(oldest.Id()))
+		assert.GreaterOrEqual(t, oldestWindow(streams[1]).TimeEnd, int64(500))
+		assert.Less(t, oldestWindow(streams[1]).TimeStart, int64(500))
+		assert.Equal(t, int64(0), oldestWindow(streams[2]).TimeStart)
+		// Kept from the start of the open landmark.
+		assert.GreaterOrEqual(t, oldestWindow(streams[3]).TimeEnd, int64(200))
+		assert.Less(t, oldestWindow(streams[3]).TimeStart, int64(200))
+		result, err := streams[2].Query("count", 0, 999, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 1000.0, result.value.Count.Value)
+
+		err = db.StartRetention(time.Millisecond, func() int64 { return 1200 })
+		assert.NoError(t, err)
+		err = db.StartRetention(time.Millisecond, func() int64 { return 1200 })
+		assert.Error(t, err)
+		truncated := func() bool {
+			streams[0].appendMutex.Lock()
+			defer streams[0].appendMutex.Unlock()
+			return oldestWindow(streams[0]).TimeEnd >= 900
+		}
+		for deadline := time.Now().Add(5 * time.Second); !truncated() && time.Now().Before(deadline); {
+			time.Sleep(time.Millisecond)
+		}
+		assert.True(t, truncated())
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(500), db.defaultRetention)
+		stream, err := db.GetStream(0)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(300), stream.Retention())
+		err = db.ApplyRetention(1299)
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, oldestWindow(stream).TimeEnd, int64(999))
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBOptions(t *testing.T) {
+	dbPath := "testdb_options"
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	defaultConfig := &StoreConfig{EachBufferSize: 8, NumBuffer: 2, WindowsPerMerge: 2}
+	config := &StoreConfig{EachBufferSize: 16, NumBuffer: 4, WindowsPerMerge: 8}
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		options := DefaultOptions()
+		options.SummaryCacheSize = 1 << 20
+		options.LandmarkCacheSize = 1 << 16
+		options.SyncWAL = true
+		options.StreamConfig = defaultConfig
+		db, err := NewWithOptions(dbPath, options)
+		assert.NoError(t, err)
+		exp := window.NewExponentialLengthsSequence(2)
+		for i := 0; i < 2; i++ {
+			_, err = db.NewStream([]string{"count"}, exp)
+			assert.NoError(t, err)
+		}
+		err = db.SetStreamConfig(1, config)
+		assert.NoError(t, err)
+		err = db.SetStreamConfig(2, config)
+		assert.Error(t, err)
+
+		for id, expected := range []*StoreConfig{defaultConfig, config} {
+			stream, err := db.GetStream(int64(id))
+			assert.NoError(t, err)
+			assert.Equal(t, expected, stream.Config())
+			assert.Equal(t, expected.WindowsPerMerge, stream.pipeline.merger.windowsPerBatch)
+			err = stream.Run()
+			assert.NoError(t, err)
+			for i := 0; i < 100; i++ {
+				err = stream.Append(int64(i), 1)
+				assert.NoError(t, err)
+			}
+		}
+		err = db.SetStreamConfig(0, config)
+		assert.Error(t, err)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	{
+		// Configs are persisted, not taken from the options.
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		for id, expected := range []*StoreConfig{defaultConfig, config} {
+			stream, err := db.GetStream(int64(id))
+			assert.NoError(t, err)
+			assert.Equal(t, expected, stream.Config())
+			assert.Equal(t, expected.WindowsPerMerge, stream.pipeline.merger.windowsPerBatch)
+			err = stream.Run()
+			assert.NoError(t, err)
+			for i := 100; i < 200; i++ {
+				err = stream.Append(int64(i), 1)
+				assert.NoError(t, err)
+			}
+			result, err := stream.Query("count", 0, 199, params)
+			assert.NoError(t, err)
+			assert.Equal(t, 200.0, result.value.Count.Value)
+		}
+		stream, err := db.NewStream([]string{"count"}, window.NewExponentialLengthsSequence(2))
+		assert.NoError(t, err)
+		assert.Nil(t, stream.Config())
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBOpenReadOnly(t *testing.T) {
+	dbPath := "testdb_read_only"
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	exp := window.NewExponentialLengthsSequence(2)
+	var expected float64
+	{
+		err := os.RemoveAll(dbPath)
+		assert.NoError(t, err)
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.NewLabelledStream("web", Labels{"host": "a"}, []string{"count", "sum"}, exp)
+		assert.NoError(t, err)
+		err = stream.Run()
+		assert.NoError(t, err)
+		for i := 0; i < 1000; i++ {
+			err = stream.Append(int64(i), float64(i))
+			assert.NoError(t, err)
+		}
+		result, err := stream.Query("sum", 0, 999, params)
+		assert.NoError(t, err)
+		expected = result.value.Sum.Value
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	walFiles, err := os.ReadDir(walPath(dbPath, 0))
+	assert.NoError(t, err)
+	{
+		db, err := OpenReadOnly(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.GetStreamByName("web")
+		assert.NoError(t, err)
+		result, err := stream.Query("sum", 0, 999, params)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result.value.Sum.Value)
+		result, err = stream.Query("count", 0, 999, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 1000.0, result.value.Count.Value)
+
+		assert.Equal(t, ErrReadOnly, stream.Run())
+		assert.Equal(t, ErrReadOnly, stream.Append(1000, 1))
+		assert.Equal(t, ErrReadOnly, stream.AppendBatch([]int64{1000}, []float64{1}))
+		assert.Equal(t, ErrReadOnly, stream.TruncateBefore(500))
+		_, err = db.NewStream([]string{"count"}, exp)
+		assert.Equal(t, ErrReadOnly, err)
+		assert.Equal(t, ErrReadOnly, db.DeleteStream(0))
+		assert.Equal(t, ErrReadOnly, db.SetRetention(0, 10))
+		assert.Equal(t, ErrReadOnly, db.AppendWithLabels(Labels{"host": "a"}, 1000, 1))
+
+		// Several readers can open the DB at once.
+		other, err := OpenReadOnly(dbPath)
+		assert.NoError(t, err)
+		err = other.Close()
+		assert.NoError(t, err)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+	files, err := os.ReadDir(walPath(dbPath, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, walFiles, files)
+	{
+		db, err := Open(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.GetStream(0)
+		assert.NoError(t, err)
+		err = stream.Run()
+		assert.NoError(t, err)
+		err = stream.Append(1000, 1000)
+		assert.NoError(t, err)
+		result, err := stream.Query("count", 0, 1000, params)
+		assert.NoError(t, err)
+		assert.Equal(t, 1001.0, result.value.Count.Value)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBBackupRestore(t *testing.T) {
+	dbPath := "testdb_backup"
+	restorePath := "testdb_restore"
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	exp := window.NewExponentialLengthsSequence(2)
+	err := os.RemoveAll(dbPath)
+	assert.NoError(t, err)
+	err = os.RemoveAll(restorePath)
+	assert.NoError(t, err)
+
+	var sum, count float64
+	var backup bytes.Buffer
+	{
+		db, err := New(dbPath)
+		assert.NoError(t, err)
+		stream, err := db.NewStream([]string{"count", "sum"}, exp)
+		assert.NoError(t, err)
+		err = stream.Run()
+		assert.NoError(t, err)
+		for i := 0; i < 1000; i++ {
+			err = stream.Append(int64(i), float64(i))
+			assert.NoError(t, err)
+		}
+		// Left open, restored from the WAL.
+		err = stream.StartLandmark(1000)
+		assert.NoError(t, err)
+		for i := 1000; i < 1010; i++ {
+			err = stream.Append(int64(i), float64(i))
+			assert.NoError(t, err)
+		}
+		result, err := stream.Query("sum", 0, 1009, params)
+		assert.NoError(t, err)
+		sum = result.value.Sum.Value
+		result, err = stream.Query("count", 0, 1009, params)
+		assert.NoError(t, err)
+		count = result.value.Count.Value
+
+		other, err := db.NewStream([]string{"count", "sum"}, exp)
+		assert.NoError(t, err)
+		err = other.Run()
+		assert.NoError(t, err)
+		for i := 0; i < 100; i++ {
+			err = other.Append(int64(i), 1)
+			assert.NoError(t, err)
+		}
+		// Ingestion goes on during the backup.
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 100; i < 5000; i++ {
+				err := other.Append(int64(i), 1)
+				assert.NoError(t, err)
+			}
+		}()
+		err = db.Backup(&backup)
+		assert.NoError(t, err)
+		<-done
+
+		links, err := filepath.Glob(filepath.Join(dbPath, "backup-*"))
+		assert.NoError(t, err)
+		assert.Empty(t, links)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+
+	err = Restore(bytes.NewReader(backup.Bytes()), restorePath)
+	assert.NoError(t, err)
+	err = Restore(bytes.NewReader(backup.Bytes()), restorePath)
+	assert.Error(t, err)
+
+	// Failed restores leave nothing behind.
+	badPath := "testdb_restore_bad"
+	err = os.RemoveAll(badPath)
+	assert.NoError(t, err)
+	truncated := backup.Bytes()[:backup.Len()/2]
+	err = Restore(bytes.NewReader(truncated), badPath)
+	assert.Error(t, err)
+	_, err = os.Stat(badPath)
+	assert.True(t, os.IsNotExist(err))
+	err = os.Mkdir(badPath, 0777)
+	assert.NoError(t, err)
+	err = Restore(bytes.NewReader(truncated), badPath)
+	assert.Error(t, err)
+	fis, err := ioutil.ReadDir(badPath)
+	assert.NoError(t, err)
+	assert.Empty(t, fis)
+	// A corrupt size fails on the missing bytes, not by allocating them.
+	var corrupt bytes.Buffer
+	corrupt.WriteString(backupMagic)
+	corrupt.WriteByte(backupKindKeyValue)
+	_ = binary.Write(&corrupt, binary.LittleEndian, uint64(1<<62))
+	corrupt.WriteString("key")
+	err = Restore(bytes.NewReader(corrupt.Bytes()), badPath)
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	// So do WAL files.
+	corruptFile := func(size uint64) []byte {
+		var corrupt bytes.Buffer
+		corrupt.WriteString(backupMagic)
+		corrupt.WriteByte(backupKindFile)
+		_ = binary.Write(&corrupt, binary.LittleEndian, uint64(len("wal-0")))
+		corrupt.WriteString("wal-0")
+		_ = binary.Write(&corrupt, binary.LittleEndian, size)
+		corrupt.WriteString("entries")
+		return corrupt.Bytes()
+	}
+	err = Restore(bytes.NewReader(corruptFile(1<<62)), badPath)
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	err = Restore(bytes.NewReader(corruptFile(math.MaxUint64)), badPath)
+	assert.EqualError(t, err, "corrupt backup")
+	fis, err = ioutil.ReadDir(badPath)
+	assert.NoError(t, err)
+	assert.Empty(t, fis)
+	err = os.RemoveAll(badPath)
+	assert.NoError(t, err)
+	{
+		db, err := Open(restorePath)
+		assert.NoError(t, err)
+		stream, err := db.GetStream(0)
+		assert.NoError(t, err)
+		assert.NotNil(t, stream.landmarkWindow)
+		result, err := stream.Query("sum", 0, 1009, params)
+		assert.NoError(t, err)
+		assert.Equal(t, sum, result.value.Sum.Value)
+		result, err = stream.Query("count", 0, 1009, params)
+		assert.NoError(t, err)
+		assert.Equal(t, count, result.value.Count.Value)
+
+		// The windows and the WAL of the other stream are from the same
+		// point of its ingestion.
+		other, err := db.GetStream(1)
+		assert.NoError(t, err)
+		result, err = other.Query("count", 0, 4999, params)
+		assert.NoError(t, err)
+		numValues := result.value.Count.Value
+		assert.True(t, numValues >= 100 && numValues <= 5000)
+		assert.Equal(t, int64(numValues), other.pipeline.numElements)
+		result, err = other.Query("sum", 0, 4999, params)
+		assert.NoError(t, err)
+		assert.Equal(t, numValues, result.value.Sum.Value)
+
+		err = other.Run()
+		assert.NoError(t, err)
+		err = other.Append(5000, 1)
+		assert.NoError(t, err)
+		result, err = other.Query("count", 0, 5000, params)
+		assert.NoError(t, err)
+		assert.Equal(t, numValues+1, result.value.Count.Value)
+		err = db.Close()
+		assert.NoError(t, err)
+	}
+}
+
+func TestDBFlushPartialBuffer(t *testing.T) {
+	dbPath := "testdb_flush_partial"
+	err := os.RemoveAll(dbPath)
+	assert.NoError(t, err)
+	db, err := New(dbPath)
+	assert.NoError(t, err)
+	stream, err := db.NewStream([]string{"count"}, window.NewExponentialLengthsSequence(2))
+	assert.NoError(t, err)
+	stream.SetConfig(&StoreConfig{
+		EachBufferSize:  8,
+		NumBuffer:       2,
+		WindowsPerMerge: 2,
+	})
+	err = stream.Run()
+	assert.NoError(t, err)
+
+	// Value i is element i. Flushing leaves a partial buffer behind, which
+	// the later windows start after.
+	for i := 0; i < 2000; i++ {
+		err = stream.Append(int64(i), 1)
+		assert.NoError(t, err)
+		if i == 999 || i == 1004 {
+			err = stream.Flush()
+			assert.NoError(t, err)
+		}
+	}
+	err = stream.Flush()
+	assert.NoError(t, err)
+	windows, err := stream.manager.GetSummaryWindowInRange(math.MinInt64, math.MaxInt64)
+	assert.NoError(t, err)
+	for _, summaryWindow := range windows {
+		assert.Equal(t, summaryWindow.TimeStart, summaryWindow.CountStart)
+		assert.Equal(t, summaryWindow.TimeEnd, summaryWindow.CountEnd)
+	}
+	err = db.Close()
+	assert.NoError(t, err)
+}
@@ -23,6 +23,7 @@ func SummaryWindowToBytes(window *SummaryWindow) ([]byte, error) {
 	summaryWindowProto.SetTe(window.TimeEnd)
 	summaryWindowProto.SetCs(window.CountStart)
 	summaryWindowProto.SetCe(window.CountEnd)
+	summaryWindowProto.SetFolded(window.Folded)
 
 	dataTableProto, err := summaryWindowProto.NewOpData()
 	if err != nil {
@@ -33,6 +34,19 @@ func SummaryWindowToBytes(window *SummaryWindow) ([]byte, error) {
 	dataTableProto.SetMax(window.Data.Max.Value)
 	dataTableProto.SetSum(window.Data.Sum.Value)
 
+	if window.Fields != nil {
+		fieldsProto, err := summaryWindowProto.NewFields(int32(len(window.Fields)))
+		if err != nil {
+			return nil, err
+		}
+		for i, data := range window.Fields {
+			fieldProto := fieldsProto.At(i)
+			fieldProto.SetCount(data.Count.Value)
+			fieldProto.SetMax(data.Max.Value)
+			fieldProto.SetSum(data.Sum.Value)
+		}
+	}
+
 	buf, err := msg.Marshal()
 	if err != nil {
 		return nil, err
@@ -57,6 +71,7 @@ func BytesToSummaryWindow(buf []byte) (*SummaryWindow, error) {
 		summaryWindowProto.Te(),
 		summaryWindowProto.Cs(),
 		summaryWindowProto.Ce())
+	summaryWindow.Folded = summaryWindowProto.Folded()
 	dataTableProto, err := summaryWindowProto.OpData()
 	if err != nil {
 		return nil, err
@@ -65,6 +80,22 @@ func BytesToSummaryWindow(buf []byte) (*SummaryWindow, error) {
 	summaryWindow.Data.Sum.Value = dataTableProto.Sum()
 	summaryWindow.Data.Count.Value = dataTableProto.Count()
 	summaryWindow.Data.Max.Value = dataTableProto.Max()
+
+	if summaryWindowProto.HasFields() {
+		fieldsProto, err := summaryWindowProto.Fields()
+		if err != nil {
+			return nil, err
+		}
+		summaryWindow.Fields = make([]*DataTable, fieldsProto.Len())
+		for i := range summaryWindow.Fields {
+			fieldProto := fieldsProto.At(i)
+			data := NewDataTable()
+			data.Count.Value = fieldProto.Count()
+			data.Max.Value = fieldProto.Max()
+			data.Sum.Value = fieldProto.Sum()
+			summaryWindow.Fields[i] = data
+		}
+	}
 	return summaryWindow, nil
 }
 
@@ -81,6 +112,9 @@ func LandmarkWindowToBytes(window *LandmarkWindow) ([]byte, error) {
 
 	landmarkWindowProto.SetTs(window.TimeStart)
 	landmarkWindowProto.SetTe(window.TimeEnd)
+	landmarkWindowProto.SetPromoted(window.Promoted)
+	landmarkWindowProto.SetCs(window.CountStart)
+	landmarkWindowProto.SetCe(window.CountEnd)
 
 	timestampsProto, err :=
 		landmarkWindowProto.NewTimestamps(int32(len(window.Landmarks)))
@@ -131,6 +165,9 @@ func BytesToLandmarkWindow(buf []byte) (*LandmarkWindow, error) {
 	}
 
 	landmarkWindow.Close(landmarkWindowProto.Te())
+	if landmarkWindowProto.Promoted() {
+		landmarkWindow.promote(landmarkWindowProto.Cs(), landmarkWindowProto.Ce())
+	}
 	return landmarkWindow, nil
 }
 
@@ -205,6 +242,7 @@ func MergerIndexToBytes(index *MergerIndex) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	indexProto.SetFirstCStart(index.firstCStart)
 	idx := 0
 	indexItemsProto, err := indexProto.NewItems(int32(index.indexMap.Count()))
 	if err != nil {
@@ -217,7 +255,7 @@ func MergerIndexToBytes(index *MergerIndex) ([]byte, error) {
 			return true
 		}
 		indexItemProto.SetSwid(key)
-		indexItemProto.SetCEnd(val.(*MergerIndexItem).cEnd)
+		indexItemProto.SetCEnd(val.(*MergerIndexItem).end)
 		err = indexItemsProto.Set(idx, indexItemProto)
 		if err != nil {
 			return true
@@ -252,5 +290,6 @@ func BytesToMergerIndex(buf []byte) (*MergerIndex, error) {
 		indexItemProto := indexItemsProto.At(i)
 		mergerIndex.Put(indexItemProto.Swid(), indexItemProto.CEnd())
 	}
+	mergerIndex.SetFirstCStart(indexProto.FirstCStart())
 	return mergerIndex, nil
 }
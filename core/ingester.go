@@ -9,7 +9,12 @@ type IngestBuffer struct {
 	Size       int64
 	timestamps []int64
 	values     []float64
-	allocator  IngestBufferAllocatorIFace
+	// Fields of the records appended, allocated by the first one.
+	records [][]float64
+	// Whether each value is kept by a landmark window, allocated by the
+	// first landmark.
+	landmarks []bool
+	allocator IngestBufferAllocatorIFace
 }
 
 var shutdownIngestBuffer *IngestBuffer = nil
@@ -41,6 +46,8 @@ func NewIngestBuffer(capacity int64, allocator IngestBufferAllocatorIFace) *Inge
 		Size:       0,
 		timestamps: make([]int64, capacity, capacity),
 		values:     make([]float64, capacity, capacity),
+		records:    nil,
+		landmarks:  nil,
 		allocator:  allocator,
 	}
 }
@@ -52,10 +59,64 @@ func (ib *IngestBuffer) Append(timestamp int64, value float64) bool {
 
 	ib.timestamps[ib.Size] = timestamp
 	ib.values[ib.Size] = value
+	if ib.records != nil {
+		ib.records[ib.Size] = nil
+	}
+	if ib.landmarks != nil {
+		ib.landmarks[ib.Size] = false
+	}
+	ib.Size += 1
+	return true
+}
+
+// AppendLandmark appends a value which takes up an element without being
+// summarized.
+func (ib *IngestBuffer) AppendLandmark(timestamp int64, value float64) bool {
+	if !ib.Append(timestamp, value) {
+		return false
+	}
+	if ib.landmarks == nil {
+		ib.landmarks = make([]bool, ib.Capacity)
+	}
+	ib.landmarks[ib.Size-1] = true
+	return true
+}
+
+func (ib *IngestBuffer) AppendRecord(timestamp int64, record []float64) bool {
+	if ib.IsFull() {
+		return false
+	}
+	if ib.records == nil {
+		ib.records = make([][]float64, ib.Capacity)
+	}
+	ib.timestamps[ib.Size] = timestamp
+	ib.values[ib.Size] = 0
+	ib.records[ib.Size] = record
+	if ib.landmarks != nil {
+		ib.landmarks[ib.Size] = false
+	}
 	ib.Size += 1
 	return true
 }
 
+// AppendBatch copies as many values as fit, and returns how many.
+func (ib *IngestBuffer) AppendBatch(timestamps []int64, values []float64) int64 {
+	n := int64(copy(ib.timestamps[ib.Size:], timestamps))
+	copy(ib.values[ib.Size:ib.Size+n], values)
+	if ib.records != nil {
+		for i := ib.Size; i < ib.Size+n; i++ {
+			ib.records[i] = nil
+		}
+	}
+	if ib.landmarks != nil {
+		for i := ib.Size; i < ib.Size+n; i++ {
+			ib.landmarks[i] = false
+		}
+	}
+	ib.Size += n
+	return n
+}
+
 func (ib *IngestBuffer) IsFull() bool {
 	return ib.Size == ib.Capacity
 }
@@ -68,6 +129,12 @@ func (ib *IngestBuffer) TruncateHead(s int64) {
 	// Move elements from position s onwards to the start.
 	copy(ib.timestamps, ib.timestamps[s:])
 	copy(ib.values, ib.values[s:])
+	if ib.records != nil {
+		copy(ib.records, ib.records[s:])
+	}
+	if ib.landmarks != nil {
+		copy(ib.landmarks, ib.landmarks[s:])
+	}
 	ib.Size -= s
 }
 
@@ -77,6 +144,8 @@ func (ib *IngestBuffer) Clear() {
 	ib.Capacity = 0
 	ib.timestamps = nil
 	ib.values = nil
+	ib.records = nil
+	ib.landmarks = nil
 	if ib.allocator != nil {
 		ib.allocator.Deallocate()
 		ib.allocator = nil
@@ -90,6 +159,22 @@ func (ib *IngestBuffer) Get(pos int64) (int64, float64, bool) {
 	return ib.timestamps[pos], ib.values[pos], true
 }
 
+// GetRecord returns the fields of the record at pos, nil for a value.
+func (ib *IngestBuffer) GetRecord(pos int64) []float64 {
+	if ib.records == nil || pos < 0 || pos >= ib.Size {
+		return nil
+	}
+	return ib.records[pos]
+}
+
+// IsLandmark reports whether the value at pos is kept by a landmark window.
+func (ib *IngestBuffer) IsLandmark(pos int64) bool {
+	if ib.landmarks == nil || pos < 0 || pos >= ib.Size {
+		return false
+	}
+	return ib.landmarks[pos]
+}
+
 type Ingester struct {
 	activeBuffer    *IngestBuffer
 	allocator       *IngestBufferAllocator
@@ -128,6 +213,43 @@ func (i *Ingester) Append(timestamp int64, value float64) {
 	i.activeBuffer.Append(timestamp, value)
 }
 
+func (i *Ingester) AppendLandmark(timestamp int64, value float64) {
+	if i.activeBuffer == nil {
+		i.activeBuffer = i.allocator.Allocate(i.bufferCapacity)
+	}
+	if i.activeBuffer.IsFull() {
+		i.pushActiveBufferToQueue()
+		i.activeBuffer = i.allocator.Allocate(i.bufferCapacity)
+	}
+	i.activeBuffer.AppendLandmark(timestamp, value)
+}
+
+func (i *Ingester) AppendRecord(timestamp int64, record []float64) {
+	if i.activeBuffer == nil {
+		i.activeBuffer = i.allocator.Allocate(i.bufferCapacity)
+	}
+	if i.activeBuffer.IsFull() {
+		i.pushActiveBufferToQueue()
+		i.activeBuffer = i.allocator.Allocate(i.bufferCapacity)
+	}
+	i.activeBuffer.AppendRecord(timestamp, record)
+}
+
+func (i *Ingester) AppendBatch(timestamps []int64, values []float64) {
+	for len(timestamps) > 0 {
+		if i.activeBuffer == nil {
+			i.activeBuffer = i.allocator.Allocate(i.bufferCapacity)
+		}
+		if i.activeBuffer.IsFull() {
+			i.pushActiveBufferToQueue()
+			i.activeBuffer = i.allocator.Allocate(i.bufferCapacity)
+		}
+		n := i.activeBuffer.AppendBatch(timestamps, values)
+		timestamps = timestamps[n:]
+		values = values[n:]
+	}
+}
+
 func (i *Ingester) Flush(shutdown bool) {
 	i.pushActiveBufferToQueue()
 	if shutdown {
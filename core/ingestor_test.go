@@ -31,3 +31,70 @@ func TestIngestBuffer_Append(t *testing.T) {
 	assert.Equal(t, buffers[1].timestamps[0], int64(10))
 	assert.Equal(t, buffers[1].timestamps[9], int64(19))
 }
+
+func TestIngestBuffer_AppendBatch(t *testing.T) {
+	outputChannel := make(chan *IngestBuffer, 10)
+	in := NewIngester(outputChannel)
+	in.setBufferCapacity(10)
+	in.allocator.SetMaxBuffers(100)
+
+	timestamps := make([]int64, 0, 25)
+	values := make([]float64, 0, 25)
+	for i := 0; i < 25; i++ {
+		timestamps = append(timestamps, int64(i))
+		values = append(values, float64(i))
+	}
+	in.AppendBatch(timestamps[:3], values[:3])
+	in.AppendBatch(timestamps[3:], values[3:])
+	in.Flush(false)
+	close(outputChannel)
+	buffers := make([]*IngestBuffer, 0)
+	for buffer := range outputChannel {
+		buffers = append(buffers, buffer)
+	}
+	assert.Equal(t, 4, len(buffers))
+	assert.Equal(t, ConstFlushIngestBuffer(), buffers[3])
+
+	for b, size := range []int64{10, 10, 5} {
+		assert.Equal(t, size, buffers[b].Size)
+		for i := int64(0); i < size; i++ {
+			timestamp, value, ok := buffers[b].Get(i)
+			assert.True(t, ok)
+			assert.Equal(t, int64(b)*10+i, timestamp)
+			assert.Equal(t, float64(int64(b)*10+i), value)
+		}
+	}
+}
+
+func TestIngestBuffer_AppendRecord(t *testing.T) {
+	buffer := NewIngestBuffer(4, nil)
+	buffer.Append(0, 1)
+	buffer.AppendRecord(1, []float64{2, 3})
+	buffer.Append(2, 4)
+	assert.Nil(t, buffer.GetRecord(0))
+	assert.Equal(t, []float64{2, 3}, buffer.GetRecord(1))
+	assert.Nil(t, buffer.GetRecord(2))
+
+	buffer.TruncateHead(1)
+	timestamp, _, ok := buffer.Get(0)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), timestamp)
+	assert.Equal(t, []float64{2, 3}, buffer.GetRecord(0))
+	assert.Nil(t, buffer.GetRecord(1))
+}
+
+func TestIngestBuffer_AppendLandmark(t *testing.T) {
+	buffer := NewIngestBuffer(4, nil)
+	buffer.Append(0, 1)
+	buffer.AppendLandmark(1, 2)
+	buffer.Append(2, 3)
+	assert.False(t, buffer.IsLandmark(0))
+	assert.True(t, buffer.IsLandmark(1))
+	assert.False(t, buffer.IsLandmark(2))
+	_, value, _ := buffer.Get(1)
+	assert.Equal(t, 2.0, value)
+
+	buffer.TruncateHead(1)
+	assert.True(t, buffer.IsLandmark(0))
+	assert.False(t, buffer.IsLandmark(1))
+}
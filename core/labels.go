@@ -0,0 +1,289 @@
+package core
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"summarydb/protos"
+)
+
+// Labels identify a stream by key/value pairs, e.g. host=a, metric=cpu.
+type Labels map[string]string
+
+// String returns the labels sorted by name, e.g. "host=a,metric=cpu". It is
+// the name of the streams created by DB.AppendWithLabels.
+func (labels Labels) String() string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=" + labels[name]
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Name returns the unique name of the stream, empty when it has none.
+func (stream *Stream) Name() string {
+	return stream.name
+}
+
+// Labels returns the labels of the stream, nil when it has none.
+func (stream *Stream) Labels() Labels {
+	return stream.labels
+}
+
+// Characters which separate the labels and matchers, left out of their
+// names and values.
+const labelSeparators = ",=!~"
+
+func validateLabels(labels Labels) error {
+	for name, value := range labels {
+		if name == "" {
+			return errors.New("empty label name")
+		}
+		if strings.ContainsAny(name, labelSeparators) {
+			return errors.New("invalid label name " + name)
+		}
+		if strings.ContainsAny(value, labelSeparators) {
+			return errors.New("invalid label value " + value)
+		}
+	}
+	return nil
+}
+
+type MatchType int
+
+const (
+	MatchEqual MatchType = iota
+	MatchNotEqual
+	MatchRegexp
+	MatchNotRegexp
+)
+
+// Matcher selects streams by the value of one of their labels. A stream
+// without the label is matched as if its value was empty.
+type Matcher struct {
+	Type  MatchType
+	Name  string
+	Value string
+	re    *regexp.Regexp
+}
+
+func NewMatcher(matchType MatchType, name string, value string) (*Matcher, error) {
+	m := &Matcher{Type: matchType, Name: name, Value: value}
+	if matchType == MatchRegexp || matchType == MatchNotRegexp {
+		// Anchored, the regexp has to match the whole value.
+		re, err := regexp.Compile("^(?:" + value + ")$")
+		if err != nil {
+			return nil, err
+		}
+		m.re = re
+	}
+	return m, nil
+}
+
+func (m *Matcher) Matches(value string) bool {
+	switch m.Type {
+	case MatchEqual:
+		return value == m.Value
+	case MatchNotEqual:
+		return value != m.Value
+	case MatchRegexp:
+		return m.re.MatchString(value)
+	case MatchNotRegexp:
+		return !m.re.MatchString(value)
+	}
+	panic("unknown match type")
+}
+
+// ParseSelector parses comma separated matchers, e.g.
+// "metric=cpu, host=~a|b, env!=test, dc!~eu-.*".
+func ParseSelector(selector string) ([]*Matcher, error) {
+	matchers := make([]*Matcher, 0)
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		i := strings.IndexAny(term, "=!")
+		if i <= 0 {
+			return nil, errors.New("invalid matcher " + term)
+		}
+		name := strings.TrimSpace(term[:i])
+		rest := term[i:]
+		var matchType MatchType
+		switch {
+		case strings.HasPrefix(rest, "=~"):
+			matchType, rest = MatchRegexp, rest[2:]
+		case strings.HasPrefix(rest, "!~"):
+			matchType, rest = MatchNotRegexp, rest[2:]
+		case strings.HasPrefix(rest, "!="):
+			matchType, rest = MatchNotEqual, rest[2:]
+		case strings.HasPrefix(rest, "="):
+			matchType, rest = MatchEqual, rest[1:]
+		default:
+			return nil, errors.New("invalid matcher " + term)
+		}
+		m, err := NewMatcher(matchType, name, strings.TrimSpace(rest))
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// labelIndex maps every label name and value to the streams carrying it.
+type labelIndex struct {
+	postings map[string]map[string]map[int64]bool
+}
+
+func newLabelIndex() *labelIndex {
+	return &labelIndex{postings: make(map[string]map[string]map[int64]bool)}
+}
+
+func (index *labelIndex) add(streamId int64, labels Labels) {
+	for name, value := range labels {
+		values, ok := index.postings[name]
+		if !ok {
+			values = make(map[string]map[int64]bool)
+			index.postings[name] = values
+		}
+		ids, ok := values[value]
+		if !ok {
+			ids = make(map[int64]bool)
+			values[value] = ids
+		}
+		ids[streamId] = true
+	}
+}
+
+func (index *labelIndex) remove(streamId int64, labels Labels) {
+	for name, value := range labels {
+		values := index.postings[name]
+		delete(values[value], streamId)
+		if len(values[value]) == 0 {
+			delete(values, value)
+		}
+		if len(values) == 0 {
+			delete(index.postings, name)
+		}
+	}
+}
+
+// lookup returns the streams carrying every one of labels, and maybe others.
+func (index *labelIndex) lookup(labels Labels) map[int64]bool {
+	ids := make(map[int64]bool)
+	first := true
+	for name, value := range labels {
+		posting := index.postings[name][value]
+		if first {
+			for id := range posting {
+				ids[id] = true
+			}
+			first = false
+			continue
+		}
+		for id := range ids {
+			if !posting[id] {
+				delete(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// match returns the streams among all matched by m.
+func (index *labelIndex) match(m *Matcher, all map[int64]bool) map[int64]bool {
+	ids := make(map[int64]bool)
+	if m.Matches("") {
+		// Streams without the label match, so start from all of them and
+		// remove those whose value doesn't.
+		for id := range all {
+			ids[id] = true
+		}
+		for value, posting := range index.postings[m.Name] {
+			if !m.Matches(value) {
+				for id := range posting {
+					delete(ids, id)
+				}
+			}
+		}
+		return ids
+	}
+	if m.Type == MatchEqual {
+		for id := range index.postings[m.Name][m.Value] {
+			ids[id] = true
+		}
+		return ids
+	}
+	for value, posting := range index.postings[m.Name] {
+		if m.Matches(value) {
+			for id := range posting {
+				ids[id] = true
+			}
+		}
+	}
+	return ids
+}
+
+// find returns the streams among all matched by every matcher.
+func (index *labelIndex) find(matchers []*Matcher, all map[int64]bool) map[int64]bool {
+	ids := all
+	for _, m := range matchers {
+		matched := index.match(m, ids)
+		for id := range matched {
+			if !ids[id] {
+				delete(matched, id)
+			}
+		}
+		ids = matched
+	}
+	return ids
+}
+
+func serializeLabels(labels Labels, streamProto *protos.Stream) error {
+	labelsProto, err := streamProto.NewLabels(int32(len(labels)))
+	if err != nil {
+		return err
+	}
+	it := 0
+	for name, value := range labels {
+		labelProto := labelsProto.At(it)
+		err = labelProto.SetName(name)
+		if err != nil {
+			return err
+		}
+		err = labelProto.SetValue(value)
+		if err != nil {
+			return err
+		}
+		it += 1
+	}
+	return nil
+}
+
+func deserializeLabels(streamProto *protos.Stream) (Labels, error) {
+	labelsProto, err := streamProto.Labels()
+	if err != nil {
+		return nil, err
+	}
+	labels := make(Labels, labelsProto.Len())
+	for i := 0; i < labelsProto.Len(); i++ {
+		labelProto := labelsProto.At(i)
+		name, err := labelProto.Name()
+		if err != nil {
+			return nil, err
+		}
+		value, err := labelProto.Value()
+		if err != nil {
+			return nil, err
+		}
+		labels[name] = value
+	}
+	return labels, nil
+}
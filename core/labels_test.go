@@ -0,0 +1,47 @@
+package core
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLabels_String(t *testing.T) {
+	assert.Equal(t, "host=a,metric=cpu", Labels{"metric": "cpu", "host": "a"}.String())
+	assert.Equal(t, "", Labels{}.String())
+}
+
+func TestValidateLabels(t *testing.T) {
+	assert.NoError(t, validateLabels(Labels{"metric": "cpu", "host": ""}))
+	assert.Error(t, validateLabels(Labels{"": "cpu"}))
+	for _, invalid := range []string{"a,b", "a=b", "a!b", "a~b"} {
+		assert.Error(t, validateLabels(Labels{invalid: "cpu"}))
+		assert.Error(t, validateLabels(Labels{"metric": invalid}))
+	}
+}
+
+func TestParseSelector(t *testing.T) {
+	matchers, err := ParseSelector("metric=cpu, host=~a|b ,env!=test,dc!~eu-.*")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(matchers))
+	assert.Equal(t, MatchEqual, matchers[0].Type)
+	assert.Equal(t, "metric", matchers[0].Name)
+	assert.Equal(t, "cpu", matchers[0].Value)
+	assert.Equal(t, MatchRegexp, matchers[1].Type)
+	assert.Equal(t, "host", matchers[1].Name)
+	assert.Equal(t, "a|b", matchers[1].Value)
+	assert.Equal(t, MatchNotEqual, matchers[2].Type)
+	assert.Equal(t, MatchNotRegexp, matchers[3].Type)
+
+	assert.True(t, matchers[1].Matches("a"))
+	assert.False(t, matchers[1].Matches("ab"))
+	assert.True(t, matchers[2].Matches(""))
+	assert.False(t, matchers[3].Matches("eu-west"))
+	assert.True(t, matchers[3].Matches("us-east"))
+
+	_, err = ParseSelector("=cpu")
+	assert.Error(t, err)
+	_, err = ParseSelector("metric")
+	assert.Error(t, err)
+	_, err = ParseSelector("host=~(")
+	assert.Error(t, err)
+}
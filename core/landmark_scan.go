@@ -0,0 +1,131 @@
+package core
+
+import (
+	"errors"
+)
+
+// LandmarkScan pages through the raw values kept by the landmark windows of
+// a stream, see Stream.ScanLandmarks.
+type LandmarkScan struct {
+	stream   *Stream
+	t0       int64
+	t1       int64
+	pageSize int
+	// Window being scanned, and the position of the next value in it.
+	window *LandmarkWindow
+	offset int
+	done   bool
+}
+
+// ScanLandmarks iterates over the landmark values with timestamps in
+// [t0, t1], ordered by timestamp, at most pageSize values at a time. The
+// landmark still being appended to is included, up to the values it holds
+// when the scan reaches it.
+func (stream *Stream) ScanLandmarks(t0, t1 int64, pageSize int) (*LandmarkScan, error) {
+	if !stream.backendSet {
+		return nil, errors.New("backend not set")
+	}
+	if t0 > t1 {
+		return nil, errors.New("invalid range")
+	}
+	if pageSize <= 0 {
+		return nil, errors.New("page size must be positive")
+	}
+	return &LandmarkScan{
+		stream:   stream,
+		t0:       t0,
+		t1:       t1,
+		pageSize: pageSize,
+		window:   nil,
+		offset:   0,
+		done:     false,
+	}, nil
+}
+
+// Loads the first window which may hold values in [t0, t1].
+func (scan *LandmarkScan) first() error {
+	landmarkTree := scan.stream.manager.landmarkIndex.GetTree()
+	start, id := landmarkTree.Floor(scan.t0)
+	if id == nil {
+		start, id = landmarkTree.Ceiling(scan.t0)
+	}
+	if id != nil && start <= scan.t1 {
+		return scan.load(start)
+	}
+	return scan.loadRunning()
+}
+
+// Loads the window after the current one.
+func (scan *LandmarkScan) next() error {
+	if scan.window == scan.stream.landmarkWindow {
+		scan.done = true
+		return nil
+	}
+	landmarkTree := scan.stream.manager.landmarkIndex.GetTree()
+	start, id := landmarkTree.Higher(scan.window.TimeStart)
+	if id != nil && start <= scan.t1 {
+		return scan.load(start)
+	}
+	return scan.loadRunning()
+}
+
+func (scan *LandmarkScan) load(start int64) error {
+	landmarkWindow, err := scan.stream.manager.GetLandmarkWindow(start)
+	if err != nil {
+		return err
+	}
+	scan.window = landmarkWindow
+	scan.offset = 0
+	return nil
+}
+
+// The running landmark starts after every stored one.
+func (scan *LandmarkScan) loadRunning() error {
+	running := scan.stream.landmarkWindow
+	if running == nil || running.TimeStart > scan.t1 {
+		scan.done = true
+		return nil
+	}
+	scan.window = running
+	scan.offset = 0
+	return nil
+}
+
+// Next returns the next page of values, empty once the scan is done.
+func (scan *LandmarkScan) Next() ([]Landmark, error) {
+	// The running landmark may be appended to meanwhile.
+	scan.stream.appendMutex.Lock()
+	defer scan.stream.appendMutex.Unlock()
+	page := make([]Landmark, 0, scan.pageSize)
+	if scan.window == nil && !scan.done {
+		err := scan.first()
+		if err != nil {
+			return nil, err
+		}
+	}
+	for !scan.done && len(page) < scan.pageSize {
+		landmarks := scan.window.Landmarks
+		if scan.offset >= len(landmarks) {
+			err := scan.next()
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		landmark := landmarks[scan.offset]
+		if landmark.Timestamp > scan.t1 {
+			scan.done = true
+			break
+		}
+		scan.offset++
+		if landmark.Timestamp >= scan.t0 {
+			page = append(page, landmark)
+		}
+	}
+	return page, nil
+}
+
+// Done checks if every value in the range has been returned.
+func (scan *LandmarkScan) Done() bool {
+	return scan.done
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"summarydb/window"
+	"testing"
+)
+
+func scanAll(t *testing.T, scan *LandmarkScan) ([]Landmark, int) {
+	values := make([]Landmark, 0)
+	pages := 0
+	for !scan.Done() {
+		page, err := scan.Next()
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, len(page), scan.pageSize)
+		if len(page) > 0 {
+			pages++
+		}
+		values = append(values, page...)
+	}
+	return values, pages
+}
+
+func TestStream_ScanLandmarks(t *testing.T) {
+	dbPath := "testdb_landmark_scan"
+	err := os.RemoveAll(dbPath)
+	assert.NoError(t, err)
+	db, err := New(dbPath)
+	assert.NoError(t, err)
+	exp := window.NewExponentialLengthsSequence(2)
+	stream, err := db.NewStream([]string{"count"}, exp)
+	assert.NoError(t, err)
+	err = stream.Run()
+	assert.NoError(t, err)
+
+	// Landmarks over [10, 19], [30, 39] and [50, ...).
+	for i := int64(0); i < 55; i++ {
+		if i == 10 || i == 30 || i == 50 {
+			err = stream.StartLandmark(i)
+			assert.NoError(t, err)
+		}
+		err = stream.Append(i, float64(i))
+		assert.NoError(t, err)
+		if i == 19 || i == 39 {
+			err = stream.EndLandmark(i)
+			assert.NoError(t, err)
+		}
+	}
+
+	scan, err := stream.ScanLandmarks(15, 52, 4)
+	assert.NoError(t, err)
+	values, pages := scanAll(t, scan)
+	assert.Len(t, values, 5+10+3)
+	assert.Equal(t, 5, pages)
+	for i := 1; i < len(values); i++ {
+		assert.Less(t, values[i-1].Timestamp, values[i].Timestamp)
+	}
+	assert.Equal(t, Landmark{Timestamp: 15, Value: 15}, values[0])
+	assert.Equal(t, Landmark{Timestamp: 52, Value: 52}, values[len(values)-1])
+
+	scan, err = stream.ScanLandmarks(0, 100, 100)
+	assert.NoError(t, err)
+	values, _ = scanAll(t, scan)
+	assert.Len(t, values, 25)
+
+	scan, err = stream.ScanLandmarks(20, 29, 10)
+	assert.NoError(t, err)
+	values, _ = scanAll(t, scan)
+	assert.Len(t, values, 0)
+
+	_, err = stream.ScanLandmarks(10, 0, 10)
+	assert.Error(t, err)
+	_, err = stream.ScanLandmarks(0, 10, 0)
+	assert.Error(t, err)
+
+	err = db.Close()
+	assert.NoError(t, err)
+}
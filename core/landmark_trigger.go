@@ -0,0 +1,282 @@
+package core
+
+import (
+	"errors"
+	"math"
+	"summarydb/protos"
+	"summarydb/stats"
+)
+
+// LandmarkRule decides whether a value is interesting enough to be kept at
+// full resolution.
+type LandmarkRule interface {
+	// last is the value appended before, nil for the first value.
+	// valueStats are the running statistics of the values appended outside
+	// landmarks.
+	Fires(timestamp int64, value float64, last *Landmark, valueStats *stats.Welford) bool
+	Serialize(rule *protos.LandmarkRule) error
+	Deserialize(rule *protos.LandmarkRule) error
+	Equals(other LandmarkRule) bool
+}
+
+func DeserializeLandmarkRule(ruleProto *protos.LandmarkRule) (LandmarkRule, error) {
+	var rule LandmarkRule
+	if ruleProto.Which() == protos.LandmarkRule_Which_threshold {
+		rule = NewThresholdRule(0, 0)
+	} else if ruleProto.Which() == protos.LandmarkRule_Which_sigma {
+		rule = NewSigmaRule(0, 0)
+	} else if ruleProto.Which() == protos.LandmarkRule_Which_rateOfChange {
+		rule = NewRateOfChangeRule(0)
+	} else {
+		return nil, errors.New("unknown landmark rule")
+	}
+	err := rule.Deserialize(ruleProto)
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// Fires for values outside [lower, upper]. Use math.Inf for a one-sided
+// threshold.
+type ThresholdRule struct {
+	lower float64
+	upper float64
+}
+
+func NewThresholdRule(lower, upper float64) *ThresholdRule {
+	return &ThresholdRule{
+		lower: lower,
+		upper: upper,
+	}
+}
+
+func (rule *ThresholdRule) Fires(_ int64, value float64, _ *Landmark, _ *stats.Welford) bool {
+	return value < rule.lower || value > rule.upper
+}
+
+func (rule *ThresholdRule) Serialize(ruleProto *protos.LandmarkRule) error {
+	proto, err := ruleProto.NewThreshold()
+	if err != nil {
+		return err
+	}
+	proto.SetLower(rule.lower)
+	proto.SetUpper(rule.upper)
+	return nil
+}
+
+func (rule *ThresholdRule) Deserialize(ruleProto *protos.LandmarkRule) error {
+	proto, err := ruleProto.Threshold()
+	if err != nil {
+		return err
+	}
+	rule.lower = proto.Lower()
+	rule.upper = proto.Upper()
+	return nil
+}
+
+func (rule *ThresholdRule) Equals(other LandmarkRule) bool {
+	switch threshold := other.(type) {
+	case *ThresholdRule:
+		return rule.lower == threshold.lower && rule.upper == threshold.upper
+	default:
+		return false
+	}
+}
+
+// Fires for values more than sigmas standard deviations away from the mean
+// of the values so far outside landmarks. Quiet until minValues values have been seen.
+type SigmaRule struct {
+	sigmas    float64
+	minValues uint64
+}
+
+func NewSigmaRule(sigmas float64, minValues uint64) *SigmaRule {
+	return &SigmaRule{
+		sigmas:    sigmas,
+		minValues: minValues,
+	}
+}
+
+func (rule *SigmaRule) Fires(_ int64, value float64, _ *Landmark, valueStats *stats.Welford) bool {
+	if valueStats == nil || valueStats.GetCount() < 2 || valueStats.GetCount() < rule.minValues {
+		return false
+	}
+	deviation := math.Abs(value - valueStats.GetMean())
+	return deviation > rule.sigmas*math.Sqrt(valueStats.GetVariance())
+}
+
+func (rule *SigmaRule) Serialize(ruleProto *protos.LandmarkRule) error {
+	proto, err := ruleProto.NewSigma()
+	if err != nil {
+		return err
+	}
+	proto.SetSigmas(rule.sigmas)
+	proto.SetMinValues(rule.minValues)
+	return nil
+}
+
+func (rule *SigmaRule) Deserialize(ruleProto *protos.LandmarkRule) error {
+	proto, err := ruleProto.Sigma()
+	if err != nil {
+		return err
+	}
+	rule.sigmas = proto.Sigmas()
+	rule.minValues = proto.MinValues()
+	return nil
+}
+
+func (rule *SigmaRule) Equals(other LandmarkRule) bool {
+	switch sigma := other.(type) {
+	case *SigmaRule:
+		return rule.sigmas == sigma.sigmas && rule.minValues == sigma.minValues
+	default:
+		return false
+	}
+}
+
+// Fires when the value changes faster than maxRate per unit of time since
+// the value before it.
+type RateOfChangeRule struct {
+	maxRate float64
+}
+
+func NewRateOfChangeRule(maxRate float64) *RateOfChangeRule {
+	return &RateOfChangeRule{
+		maxRate: maxRate,
+	}
+}
+
+func (rule *RateOfChangeRule) Fires(timestamp int64, value float64, last *Landmark, _ *stats.Welford) bool {
+	if last == nil {
+		return false
+	}
+	change := math.Abs(value - last.Value)
+	interval := timestamp - last.Timestamp
+	if interval <= 0 {
+		return change > 0
+	}
+	return change/float64(interval) > rule.maxRate
+}
+
+func (rule *RateOfChangeRule) Serialize(ruleProto *protos.LandmarkRule) error {
+	proto, err := ruleProto.NewRateOfChange()
+	if err != nil {
+		return err
+	}
+	proto.SetMaxRate(rule.maxRate)
+	return nil
+}
+
+func (rule *RateOfChangeRule) Deserialize(ruleProto *protos.LandmarkRule) error {
+	proto, err := ruleProto.RateOfChange()
+	if err != nil {
+		return err
+	}
+	rule.maxRate = proto.MaxRate()
+	return nil
+}
+
+func (rule *RateOfChangeRule) Equals(other LandmarkRule) bool {
+	switch rateOfChange := other.(type) {
+	case *RateOfChangeRule:
+		return rule.maxRate == rateOfChange.maxRate
+	default:
+		return false
+	}
+}
+
+// LandmarkTriggers open a landmark window when any of the rules fires, and
+// close it once none has fired for longer than the quiet period.
+type LandmarkTriggers struct {
+	rules       []LandmarkRule
+	quietPeriod int64
+	last        *Landmark
+	lastFired   int64
+}
+
+func NewLandmarkTriggers(quietPeriod int64, rules ...LandmarkRule) *LandmarkTriggers {
+	return &LandmarkTriggers{
+		rules:       rules,
+		quietPeriod: quietPeriod,
+		last:        nil,
+		lastFired:   math.MinInt64,
+	}
+}
+
+// Fires checks (timestamp, value) against the rules, and remembers it as
+// the last value.
+func (triggers *LandmarkTriggers) Fires(timestamp int64, value float64, valueStats *stats.Welford) bool {
+	fired := false
+	for _, rule := range triggers.rules {
+		if rule.Fires(timestamp, value, triggers.last, valueStats) {
+			fired = true
+			break
+		}
+	}
+	if fired {
+		triggers.lastFired = timestamp
+	}
+	triggers.last = &Landmark{
+		Timestamp: timestamp,
+		Value:     value,
+	}
+	return fired
+}
+
+// resume carries on after a restart during a landmark they opened, whose
+// latest value is last. The quiet period starts over from it.
+func (triggers *LandmarkTriggers) resume(last Landmark) {
+	triggers.last = &last
+	triggers.lastFired = last.Timestamp
+}
+
+// IsQuiet checks if no rule has fired within the quiet period before timestamp.
+func (triggers *LandmarkTriggers) IsQuiet(timestamp int64) bool {
+	return timestamp-triggers.lastFired > triggers.quietPeriod
+}
+
+func (triggers *LandmarkTriggers) Serialize(proto *protos.LandmarkTriggers) error {
+	proto.SetQuietPeriod(triggers.quietPeriod)
+	rulesProto, err := proto.NewRules(int32(len(triggers.rules)))
+	if err != nil {
+		return err
+	}
+	for i, rule := range triggers.rules {
+		ruleProto := rulesProto.At(i)
+		err = rule.Serialize(&ruleProto)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func DeserializeLandmarkTriggers(proto *protos.LandmarkTriggers) (*LandmarkTriggers, error) {
+	rulesProto, err := proto.Rules()
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]LandmarkRule, 0, rulesProto.Len())
+	for i := 0; i < rulesProto.Len(); i++ {
+		ruleProto := rulesProto.At(i)
+		rule, err := DeserializeLandmarkRule(&ruleProto)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return NewLandmarkTriggers(proto.QuietPeriod(), rules...), nil
+}
+
+func (triggers *LandmarkTriggers) Equals(other *LandmarkTriggers) bool {
+	if triggers.quietPeriod != other.quietPeriod || len(triggers.rules) != len(other.rules) {
+		return false
+	}
+	for i, rule := range triggers.rules {
+		if !rule.Equals(other.rules[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"capnproto.org/go/capnp/v3"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"summarydb/protos"
+	"summarydb/stats"
+	"testing"
+)
+
+func TestThresholdRule_Fires(t *testing.T) {
+	rule := NewThresholdRule(-1, 1)
+	assert.False(t, rule.Fires(0, 0, nil, nil))
+	assert.False(t, rule.Fires(0, 1, nil, nil))
+	assert.True(t, rule.Fires(0, 1.5, nil, nil))
+	assert.True(t, rule.Fires(0, -2, nil, nil))
+
+	upper := NewThresholdRule(math.Inf(-1), 10)
+	assert.False(t, upper.Fires(0, -1e9, nil, nil))
+	assert.True(t, upper.Fires(0, 11, nil, nil))
+}
+
+func TestSigmaRule_Fires(t *testing.T) {
+	valueStats := stats.NewWelford()
+	rule := NewSigmaRule(3, 4)
+	for _, value := range []float64{9, 11, 9} {
+		valueStats.Update(value)
+	}
+	// Not enough values yet.
+	assert.False(t, rule.Fires(0, 100, nil, valueStats))
+
+	valueStats.Update(11)
+	// Mean 10, standard deviation 1.
+	assert.False(t, rule.Fires(0, 12.5, nil, valueStats))
+	assert.False(t, rule.Fires(0, 7.5, nil, valueStats))
+	assert.True(t, rule.Fires(0, 13.5, nil, valueStats))
+	assert.True(t, rule.Fires(0, 6, nil, valueStats))
+}
+
+func TestRateOfChangeRule_Fires(t *testing.T) {
+	rule := NewRateOfChangeRule(2)
+	assert.False(t, rule.Fires(10, 100, nil, nil))
+
+	last := &Landmark{Timestamp: 10, Value: 0}
+	assert.False(t, rule.Fires(15, 10, last, nil))
+	assert.True(t, rule.Fires(15, -11, last, nil))
+	assert.True(t, rule.Fires(10, 1, last, nil))
+}
+
+func TestLandmarkTriggers_Fires(t *testing.T) {
+	triggers := NewLandmarkTriggers(5,
+		NewThresholdRule(math.Inf(-1), 100),
+		NewRateOfChangeRule(10))
+
+	assert.False(t, triggers.Fires(0, 0, nil))
+	assert.False(t, triggers.Fires(1, 5, nil))
+	assert.True(t, triggers.Fires(2, 50, nil))
+	assert.False(t, triggers.IsQuiet(7))
+	assert.True(t, triggers.IsQuiet(8))
+	assert.True(t, triggers.Fires(3, 101, nil))
+	assert.False(t, triggers.IsQuiet(8))
+}
+
+func TestLandmarkTriggers_Serialize(t *testing.T) {
+	triggers := NewLandmarkTriggers(5,
+		NewThresholdRule(math.Inf(-1), 100),
+		NewSigmaRule(3, 30),
+		NewRateOfChangeRule(10))
+
+	_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	assert.NoError(t, err)
+	proto, err := protos.NewRootLandmarkTriggers(seg)
+	assert.NoError(t, err)
+	err = triggers.Serialize(&proto)
+	assert.NoError(t, err)
+
+	newTriggers, err := DeserializeLandmarkTriggers(&proto)
+	assert.NoError(t, err)
+	assert.True(t, triggers.Equals(newTriggers))
+	assert.False(t, triggers.Equals(NewLandmarkTriggers(5)))
+	assert.False(t, triggers.Equals(NewLandmarkTriggers(6,
+		NewThresholdRule(math.Inf(-1), 100),
+		NewSigmaRule(3, 30),
+		NewRateOfChangeRule(10))))
+}
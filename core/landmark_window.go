@@ -1,5 +1,7 @@
 package core
 
+import "sort"
+
 // Window holding exact values
 
 type Landmark struct {
@@ -11,13 +13,21 @@ type LandmarkWindow struct {
 	TimeStart int64
 	TimeEnd   int64
 	Landmarks []Landmark
+	// Set for windows promoted from the summary windows, whose values were
+	// logged to the WAL as summarized ones: elements CountStart to CountEnd.
+	Promoted   bool
+	CountStart int64
+	CountEnd   int64
 }
 
 func NewLandmarkWindow(timeStart int64) *LandmarkWindow {
 	return &LandmarkWindow{
-		TimeStart: timeStart,
-		TimeEnd:   0,
-		Landmarks: make([]Landmark, 0),
+		TimeStart:  timeStart,
+		TimeEnd:    0,
+		Landmarks:  make([]Landmark, 0),
+		Promoted:   false,
+		CountStart: -1,
+		CountEnd:   -1,
 	}
 }
 
@@ -32,6 +42,30 @@ func (window *LandmarkWindow) Insert(timestamp int64, value float64) {
 	})
 }
 
+// insertLate inserts a value which arrived after later ones, keeping the
+// values ordered by timestamp.
+func (window *LandmarkWindow) insertLate(timestamp int64, value float64) {
+	i := sort.Search(len(window.Landmarks), func(i int) bool {
+		return window.Landmarks[i].Timestamp > timestamp
+	})
+	window.Landmarks = append(window.Landmarks, Landmark{})
+	copy(window.Landmarks[i+1:], window.Landmarks[i:])
+	window.Landmarks[i] = Landmark{Timestamp: timestamp, Value: value}
+}
+
+// promote marks the window as holding elements countStart to countEnd of the
+// summary windows.
+func (window *LandmarkWindow) promote(countStart, countEnd int64) {
+	window.Promoted = true
+	window.CountStart = countStart
+	window.CountEnd = countEnd
+}
+
+// holdsElement reports whether the window was promoted with element n.
+func (window *LandmarkWindow) holdsElement(n int64) bool {
+	return window.Promoted && window.CountStart <= n && n <= window.CountEnd
+}
+
 func (window *LandmarkWindow) Close(timestamp int64) {
 	window.TimeEnd = timestamp
 }
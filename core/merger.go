@@ -3,6 +3,7 @@ package core
 import (
 	"container/heap"
 	"context"
+	"errors"
 	"fmt"
 	"summarydb/storage"
 	"summarydb/tree"
@@ -13,8 +14,9 @@ import (
 const bufferSize int = 100
 
 type MergeEvent struct {
-	Id   int64
-	Size int64
+	Id      int64
+	Size    int64
+	TimeEnd int64
 }
 
 var shutdownMergeEvent *MergeEvent = nil
@@ -26,7 +28,7 @@ func ConstShutdownMergeEvent() *MergeEvent {
 	shutdownMergeMutex.Lock()
 	defer shutdownMergeMutex.Unlock()
 	if shutdownMergeEvent == nil {
-		shutdownMergeEvent = &MergeEvent{-1, -1}
+		shutdownMergeEvent = &MergeEvent{-1, -1, -1}
 	}
 	return shutdownMergeEvent
 }
@@ -35,13 +37,17 @@ func ConstFlushMergeEvent() *MergeEvent {
 	flushMergeMutex.Lock()
 	defer flushMergeMutex.Unlock()
 	if flushMergeEvent == nil {
-		flushMergeEvent = &MergeEvent{-1, -1}
+		flushMergeEvent = &MergeEvent{-1, -1, -1}
 	}
 	return flushMergeEvent
 }
 
 // mergeCounts is a priority queue, mapping each summary window w_i to the time
 // at which w_{i+1} will be merged into it.
+//
+// With count decay the windows span element counts, and time is the number
+// of elements. With time decay (window.TimeWindowing) the windows span
+// timestamps, and time is one past the end of the latest window.
 type Merger struct {
 	streamWindowManager *StreamWindowManager
 	windowing           window.Windowing
@@ -54,6 +60,8 @@ type Merger struct {
 	barrier             *Barrier
 	mutex               sync.Mutex
 	latestTimeStart     int64
+	decayByTime         bool
+	latestTimeEnd       int64
 }
 
 func NewMerger(windowing window.Windowing, windowsPerBatch int64, barrier *Barrier) *Merger {
@@ -68,6 +76,8 @@ func NewMerger(windowing window.Windowing, windowsPerBatch int64, barrier *Barri
 		pendingMerges:       make(map[int64][]int64),
 		barrier:             barrier,
 		mutex:               sync.Mutex{},
+		decayByTime:         window.IsTimeDecayed(windowing),
+		latestTimeEnd:       0,
 	}
 }
 
@@ -97,9 +107,62 @@ func (hm *Merger) PrimeUp() error {
 	hm.index = index
 	hm.numElements = numElements
 	hm.latestTimeStart = latestTimeStart
+	if hm.decayByTime && index.GetLastSWID() != InvalidInt64 {
+		// With time decay the index holds the end timestamps.
+		hm.latestTimeEnd = index.GetCEnd(index.GetLastSWID())
+	}
 	return err
 }
 
+// Rebuild replaces the windowing, and recomputes the merge heap and index
+// from the summary windows of the stream, ordered by time. Windows which are
+// due a merge under the new windowing are merged by the next Process.
+func (hm *Merger) Rebuild(windowing window.Windowing, windows []*SummaryWindow) {
+	hm.mutex.Lock()
+	defer hm.mutex.Unlock()
+	hm.windowing = windowing
+	hm.decayByTime = window.IsTimeDecayed(windowing)
+	hm.mergeCounts = tree.NewMinHeap(bufferSize)
+	hm.index = NewMergerIndex()
+	hm.pendingMerges = make(map[int64][]int64)
+	hm.latestTimeEnd = 0
+
+	for _, summaryWindow := range windows {
+		end := summaryWindow.CountEnd
+		if hm.decayByTime {
+			end = summaryWindow.TimeEnd
+			hm.latestTimeEnd = summaryWindow.TimeEnd
+		}
+		hm.index.Put(summaryWindow.Id(), end)
+	}
+	if len(windows) > 0 && !hm.decayByTime {
+		hm.index.SetFirstCStart(windows[0].CountStart)
+	}
+	for i := 0; i+1 < len(windows); i++ {
+		w := windows[i].Id()
+		hm.updateMergeCountFor(w, hm.getStart(w),
+			hm.index.GetCEnd(windows[i+1].Id()), hm.now())
+	}
+}
+
+// The current time against which the merge heap is compared.
+func (hm *Merger) now() int64 {
+	if hm.decayByTime {
+		return hm.latestTimeEnd + 1
+	}
+	return hm.numElements
+}
+
+// Start of the span covered by w, which begins right after its predecessor.
+// With time decay the first window starts at its own timestamp, rather than
+// at 0.
+func (hm *Merger) getStart(w int64) int64 {
+	if hm.decayByTime && hm.index.Contains(w) && hm.index.GetPred(w) == InvalidInt64 {
+		return w
+	}
+	return hm.index.GetCStart(w)
+}
+
 // Given consecutive windows w0, w1 which together span the count [c0, c1],
 // set mergeCounts[w] = first n' >= n such that (w, w_next) will need to
 // merged after n' elements have been inserted.
@@ -208,7 +271,7 @@ func (hm *Merger) issueAllPendingMerges() error {
 
 func (hm *Merger) updatePendingMerges() {
 	for hm.mergeCounts.Len() != 0 &&
-		hm.mergeCounts.Top().(*tree.HeapItem).Priority <= int(hm.numElements) {
+		hm.mergeCounts.Top().(*tree.HeapItem).Priority <= int(hm.now()) {
 
 		minItem := heap.Pop(hm.mergeCounts).(*tree.HeapItem)
 		hm.index.UnsetHeapItem(minItem.Value)
@@ -219,7 +282,7 @@ func (hm *Merger) updatePendingMerges() {
 		w0 := hm.index.GetPred(w1)
 		w3 := hm.index.GetSucc(w2)
 
-		w1NewStart := hm.index.GetCStart(w1)
+		w1NewStart := hm.getStart(w1)
 		w1NewEnd := hm.index.GetCEnd(w2)
 
 		hm.addPendingMerge(w1, w2)
@@ -230,11 +293,11 @@ func (hm *Merger) updatePendingMerges() {
 			heap.Remove(hm.mergeCounts, w2RemovedIndexItem.heapItem.Index)
 		}
 
-		w0Start := hm.index.GetCStart(w0)
+		w0Start := hm.getStart(w0)
 		w3End := hm.index.GetCEnd(w3)
 
-		hm.updateMergeCountFor(w0, w0Start, w1NewEnd, hm.numElements)
-		hm.updateMergeCountFor(w1, w1NewStart, w3End, hm.numElements)
+		hm.updateMergeCountFor(w0, w0Start, w1NewEnd, hm.now())
+		hm.updateMergeCountFor(w1, w1NewStart, w3End, hm.now())
 	}
 }
 
@@ -245,13 +308,19 @@ func (hm *Merger) Process(mergeEvent *MergeEvent) error {
 	hm.numElements += mergeEvent.Size
 	hm.numWindows += 1
 
+	end := hm.numElements - 1
+	if hm.decayByTime {
+		hm.latestTimeEnd = mergeEvent.TimeEnd
+		end = mergeEvent.TimeEnd
+	}
+
 	lastWindowId := hm.index.GetLastSWID()
-	cStart := hm.index.GetCStart(lastWindowId)
+	cStart := hm.getStart(lastWindowId)
 	if lastWindowId != InvalidInt64 {
-		hm.updateMergeCountFor(lastWindowId, cStart, hm.numElements-1, hm.numElements)
+		hm.updateMergeCountFor(lastWindowId, cStart, end, hm.now())
 	}
 
-	hm.index.Put(mergeEvent.Id, hm.numElements-1)
+	hm.index.Put(mergeEvent.Id, end)
 	hm.updatePendingMerges()
 	if hm.numWindows%hm.windowsPerBatch == 0 {
 		return hm.issueAllPendingMerges()
@@ -269,6 +338,81 @@ func (hm *Merger) flush() error {
 	return err
 }
 
+// Truncate forgets the oldest summary windows, which no longer take part in
+// merges, and deletes them along with the landmark windows in landmarkIDs.
+// There can't be any pending merge, i.e. the merger was flushed.
+func (hm *Merger) Truncate(windowIDs []int64, landmarkIDs []int64) error {
+	hm.mutex.Lock()
+	defer hm.mutex.Unlock()
+	if len(hm.pendingMerges) > 0 {
+		return errors.New("cannot truncate with pending merges")
+	}
+	// The first kept window goes on starting right after the removed ones.
+	firstKept := InvalidInt64
+	if len(windowIDs) > 0 {
+		firstKept = hm.index.GetSucc(windowIDs[len(windowIDs)-1])
+	}
+	firstStart := hm.index.GetCStart(firstKept)
+	for _, swid := range windowIDs {
+		item := hm.index.Remove(swid)
+		if item != nil && item.heapItem != nil {
+			heap.Remove(hm.mergeCounts, item.heapItem.Index)
+		}
+	}
+	if firstKept != InvalidInt64 {
+		hm.index.SetFirstCStart(firstStart)
+		hm.updateMergeCountFor(firstKept, hm.getStart(firstKept),
+			hm.index.GetCEnd(hm.index.GetSucc(firstKept)), hm.now())
+	} else if len(windowIDs) > 0 {
+		// The next window written is the first.
+		hm.index.SetFirstCStart(hm.numElements)
+	}
+	return hm.streamWindowManager.TruncateBrew(
+		windowIDs, landmarkIDs, hm.mergeCounts, hm.index)
+}
+
+// Widen catches up with summary windows whose end moved past the one in the
+// index, i.e. which were stretched by folded values. With time decay the
+// index holds the end timestamps, so the merges due around them change.
+// There can't be any pending merge, i.e. the merger was flushed.
+func (hm *Merger) Widen(windows []*SummaryWindow) error {
+	hm.mutex.Lock()
+	defer hm.mutex.Unlock()
+	if !hm.decayByTime {
+		return nil
+	}
+	if len(hm.pendingMerges) > 0 {
+		return errors.New("cannot widen with pending merges")
+	}
+	widened := false
+	for _, summaryWindow := range windows {
+		w := summaryWindow.Id()
+		if !hm.index.Contains(w) || hm.index.GetCEnd(w) >= summaryWindow.TimeEnd {
+			continue
+		}
+		hm.index.SetCEnd(w, summaryWindow.TimeEnd)
+		if hm.latestTimeEnd < summaryWindow.TimeEnd {
+			hm.latestTimeEnd = summaryWindow.TimeEnd
+		}
+		// The predecessor now ends later together with w, and the successor
+		// starts later.
+		w0 := hm.index.GetPred(w)
+		w2 := hm.index.GetSucc(w)
+		hm.updateMergeCountFor(w0, hm.getStart(w0), summaryWindow.TimeEnd, hm.now())
+		hm.updateMergeCountFor(w2, hm.getStart(w2),
+			hm.index.GetCEnd(hm.index.GetSucc(w2)), hm.now())
+		widened = true
+	}
+	if !widened {
+		return nil
+	}
+	err := hm.streamWindowManager.PutHeap(hm.mergeCounts)
+	if err != nil {
+		return err
+	}
+	return hm.streamWindowManager.PutMergerIndex(hm.index)
+}
+
 func (hm *Merger) PrintSummaryWindows() {
 	windows, err := hm.streamWindowManager.GetSummaryWindowInRange(0, hm.numElements+1)
 	if err != nil {
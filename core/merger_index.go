@@ -8,21 +8,25 @@ import (
 const InvalidInt64 int64 = math.MinInt64
 
 type MergerIndexItem struct {
-	cEnd     int64
+	end      int64
 	heapItem *tree.HeapItem
 }
 
-// In-memory index mapping swid -> (cEnd, heapItem)
+// In-memory index mapping swid -> (end, heapItem)
 // Where,
-//		cEnd is the end timestamp
+//		end is the count of the last element, or the end timestamp with time
+//		decay
 // 		heapItem is a pointer to an element in the main merge heap (mergeCounts)
 // To support predecessor/successor lookups, we use a RB tree instead of hashmap
+// The first window starts at firstCStart, 0 unless older windows were
+// truncated.
 type MergerIndex struct {
-	indexMap *tree.RbTree
+	indexMap    *tree.RbTree
+	firstCStart int64
 }
 
 func NewMergerIndex() *MergerIndex {
-	return &MergerIndex{indexMap: tree.NewRbTree()}
+	return &MergerIndex{indexMap: tree.NewRbTree(), firstCStart: 0}
 }
 
 func (index *MergerIndex) PopulateFromHeap(heap *tree.MinHeap) {
@@ -36,12 +40,12 @@ func (index *MergerIndex) PopulateFromHeap(heap *tree.MinHeap) {
 	}
 }
 
-func (index *MergerIndex) Put(swid int64, cEnd int64) {
+func (index *MergerIndex) Put(swid int64, end int64) {
 	if swid == InvalidInt64 {
 		return
 	}
 	item := &MergerIndexItem{
-		cEnd:     cEnd,
+		end:      end,
 		heapItem: nil,
 	}
 	index.indexMap.Insert(swid, item)
@@ -73,12 +77,17 @@ func (index *MergerIndex) GetCStart(swid int64) int64 {
 	}
 	_, prevItem := index.indexMap.Lower(swid)
 	if prevItem == nil {
-		return 0
+		return index.firstCStart
 	}
 	indexItem := prevItem.(*MergerIndexItem)
-	return indexItem.cEnd + 1
+	return indexItem.end + 1
 }
 
+func (index *MergerIndex) SetFirstCStart(cStart int64) {
+	index.firstCStart = cStart
+}
+
+// GetCEnd returns the end of swid, a timestamp with time decay.
 func (index *MergerIndex) GetCEnd(swid int64) int64 {
 	if swid == InvalidInt64 {
 		return InvalidInt64
@@ -88,7 +97,21 @@ func (index *MergerIndex) GetCEnd(swid int64) int64 {
 		return InvalidInt64
 	}
 	indexItem := item.(*MergerIndexItem)
-	return indexItem.cEnd
+	return indexItem.end
+}
+
+// SetCEnd moves the end of swid, which keeps its place in the merge heap.
+func (index *MergerIndex) SetCEnd(swid int64, end int64) bool {
+	if swid == InvalidInt64 {
+		return false
+	}
+	item, ok := index.indexMap.Get(swid)
+	if !ok {
+		return ok
+	}
+	indexItem := item.(*MergerIndexItem)
+	indexItem.end = end
+	return true
 }
 
 func (index *MergerIndex) GetPred(swid int64) int64 {
@@ -0,0 +1,267 @@
+package core
+
+import (
+	"capnproto.org/go/capnp/v3"
+	"errors"
+	"math"
+	"summarydb/protos"
+	"summarydb/storage"
+	"summarydb/window"
+)
+
+// StreamMigration changes the operators or the windowing of an existing
+// stream, see Stream.Migrate.
+type StreamMigration struct {
+	addOperators    []string
+	removeOperators []string
+	windowing       window.Windowing
+	setDecay        bool
+	timeDecayed     bool
+}
+
+func NewStreamMigration() *StreamMigration {
+	return &StreamMigration{
+		addOperators:    make([]string, 0),
+		removeOperators: make([]string, 0),
+		windowing:       nil,
+		setDecay:        false,
+		timeDecayed:     false,
+	}
+}
+
+func (migration *StreamMigration) AddOperators(operatorNames ...string) *StreamMigration {
+	migration.addOperators = append(migration.addOperators, operatorNames...)
+	return migration
+}
+
+func (migration *StreamMigration) RemoveOperators(operatorNames ...string) *StreamMigration {
+	migration.removeOperators = append(migration.removeOperators, operatorNames...)
+	return migration
+}
+
+// SetWindowing replaces the windowing of the stream. windowing must not have
+// been used by another stream.
+func (migration *StreamMigration) SetWindowing(windowing window.Windowing) *StreamMigration {
+	migration.windowing = windowing
+	return migration
+}
+
+// SetTimeDecay keeps the lengths sequence of the stream, and switches it to
+// decay by time or by count.
+func (migration *StreamMigration) SetTimeDecay(timeDecayed bool) *StreamMigration {
+	migration.setDecay = true
+	migration.timeDecayed = timeDecayed
+	return migration
+}
+
+// Lengths sequences are consumed by their windowing, so a new windowing
+// starts from a copy.
+func copyLengthsSequence(seq window.LengthsSequence) (window.LengthsSequence, error) {
+	_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	if err != nil {
+		return nil, err
+	}
+	streamProto, err := protos.NewRootStream(seg)
+	if err != nil {
+		return nil, err
+	}
+	windowProto := streamProto.Window()
+	err = seq.Serialize(&windowProto)
+	if err != nil {
+		return nil, err
+	}
+	return window.DeserializeLengthsSequence(&windowProto)
+}
+
+func (stream *Stream) getMigratedOperators(migration *StreamMigration) ([]string, error) {
+	operators := make(map[string]bool)
+	for _, name := range stream.manager.operators.GetOpNames() {
+		operators[name] = true
+	}
+	for _, name := range migration.removeOperators {
+		if !operators[name] {
+			return nil, errors.New("op not found: " + name)
+		}
+		delete(operators, name)
+	}
+	for _, name := range migration.addOperators {
+		if _, ok := OpNameOpTypeMap[name]; !ok {
+			return nil, errors.New("unknown op: " + name)
+		}
+		if operators[name] {
+			return nil, errors.New("op already exists: " + name)
+		}
+		operators[name] = true
+	}
+	if len(operators) == 0 {
+		return nil, errors.New("stream needs at least one op")
+	}
+	names := make([]string, 0, len(operators))
+	for name := range operators {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (stream *Stream) getMigratedWindowing(migration *StreamMigration) (window.Windowing, error) {
+	if migration.windowing != nil {
+		return migration.windowing, nil
+	}
+	current := stream.pipeline.windowing
+	if !migration.setDecay || migration.timeDecayed == window.IsTimeDecayed(current) {
+		return nil, nil
+	}
+	seq, err := copyLengthsSequence(current.GetSeq())
+	if err != nil {
+		return nil, err
+	}
+	var windowing window.Windowing = window.NewWindowing(seq)
+	if migration.timeDecayed {
+		windowing = window.NewTimeWindowing(windowing)
+	}
+	return windowing, nil
+}
+
+var errTruncatedWAL = errors.New("window starts before the WAL, whose front was truncated")
+
+// Summarizes the raw values of every window again, under opSet.
+func (stream *Stream) resummarize(
+	opSet *OpSet, windows []*SummaryWindow) (map[int64][]byte, error) {
+	firstIndex, err := stream.pipeline.wal.FirstIndex()
+	if err != nil {
+		return nil, err
+	}
+	buffers := make(map[int64][]byte, len(windows))
+	for _, summaryWindow := range windows {
+		if summaryWindow.Folded {
+			return nil, errFoldedWindow
+		}
+		// Summary windows number their elements from 0, the WAL from 1.
+		if uint64(summaryWindow.CountStart+1) < firstIndex {
+			return nil, errTruncatedWAL
+		}
+		landmarks, err := stream.readRawValues(
+			summaryWindow.CountStart, summaryWindow.CountEnd)
+		if err != nil {
+			return nil, err
+		}
+		resummarized := NewSummaryWindow(
+			summaryWindow.TimeStart, summaryWindow.TimeEnd,
+			summaryWindow.CountStart, summaryWindow.CountEnd)
+		for _, landmark := range landmarks {
+			opSet.Insert(resummarized.Data, landmark.Value, landmark.Timestamp)
+		}
+		buf, err := SummaryWindowToBytes(resummarized)
+		if err != nil {
+			return nil, err
+		}
+		buffers[summaryWindow.Id()] = buf
+	}
+	return buffers, nil
+}
+
+// Migrate adds or removes operators, or switches the windowing of a stream
+// which is not running. The stream metadata, the re-summarized windows and
+// the merge state are written to mds in a single commit.
+//
+// Windows written after the migration use the new configuration. Older
+// windows are summarized again from the WAL for any added operator, which
+// fails if values were folded into any of them. Without a WAL they are left
+// as they are, and the operator is tagged with the first timestamp it
+// covers: queries reaching into older windows fail. Under a new windowing,
+// older windows are merged as if the windowing had always been in place,
+// once more values are appended.
+func (stream *Stream) Migrate(migration *StreamMigration, mds storage.MetadataStore) error {
+	if !stream.backendSet {
+		return errors.New("backend not set")
+	}
+	if stream.readOnly {
+		return ErrReadOnly
+	}
+	if stream.running {
+		return errors.New("cannot migrate a running stream")
+	}
+	if stream.fields != nil {
+		return errors.New("cannot migrate a stream of records")
+	}
+
+	operatorNames, err := stream.getMigratedOperators(migration)
+	if err != nil {
+		return err
+	}
+	opSet := NewOpSet(operatorNames)
+	windowing, err := stream.getMigratedWindowing(migration)
+	if err != nil {
+		return err
+	}
+
+	operatorsSince := make(map[string]int64)
+	for name, since := range stream.operatorsSince {
+		if opSet.GetOp(name) != nil {
+			operatorsSince[name] = since
+		}
+	}
+
+	var windows []*SummaryWindow = nil
+	if len(migration.addOperators) > 0 || windowing != nil {
+		windows, err = stream.manager.GetSummaryWindowInRange(math.MinInt64, math.MaxInt64)
+		if err != nil {
+			return err
+		}
+	}
+
+	var buffers map[int64][]byte = nil
+	if len(migration.addOperators) > 0 {
+		if stream.pipeline.wal != nil {
+			buffers, err = stream.resummarize(opSet, windows)
+			if err != nil {
+				return err
+			}
+			// Every window now holds the data of every operator.
+			operatorsSince = make(map[string]int64)
+		} else {
+			for _, name := range migration.addOperators {
+				operatorsSince[name] = stream.pipeline.lastTimestamp + 1
+			}
+		}
+	}
+
+	var heapBuf, indexBuf []byte = nil, nil
+	if windowing != nil {
+		merger := NewMerger(windowing, 1, nil)
+		merger.numElements = stream.pipeline.merger.numElements
+		merger.Rebuild(windowing, windows)
+		heapBuf, err = HeapToBytes(merger.mergeCounts)
+		if err != nil {
+			return err
+		}
+		indexBuf, err = MergerIndexToBytes(merger.index)
+		if err != nil {
+			return err
+		}
+	} else {
+		windowing = stream.pipeline.windowing
+	}
+
+	streamBuf, err := stream.serialize(opSet, windowing, operatorsSince)
+	if err != nil {
+		return err
+	}
+	err = mds.PutStreamMigration(stream.streamId, streamBuf, buffers, heapBuf, indexBuf)
+	if err != nil {
+		return err
+	}
+
+	// Committed, catch up in memory.
+	resummarizedIDs := make([]int64, 0, len(buffers))
+	for swid := range buffers {
+		resummarizedIDs = append(resummarizedIDs, swid)
+	}
+	stream.manager.backingStore.EvictCache(stream.streamId, resummarizedIDs)
+	stream.manager.operators = opSet
+	stream.operatorsSince = operatorsSince
+	if windowing != stream.pipeline.windowing {
+		stream.pipeline.SetWindowing(windowing, windows)
+	}
+	return nil
+}
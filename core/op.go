@@ -1,10 +1,14 @@
 package core
 
-import "summarydb/protos"
+import (
+	"summarydb/protos"
+	"summarydb/stats"
+)
 
 type QueryParams struct {
 	ConfidenceLevel float64
 	SDMultiplier    float64
+	CIMethod        stats.CIMethod
 }
 
 type AggResult struct {
@@ -19,3 +23,11 @@ type Op interface {
 	EmptyQuery() *AggResult
 	Query([]*SummaryWindow, []*LandmarkWindow, int64, int64, *QueryParams) *AggResult
 }
+
+// Ops whose answers are estimated by GetSumStats, the per-window values of
+// which are exposed to explain the error of a query.
+type SumEstimatedOp interface {
+	Op
+	GetSummaryData(*DataTable) float64
+	GetLandmarkData(float64) float64
+}
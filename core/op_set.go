@@ -37,6 +37,14 @@ func OpProtosToOpNames(opsProto protos.OpType_List) []string {
 	return opNames
 }
 
+func (set *OpSet) GetOpNames() []string {
+	names := make([]string, 0, len(set.ops))
+	for name := range set.ops {
+		names = append(names, name)
+	}
+	return names
+}
+
 func (set *OpSet) GetOp(operatorName string) Op {
 	return set.ops[operatorName]
 }
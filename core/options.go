@@ -0,0 +1,33 @@
+package core
+
+import "github.com/dgraph-io/badger/v2"
+
+// Options of a DB, see DefaultOptions for those of New and Open.
+type Options struct {
+	// Badger returns the options of the Badger DB stored at path.
+	Badger func(path string) badger.Options
+	// Caches of the summary and landmark windows, for each stream. Sizes
+	// are in bytes.
+	CacheEnabled      bool
+	SummaryCacheSize  int64
+	LandmarkCacheSize int64
+	// SyncWAL makes every append fsync the WAL of its stream. Otherwise the
+	// WAL is only synced by flushes.
+	SyncWAL bool
+	// Config given to new streams, which is persisted with them. nil leaves
+	// them unbuffered.
+	StreamConfig *StoreConfig
+}
+
+func DefaultOptions() *Options {
+	return &Options{
+		Badger: func(path string) badger.Options {
+			return badger.DefaultOptions(path).WithTruncate(true)
+		},
+		CacheEnabled:      true,
+		SummaryCacheSize:  DefaultSummaryCacheSize,
+		LandmarkCacheSize: DefaultLandmarkCacheSize,
+		SyncWAL:           false,
+		StreamConfig:      nil,
+	}
+}
@@ -0,0 +1,250 @@
+package core
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+	"summarydb/protos"
+)
+
+// OutOfOrderPolicy decides what becomes of a value appended with a timestamp
+// before the latest one.
+type OutOfOrderPolicy int
+
+const (
+	// Moves the value to just after the latest timestamp.
+	ClampOutOfOrder OutOfOrderPolicy = iota
+	// Fails the append with an *OutOfOrderError.
+	RejectOutOfOrder
+	// Leaves the value out, silently.
+	DropOutOfOrder
+	// Holds back the latest values, and appends them ordered by timestamp.
+	// Values arriving after a later one was appended are rejected.
+	ReorderOutOfOrder
+	// Folds the value into the stored window covering its timestamp. Values
+	// before the first window are rejected.
+	FoldOutOfOrder
+)
+
+type OutOfOrderError struct {
+	Timestamp     int64
+	LastTimestamp int64
+}
+
+func (err *OutOfOrderError) Error() string {
+	return fmt.Sprintf("out of order: %d is before %d", err.Timestamp, err.LastTimestamp)
+}
+
+func (policy OutOfOrderPolicy) Serialize() (protos.OutOfOrderPolicy, error) {
+	switch policy {
+	case ClampOutOfOrder:
+		return protos.OutOfOrderPolicy_clamp, nil
+	case RejectOutOfOrder:
+		return protos.OutOfOrderPolicy_reject, nil
+	case DropOutOfOrder:
+		return protos.OutOfOrderPolicy_drop, nil
+	case ReorderOutOfOrder:
+		return protos.OutOfOrderPolicy_reorder, nil
+	case FoldOutOfOrder:
+		return protos.OutOfOrderPolicy_fold, nil
+	default:
+		return 0, errors.New("unknown out of order policy")
+	}
+}
+
+func DeserializeOutOfOrderPolicy(proto protos.OutOfOrderPolicy) (OutOfOrderPolicy, error) {
+	switch proto {
+	case protos.OutOfOrderPolicy_clamp:
+		return ClampOutOfOrder, nil
+	case protos.OutOfOrderPolicy_reject:
+		return RejectOutOfOrder, nil
+	case protos.OutOfOrderPolicy_drop:
+		return DropOutOfOrder, nil
+	case protos.OutOfOrderPolicy_reorder:
+		return ReorderOutOfOrder, nil
+	case protos.OutOfOrderPolicy_fold:
+		return FoldOutOfOrder, nil
+	default:
+		return 0, errors.New("unknown out of order policy")
+	}
+}
+
+// Returned when summarizing a Folded window again, the WAL is missing values
+// it holds.
+var errFoldedWindow = errors.New("window holds folded values, which are not in the WAL")
+
+// Late values bound for the summary windows are folded in batches, each of
+// which flushes the pipeline.
+const lateBatchSize = 256
+
+// foldLate puts a value arriving before lastTimestamp into the window
+// covering it: a stored landmark window, or else the latest summary window
+// starting at or before it, which is stretched to cover it. Windows keep
+// their element counts, so the merger goes on as if nothing happened.
+//
+// Values bound for the summary windows are queued, and folded in by the next
+// flush of the stream: Flush, queries, Close, or once lateBatchSize of them
+// are queued. Until then they are lost if the stream stops without being
+// closed. Folded values only live in the windows, the WAL doesn't have them:
+// the summary windows are marked Folded. Values falling inside the running
+// landmark are summarized.
+func (stream *Stream) foldLate(timestamp int64, value float64, lastTimestamp int64) error {
+	manager := stream.manager
+	// Landmark windows are only written under appendMutex.
+	start, id := manager.landmarkIndex.GetTree().Floor(timestamp)
+	if id != nil {
+		landmarkWindow, err := manager.GetLandmarkWindow(start)
+		if err != nil {
+			return err
+		}
+		if timestamp <= landmarkWindow.TimeEnd {
+			landmarkWindow.insertLate(timestamp, value)
+			stream.pipeline.countLate(value)
+			return manager.PutLandmarkWindow(landmarkWindow)
+		}
+	}
+
+	if stream.firstWindowStart == math.MinInt64 {
+		// The first window may still be on its way to the store.
+		err := stream.flush()
+		if err != nil {
+			return err
+		}
+		firstStart, id := manager.summaryIndex.GetTree().Min()
+		if id != nil {
+			stream.firstWindowStart = firstStart
+		}
+	}
+	if stream.firstWindowStart == math.MinInt64 || timestamp < stream.firstWindowStart {
+		stream.pipeline.countOutOfOrder(true)
+		return &OutOfOrderError{Timestamp: timestamp, LastTimestamp: lastTimestamp}
+	}
+	stream.lateValues = append(stream.lateValues, Landmark{Timestamp: timestamp, Value: value})
+	stream.pipeline.countLate(value)
+	if len(stream.lateValues) >= lateBatchSize {
+		return stream.flush()
+	}
+	return nil
+}
+
+// flush brings the stored windows up to date with the appended values, and
+// folds in the late values queued meanwhile.
+func (stream *Stream) flush() error {
+	err := stream.pipeline.Flush(false)
+	if err != nil {
+		return err
+	}
+	return stream.foldLateValues()
+}
+
+// foldLateValues folds the queued late values into the stored summary
+// windows, which are brought up to date beforehand.
+func (stream *Stream) foldLateValues() error {
+	if len(stream.lateValues) == 0 {
+		return nil
+	}
+	manager := stream.manager
+	folded := make(map[int64]*SummaryWindow)
+	for _, late := range stream.lateValues {
+		start, id := manager.summaryIndex.GetTree().Floor(late.Timestamp)
+		if id == nil {
+			continue
+		}
+		summaryWindow, ok := folded[start]
+		if !ok {
+			stored, err := manager.GetSummaryWindow(start)
+			if err != nil {
+				return err
+			}
+			// Copy, the window may be shared with the cache.
+			summaryWindow = manager.MergeSummaryWindows([]*SummaryWindow{stored})
+			folded[start] = summaryWindow
+		}
+		manager.InsertIntoSummaryWindow(summaryWindow, late.Timestamp, late.Value)
+		if summaryWindow.TimeEnd < late.Timestamp {
+			summaryWindow.TimeEnd = late.Timestamp
+		}
+		summaryWindow.Folded = true
+	}
+	stream.lateValues = stream.lateValues[:0]
+	windows := make([]*SummaryWindow, 0, len(folded))
+	for _, summaryWindow := range folded {
+		err := manager.PutSummaryWindow(summaryWindow)
+		if err != nil {
+			return err
+		}
+		windows = append(windows, summaryWindow)
+	}
+	return stream.pipeline.merger.Widen(windows)
+}
+
+type reorderEntry struct {
+	Landmark
+	// Arrival order, which breaks ties between equal timestamps.
+	seq uint64
+}
+
+// reorderBuffer is a min-heap of the values held back by the reorder
+// policy, ordered by timestamp and then by arrival.
+type reorderBuffer struct {
+	entries []reorderEntry
+	seq     uint64
+	// Latest timestamp pushed so far.
+	latest int64
+}
+
+func newReorderBuffer() *reorderBuffer {
+	return &reorderBuffer{
+		entries: make([]reorderEntry, 0),
+		seq:     0,
+		latest:  0,
+	}
+}
+
+func (buffer *reorderBuffer) Len() int {
+	return len(buffer.entries)
+}
+
+func (buffer *reorderBuffer) Less(i, j int) bool {
+	a, b := buffer.entries[i], buffer.entries[j]
+	if a.Timestamp != b.Timestamp {
+		return a.Timestamp < b.Timestamp
+	}
+	return a.seq < b.seq
+}
+
+func (buffer *reorderBuffer) Swap(i, j int) {
+	buffer.entries[i], buffer.entries[j] = buffer.entries[j], buffer.entries[i]
+}
+
+func (buffer *reorderBuffer) Push(x interface{}) {
+	buffer.entries = append(buffer.entries, x.(reorderEntry))
+}
+
+func (buffer *reorderBuffer) Pop() interface{} {
+	n := len(buffer.entries)
+	entry := buffer.entries[n-1]
+	buffer.entries = buffer.entries[:n-1]
+	return entry
+}
+
+// Add holds back (timestamp, value), and reports whether a later timestamp
+// was added before it.
+func (buffer *reorderBuffer) Add(timestamp int64, value float64) bool {
+	late := buffer.seq > 0 && timestamp < buffer.latest
+	if !late {
+		buffer.latest = timestamp
+	}
+	heap.Push(buffer, reorderEntry{
+		Landmark: Landmark{Timestamp: timestamp, Value: value},
+		seq:      buffer.seq,
+	})
+	buffer.seq++
+	return late
+}
+
+// Oldest removes and returns the value with the earliest timestamp.
+func (buffer *reorderBuffer) Oldest() Landmark {
+	return heap.Pop(buffer).(reorderEntry).Landmark
+}
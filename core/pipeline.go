@@ -4,18 +4,35 @@ import (
 	"bytes"
 	"context"
 	"encoding/binary"
+	"errors"
 	"log"
+	"math"
 	"math/rand"
 	"os"
 	"strconv"
+	"summarydb/stats"
 	"summarydb/storage"
 	"summarydb/window"
+	"sync"
 	"sync/atomic"
 	"time"
 )
 
 const QueueSize = 100
 
+// WAL entries hold the timestamp and the value, followed by a flag set for
+// values kept by landmark windows. Entries without the flag are 16 bytes.
+const walEntrySize = 16
+
+// Flags of the WAL entries. The values of landmarks opened by the landmark
+// triggers are told apart, so that the landmark still closes on its own once
+// restored.
+const (
+	walValue byte = iota
+	walLandmark
+	walAutoLandmark
+)
+
 type Pipeline struct {
 	streamWindowManager *StreamWindowManager
 	wal                 *storage.Log
@@ -27,7 +44,10 @@ type Pipeline struct {
 	barrier    *Barrier
 	windowing  window.Windowing
 
-	bufferSize    int64
+	bufferSize int64
+	// Buffer size asked for in SetBufferSize, before bufferSize is rounded
+	// down to whole windows.
+	maxBufferSize int64
 	numElements   int64
 	lastTimestamp int64
 
@@ -36,6 +56,11 @@ type Pipeline struct {
 	writerQueue     chan *SummaryWindow
 	mergerQueue     chan *MergeEvent
 
+	statistics *stats.StreamStatistics
+	// Index of the first WAL entry not counted by statistics.
+	statisticsEnd   uint64
+	statisticsMutex sync.Mutex
+
 	logger *log.Logger
 
 	running bool
@@ -57,12 +82,16 @@ func NewPipeline(windowing window.Windowing) *Pipeline {
 		barrier:             barrier,
 		windowing:           windowing,
 		bufferSize:          0,
+		maxBufferSize:       0,
 		numElements:         0,
 		lastTimestamp:       0,
 		partialBuffers:      partialBuffers,
 		summarizerQueue:     summarizerQueue,
 		writerQueue:         writerQueue,
 		mergerQueue:         mergerQueue,
+		statistics:          stats.NewStreamStatistics(),
+		statisticsEnd:       1,
+		statisticsMutex:     sync.Mutex{},
 		logger:              logger,
 		running:             false,
 	}
@@ -78,37 +107,223 @@ func (p *Pipeline) Run(ctx context.Context) {
 }
 
 func (p *Pipeline) Append(timestamp int64, value float64) error {
+	return p.append(timestamp, value, nil, walValue)
+}
+
+// AppendLandmark logs a value kept by a landmark window, opened by the
+// landmark triggers when auto is set. It takes up an element in the summary
+// windows without being summarized, so that element counts stay in step
+// with the WAL.
+func (p *Pipeline) AppendLandmark(timestamp int64, value float64, auto bool) error {
+	if auto {
+		return p.append(timestamp, value, nil, walAutoLandmark)
+	}
+	return p.append(timestamp, value, nil, walLandmark)
+}
+
+// AppendRecord appends a record, with one value per field of the stream. The
+// value statistics follow the first field.
+func (p *Pipeline) AppendRecord(timestamp int64, record []float64) error {
+	return p.append(timestamp, record[0], record, walValue)
+}
+
+// record holds the fields of a record, nil for a value. flag is the WAL flag
+// of the element.
+func (p *Pipeline) append(timestamp int64, value float64, record []float64, flag byte) error {
+	landmark := flag != walValue
 	if timestamp < p.lastTimestamp {
 		p.logger.Printf("Out of order: %d", timestamp)
 		timestamp = p.lastTimestamp + 1
+		p.countOutOfOrder(false)
 	}
+	p.updateStatistics(timestamp, value, landmark)
 
-	if p.bufferSize > 0 {
+	if p.bufferSize > 0 && landmark {
+		p.ingester.AppendLandmark(timestamp, value)
+	} else if p.bufferSize > 0 && record != nil {
+		p.ingester.AppendRecord(timestamp, record)
+	} else if p.bufferSize > 0 {
 		p.ingester.Append(timestamp, value)
 	} else {
-		err := p.appendUnbuffered(timestamp, value)
+		err := p.appendUnbuffered(timestamp, value, record, landmark)
 		if err != nil {
 			return err
 		}
 	}
-	return p.appendWAL(timestamp, value)
+	if record != nil {
+		return p.writeWAL(timestamp, encodeWALRecord(timestamp, record))
+	}
+	return p.appendWAL(timestamp, value, flag)
+}
+
+// countLate counts a value folded into an earlier window, which leaves the
+// arrival intervals as they were.
+func (p *Pipeline) countLate(value float64) {
+	p.statisticsMutex.Lock()
+	p.statistics.AppendLate(value)
+	p.statistics.CountOutOfOrder(false)
+	p.statisticsMutex.Unlock()
+}
+
+// updateStatistics counts the value about to be logged to the WAL, which
+// the baseline leaves out if it is kept by a landmark.
+func (p *Pipeline) updateStatistics(timestamp int64, value float64, landmark bool) {
+	p.statisticsMutex.Lock()
+	p.appendStatistics(timestamp, value, landmark)
+	p.statisticsEnd = uint64(p.numElements) + 2
+	p.statisticsMutex.Unlock()
+}
+
+// countOutOfOrder counts a late value, discarded when it never reaches the
+// pipeline.
+func (p *Pipeline) countOutOfOrder(discarded bool) {
+	p.statisticsMutex.Lock()
+	p.statistics.CountOutOfOrder(discarded)
+	p.statisticsMutex.Unlock()
+}
+
+// GetStatistics returns a snapshot of the arrival statistics, which can be
+// read while appends continue.
+func (p *Pipeline) GetStatistics() *stats.StreamStatistics {
+	p.statisticsMutex.Lock()
+	defer p.statisticsMutex.Unlock()
+	return p.statistics.Copy()
+}
+
+// appendStatistics must be called with the statistics mutex held.
+func (p *Pipeline) appendStatistics(timestamp int64, value float64, landmark bool) {
+	if landmark {
+		p.statistics.AppendLandmark(timestamp, value)
+	} else {
+		p.statistics.Append(timestamp, value)
+	}
+}
+
+// GetBaselineStats returns a snapshot of the running statistics of the
+// values appended outside landmarks.
+func (p *Pipeline) GetBaselineStats() *stats.Welford {
+	p.statisticsMutex.Lock()
+	defer p.statisticsMutex.Unlock()
+	return p.statistics.BaselineStats.Copy()
 }
 
-func (p *Pipeline) appendWAL(timestamp int64, value float64) error {
+// snapshotStatistics returns a snapshot of the statistics, along with the
+// index of the first WAL entry they leave out.
+func (p *Pipeline) snapshotStatistics() (*stats.StreamStatistics, uint64) {
+	p.statisticsMutex.Lock()
+	defer p.statisticsMutex.Unlock()
+	return p.statistics.Copy(), p.statisticsEnd
+}
+
+// SetStatistics sets statistics which leave out the WAL entries from end
+// onwards, 0 if they cover the whole WAL. PrimeUp catches up with those.
+func (p *Pipeline) SetStatistics(statistics *stats.StreamStatistics, end uint64) *Pipeline {
+	p.statisticsMutex.Lock()
+	p.statistics = statistics
+	p.statisticsEnd = end
+	p.statisticsMutex.Unlock()
+	return p
+}
+
+func (p *Pipeline) appendWAL(timestamp int64, value float64, flag byte) error {
+	return p.writeWAL(timestamp, encodeWALEntry(timestamp, value, flag))
+}
+
+// writeWAL counts the element at timestamp and logs its encoded entry.
+func (p *Pipeline) writeWAL(timestamp int64, entry []byte) error {
 	atomic.AddInt64(&p.numElements, 1)
 	atomic.StoreInt64(&p.lastTimestamp, timestamp)
 	if p.wal != nil {
-		var buf bytes.Buffer
-		_ = binary.Write(&buf, binary.LittleEndian, timestamp)
-		_ = binary.Write(&buf, binary.LittleEndian, value)
-		return p.wal.Write(uint64(p.numElements), buf.Bytes())
+		return p.wal.Write(uint64(p.numElements), entry)
 	}
 	return nil
 }
 
-func (p *Pipeline) appendUnbuffered(timestamp int64, value float64) error {
+func encodeWALEntry(timestamp int64, value float64, flag byte) []byte {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, timestamp)
+	_ = binary.Write(&buf, binary.LittleEndian, value)
+	_ = buf.WriteByte(flag)
+	return buf.Bytes()
+}
+
+// Records are logged as their first field, followed by the others.
+func encodeWALRecord(timestamp int64, record []float64) []byte {
+	buf := encodeWALEntry(timestamp, record[0], walValue)
+	for _, value := range record[1:] {
+		buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(value))
+	}
+	return buf
+}
+
+// AppendBatch appends values[i] at timestamps[i] in order, with a single WAL
+// write. The values are copied into the ingest buffers in bulk.
+func (p *Pipeline) AppendBatch(timestamps []int64, values []float64) error {
+	if len(timestamps) != len(values) {
+		return errors.New("timestamps and values differ in length")
+	}
+	if len(timestamps) == 0 {
+		return nil
+	}
+
+	copied := false
+	lastTimestamp := p.lastTimestamp
+	for i := range timestamps {
+		if timestamps[i] < lastTimestamp {
+			if !copied {
+				// Leave the caller's slice alone.
+				timestamps = append([]int64(nil), timestamps...)
+				copied = true
+			}
+			p.logger.Printf("Out of order: %d", timestamps[i])
+			timestamps[i] = lastTimestamp + 1
+			p.countOutOfOrder(false)
+		}
+		lastTimestamp = timestamps[i]
+	}
+
+	p.statisticsMutex.Lock()
+	for i, timestamp := range timestamps {
+		p.statistics.Append(timestamp, values[i])
+	}
+	p.statisticsEnd = uint64(p.numElements+int64(len(timestamps))) + 1
+	p.statisticsMutex.Unlock()
+
+	if p.bufferSize > 0 {
+		p.ingester.AppendBatch(timestamps, values)
+	} else {
+		for i, timestamp := range timestamps {
+			err := p.appendUnbuffered(timestamp, values[i], nil, false)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return p.appendWALBatch(timestamps, values)
+}
+
+func (p *Pipeline) appendWALBatch(timestamps []int64, values []float64) error {
+	first := atomic.AddInt64(&p.numElements, int64(len(timestamps))) - int64(len(timestamps)) + 1
+	atomic.StoreInt64(&p.lastTimestamp, timestamps[len(timestamps)-1])
+	if p.wal == nil {
+		return nil
+	}
+	var batch storage.Batch
+	for i, timestamp := range timestamps {
+		batch.Write(uint64(first+int64(i)), encodeWALEntry(timestamp, values[i], walValue))
+	}
+	return p.wal.WriteBatch(&batch)
+}
+
+// record holds the fields of a record, nil for a value. A landmark value
+// takes up an empty window.
+func (p *Pipeline) appendUnbuffered(timestamp int64, value float64, record []float64, landmark bool) error {
 	newWindow := NewSummaryWindow(timestamp, timestamp, p.numElements, p.numElements)
-	p.streamWindowManager.InsertIntoSummaryWindow(newWindow, timestamp, value)
+	if record != nil {
+		p.streamWindowManager.InsertRecordIntoSummaryWindow(newWindow, timestamp, record)
+	} else if !landmark {
+		p.streamWindowManager.InsertIntoSummaryWindow(newWindow, timestamp, value)
+	}
 	mergeEvent, err := p.writer.Process(newWindow)
 	if err != nil {
 		return err
@@ -123,14 +338,19 @@ func (p *Pipeline) writeRemainingElementsInBuffer() error {
 			select {
 			case partialBuffer := <-p.partialBuffers:
 				if partialBuffer != nil {
-					p.numElements -= partialBuffer.Size
+					atomic.AddInt64(&p.numElements, -partialBuffer.Size)
 					for i := int64(0); i < partialBuffer.Size; i++ {
 						timestamp, value, _ := partialBuffer.Get(i)
-						err := p.appendUnbuffered(timestamp, value)
+						err := p.appendUnbuffered(
+							timestamp, value, partialBuffer.GetRecord(i), partialBuffer.IsLandmark(i))
 						if err != nil {
 							return err
 						}
+						atomic.AddInt64(&p.numElements, 1)
 					}
+					// Counted by the summarizer as well, so that the
+					// windows it summarizes next start after them.
+					p.summarizer.numElements += partialBuffer.Size
 					partialBuffer.Clear()
 				}
 			default:
@@ -149,8 +369,10 @@ func (p *Pipeline) flushWAL(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-randomDelay.C:
-			p.logger.Println("Flushing WAL after: ", timeout)
-			_ = p.wal.Sync()
+			if p.wal != nil {
+				p.logger.Println("Flushing WAL after: ", timeout)
+				_ = p.wal.Sync()
+			}
 			timeout = time.Duration(rand.Int31n(1000)+1) * time.Millisecond
 			randomDelay = time.NewTicker(timeout)
 		}
@@ -226,8 +448,10 @@ func (p *Pipeline) SetBufferSize(maxPerBufferSize int64) *Pipeline {
 	// Ensure that each ingest buffer can be summarized into an integral
 	// number of windows, without leaving anything behind, i.e., in normal
 	// (non-flush) operation, there are no partial buffers.
+	p.maxBufferSize = maxPerBufferSize
 	bufferWindowLengths := p.windowing.GetWindowsCoveringUpto(maxPerBufferSize)
 	p.summarizer.SetWindowLengths(bufferWindowLengths)
+	p.bufferSize = 0
 	for _, length := range bufferWindowLengths {
 		p.bufferSize += length
 	}
@@ -235,6 +459,18 @@ func (p *Pipeline) SetBufferSize(maxPerBufferSize int64) *Pipeline {
 	return p
 }
 
+// SetWindowing switches the windowing of a stopped pipeline. The ingest
+// buffers are split up again under the new windowing, and the merger is
+// rebuilt from windows, the existing summary windows ordered by time.
+func (p *Pipeline) SetWindowing(windowing window.Windowing, windows []*SummaryWindow) *Pipeline {
+	p.windowing = windowing
+	p.merger.Rebuild(windowing, windows)
+	if p.maxBufferSize > 0 {
+		p.SetBufferSize(p.maxBufferSize)
+	}
+	return p
+}
+
 func (p *Pipeline) SetWindowsPerMerge(windowsPerMerge int64) *Pipeline {
 	p.merger.windowsPerBatch = windowsPerMerge
 	return p
@@ -242,6 +478,7 @@ func (p *Pipeline) SetWindowsPerMerge(windowsPerMerge int64) *Pipeline {
 
 func (p *Pipeline) SetUnbuffered() *Pipeline {
 	p.bufferSize = 0
+	p.maxBufferSize = 0
 	return p
 }
 
@@ -251,16 +488,45 @@ func (p *Pipeline) SetNumBuffers(numBuffers int64) *Pipeline {
 }
 
 func (p *Pipeline) ReadEntryFromWAL(idx uint64) (int64, float64, error) {
+	timestamp, value, _, err := p.readWALEntry(idx)
+	return timestamp, value, err
+}
+
+// Also reports whether the value is kept by a landmark window.
+func (p *Pipeline) readWALEntry(idx uint64) (int64, float64, bool, error) {
 	var timestamp int64
 	var value float64
+	landmark := false
 	buf, err := p.wal.Read(idx)
 	if err != nil {
-		return 0, 0, err
+		return 0, 0, false, err
 	}
 	bytesBuf := bytes.NewBuffer(buf)
 	_ = binary.Read(bytesBuf, binary.LittleEndian, &timestamp)
 	_ = binary.Read(bytesBuf, binary.LittleEndian, &value)
-	return timestamp, value, nil
+	if len(buf) > walEntrySize {
+		landmark = buf[walEntrySize] != walValue
+	}
+	return timestamp, value, landmark, nil
+}
+
+func (p *Pipeline) readWALRecord(idx uint64) (int64, []float64, error) {
+	buf, err := p.wal.Read(idx)
+	if err != nil {
+		return 0, nil, err
+	}
+	numFields := p.streamWindowManager.NumFields()
+	if len(buf) != walEntrySize+1+8*(numFields-1) {
+		return 0, nil, errors.New("not a record")
+	}
+	timestamp := int64(binary.LittleEndian.Uint64(buf))
+	record := make([]float64, numFields)
+	record[0] = math.Float64frombits(binary.LittleEndian.Uint64(buf[8:]))
+	for i := 1; i < numFields; i++ {
+		offset := walEntrySize + 1 + 8*(i-1)
+		record[i] = math.Float64frombits(binary.LittleEndian.Uint64(buf[offset:]))
+	}
+	return timestamp, record, nil
 }
 
 func (p *Pipeline) PrimeUp() error {
@@ -278,6 +544,11 @@ func (p *Pipeline) PrimeUp() error {
 		p.summarizer.numElements = p.numElements
 	}
 
+	err := p.catchUpStatistics()
+	if err != nil {
+		return err
+	}
+
 	{
 		err := p.writer.PrimeUp()
 		if err != nil {
@@ -291,7 +562,40 @@ func (p *Pipeline) PrimeUp() error {
 	return nil
 }
 
-func (p *Pipeline) Restore() error {
+// catchUpStatistics counts the values logged to the WAL after the statistics
+// were last written, e.g., before a crash.
+func (p *Pipeline) catchUpStatistics() error {
+	p.statisticsMutex.Lock()
+	defer p.statisticsMutex.Unlock()
+	if p.statisticsEnd == 0 {
+		p.statisticsEnd = uint64(p.numElements) + 1
+		return nil
+	}
+	first, err := p.wal.FirstIndex()
+	if err != nil {
+		return err
+	}
+	n := p.statisticsEnd
+	if n < first {
+		// Truncated before they were counted.
+		n = first
+	}
+	for ; n <= uint64(p.numElements); n++ {
+		timestamp, value, landmark, err := p.readWALEntry(n)
+		if err != nil {
+			return err
+		}
+		p.appendStatistics(timestamp, value, landmark)
+	}
+	p.statisticsEnd = uint64(p.numElements) + 1
+	return nil
+}
+
+// Restore catches up with the elements logged to the WAL but not yet written
+// or merged. It returns the landmark window which was open when the stream
+// stopped, nil if there was none, and whether the landmark triggers opened
+// it.
+func (p *Pipeline) Restore() (*LandmarkWindow, bool, error) {
 	if p.wal == nil {
 		panic("cannot restore without wal")
 	}
@@ -305,31 +609,80 @@ func (p *Pipeline) Restore() error {
 	for n := mergerNum + 1; n < writerNum; n++ {
 		t, _, err := p.ReadEntryFromWAL(uint64(n))
 		if err != nil {
-			return err
+			return nil, false, err
 		}
 		summaryWindow, err := p.streamWindowManager.GetSummaryWindow(t)
 		if err != nil {
-			return err
+			return nil, false, err
 		}
 		mergerEvent := &MergeEvent{
-			Id:   summaryWindow.Id(),
-			Size: summaryWindow.Size(),
+			Id:      summaryWindow.Id(),
+			Size:    summaryWindow.Size(),
+			TimeEnd: summaryWindow.TimeEnd,
 		}
 		err = p.merger.Process(mergerEvent)
 		if err != nil {
-			return err
+			return nil, false, err
 		}
 	}
 
 	for n := writerNum + 1; n < appendNum; n++ {
-		t, v, err := p.ReadEntryFromWAL(uint64(n))
+		t, v, landmark, err := p.readWALEntry(uint64(n))
 		if err != nil {
-			return err
+			return nil, false, err
+		}
+		var record []float64 = nil
+		if p.streamWindowManager.NumFields() > 0 {
+			t, record, err = p.readWALRecord(uint64(n))
+			if err != nil {
+				return nil, false, err
+			}
 		}
-		err = p.appendUnbuffered(t, v)
+		err = p.appendUnbuffered(t, v, record, landmark)
 		if err != nil {
-			return err
+			return nil, false, err
 		}
 	}
-	return nil
+	return p.restoreLandmark()
+}
+
+// Values logged after the latest landmark window ended belong to a landmark
+// which was still open. It is rebuilt from them, starting at the first one,
+// whose flag tells whether the landmark triggers opened it.
+func (p *Pipeline) restoreLandmark() (*LandmarkWindow, bool, error) {
+	ended, _, err := p.streamWindowManager.GetCountAndTime(storage.Landmark)
+	if err != nil {
+		// Not written until the first landmark ends.
+		ended = 0
+	}
+	n := p.numElements
+	for ; n > ended; n-- {
+		_, _, landmark, err := p.readWALEntry(uint64(n))
+		if err != nil {
+			return nil, false, err
+		}
+		if !landmark {
+			break
+		}
+	}
+	if n == p.numElements {
+		return nil, false, nil
+	}
+	buf, err := p.wal.Read(uint64(n + 1))
+	if err != nil {
+		return nil, false, err
+	}
+	auto := buf[walEntrySize] == walAutoLandmark
+	var landmarkWindow *LandmarkWindow = nil
+	for n++; n <= p.numElements; n++ {
+		t, v, _, err := p.readWALEntry(uint64(n))
+		if err != nil {
+			return nil, false, err
+		}
+		if landmarkWindow == nil {
+			landmarkWindow = NewLandmarkWindow(t)
+		}
+		landmarkWindow.Insert(t, v)
+	}
+	return landmarkWindow, auto, nil
 }
@@ -271,3 +271,121 @@ func BenchmarkPipeline_PowerSeq(b *testing.B) {
 	windowing := window.NewGenericWindowing(window.NewPowerLengthsSequence(1, 1, 10, 1))
 	benchmarkPipelineLoop(b, windowing)
 }
+
+// Timestamps of a sparse stretch, one element every 1000 ticks, followed by
+// a dense burst of one element per tick.
+func getBurstyTimestamps() []int64 {
+	timestamps := make([]int64, 0)
+	for i := int64(0); i < 100; i++ {
+		timestamps = append(timestamps, i*1000)
+	}
+	for i := int64(0); i < 2000; i++ {
+		timestamps = append(timestamps, 100000+i)
+	}
+	return timestamps
+}
+
+// With time decay, no two adjacent windows may be due for a merge at the
+// latest time, where each window spans from the end of its predecessor.
+func checkTimeDecayInvariants(t *testing.T,
+	reference window.Windowing, summaryWindows []*SummaryWindow) {
+	now := summaryWindows[len(summaryWindows)-1].TimeEnd + 1
+	count := 0.0
+	for i := 0; i+1 < len(summaryWindows); i++ {
+		start := summaryWindows[i].TimeStart
+		if i > 0 {
+			start = summaryWindows[i-1].TimeEnd + 1
+		}
+		mergeTime, ok := reference.GetFirstContainingTime(
+			start, summaryWindows[i+1].TimeEnd, now)
+		if ok {
+			assert.Greater(t, mergeTime, now)
+		}
+		assert.Less(t, summaryWindows[i].TimeEnd, summaryWindows[i+1].TimeStart)
+		count += summaryWindows[i].Data.Count.Value
+	}
+	count += summaryWindows[len(summaryWindows)-1].Data.Count.Value
+	assert.Equal(t, float64(len(getBurstyTimestamps())), count)
+}
+
+func countWindowsBefore(summaryWindows []*SummaryWindow, t int64) int {
+	numWindows := 0
+	for _, summaryWindow := range summaryWindows {
+		if summaryWindow.TimeEnd < t {
+			numWindows++
+		}
+	}
+	return numWindows
+}
+
+func TestPipeline_TimeDecay_Bursty_Unbuffered(t *testing.T) {
+	newManager := func() *StreamWindowManager {
+		manager := NewStreamWindowManager(0, []string{"count"})
+		manager.SetBackingStore(NewBackingStore(storage.NewInMemoryBackend(), false))
+		return manager
+	}
+	timeManager := newManager()
+	timePipeline := NewPipeline(window.NewTimeWindowing(
+		window.NewGenericWindowing(window.NewExponentialLengthsSequence(2)))).
+		SetWindowManager(timeManager).
+		SetUnbuffered()
+	countManager := newManager()
+	countPipeline := NewPipeline(
+		window.NewGenericWindowing(window.NewExponentialLengthsSequence(2))).
+		SetWindowManager(countManager).
+		SetUnbuffered()
+
+	for _, ts := range getBurstyTimestamps() {
+		assert.NoError(t, timePipeline.Append(ts, 0))
+		assert.NoError(t, countPipeline.Append(ts, 0))
+	}
+
+	timeWindows, err := timeManager.GetSummaryWindowInRange(0, 200000)
+	assert.NoError(t, err)
+	checkTimeDecayInvariants(t, window.NewGenericWindowing(
+		window.NewExponentialLengthsSequence(2)), timeWindows)
+
+	// The burst ages the sparse stretch by 2000 elements but only 2000
+	// ticks, so time decay keeps it at a much finer resolution.
+	countWindows, err := countManager.GetSummaryWindowInRange(0, 200000)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, countWindowsBefore(timeWindows, 100000), 5)
+	assert.LessOrEqual(t, countWindowsBefore(countWindows, 100000), 1)
+}
+
+func TestPipeline_TimeDecay_Bursty_Buffered(t *testing.T) {
+	manager := NewStreamWindowManager(0, []string{"count"})
+	manager.SetBackingStore(NewBackingStore(storage.NewInMemoryBackend(), false))
+	windowing := window.NewTimeWindowing(
+		window.NewPowerWindowing(1, 1, 4, 1))
+	pipeline := NewPipeline(windowing).
+		SetBufferSize(32).
+		SetWindowsPerMerge(4).
+		SetWindowManager(manager)
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	pipeline.Run(ctx)
+	for _, ts := range getBurstyTimestamps() {
+		assert.NoError(t, pipeline.Append(ts, 0))
+	}
+	assert.NoError(t, pipeline.Flush(false))
+
+	summaryWindows, err := manager.GetSummaryWindowInRange(0, 200000)
+	assert.NoError(t, err)
+	checkTimeDecayInvariants(t, window.NewPowerWindowing(1, 1, 4, 1), summaryWindows)
+	cancelFunc()
+}
+
+func TestPipeline_SetWindowing_BufferSize(t *testing.T) {
+	exp := window.NewWindowing(window.NewExponentialLengthsSequence(2))
+	pipeline := NewPipeline(exp).SetBufferSize(10)
+	// Windows of 1, 2 and 4 elements.
+	assert.Equal(t, int64(7), pipeline.bufferSize)
+
+	// Split up again from the size asked for, not the rounded one.
+	uniform := getUniformWindowing(t, 5)
+	pipeline.SetWindowing(uniform, nil)
+	assert.Equal(t, int64(10), pipeline.bufferSize)
+	pipeline.SetWindowing(exp, nil)
+	assert.Equal(t, int64(7), pipeline.bufferSize)
+}
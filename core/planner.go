@@ -0,0 +1,260 @@
+package core
+
+import (
+	"errors"
+	"math"
+	"summarydb/stats"
+	"summarydb/storage"
+	"summarydb/window"
+)
+
+// Simulating more windows than this is too slow to be useful, e.g. a
+// uniform windowing over months of high frequency data.
+const maxPlanWindows = 1 << 22
+
+// A sum or count query over [T - Age - Length, T - Age] at time T.
+type PlanQuery struct {
+	Age    int64
+	Length int64
+}
+
+type PlanConfig struct {
+	// Elements appended per unit of time.
+	Rate float64
+	// Time to simulate, and the interval between snapshots.
+	Horizon int64
+	Step    int64
+	// Mean of the appended values, 1 estimates count queries.
+	MeanValue float64
+	// Fields of the appended records, 0 for a stream of values.
+	Fields  int
+	Queries []*PlanQuery
+	Params  *QueryParams
+}
+
+type PlanQueryEstimate struct {
+	Query *PlanQuery
+	// Exact answer, assuming every value equals MeanValue.
+	Expected float64
+	// Half-width of the confidence interval of the estimate.
+	HalfWidth     float64
+	RelativeError float64
+	// Windows which only partially overlap the query.
+	PartialWindows int
+	// Time from which the whole query range sits in a single window, set
+	// when Merges is true.
+	MergedAt int64
+	Merges   bool
+}
+
+type PlanSnapshot struct {
+	Time     int64
+	Elements int64
+	Windows  int64
+	// Bytes taken by the summary windows, and by the raw values in the WAL.
+	Bytes    int64
+	RawBytes int64
+	// Queries which fit inside [0, Time], in the order of PlanConfig.Queries.
+	Queries []*PlanQueryEstimate
+}
+
+type Plan struct {
+	BytesPerWindow int64
+	BytesPerEntry  int64
+	Snapshots      []*PlanSnapshot
+}
+
+func getBytesPerWindow() (int64, error) {
+	buf, err := SummaryWindowToBytes(NewSummaryWindow(0, 0, 0, 0))
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(buf) + len(storage.GetKey(false, 0, 0))), nil
+}
+
+// Size of an element in the WAL, a value or a record of numFields fields.
+func getBytesPerEntry(numFields int) int64 {
+	if numFields == 0 {
+		return int64(len(encodeWALEntry(0, 0, walValue)))
+	}
+	return int64(len(encodeWALRecord(0, make([]float64, numFields))))
+}
+
+// Windowing of a stream under a steady arrival rate. Windows are laid out
+// from the newest element backwards, the last one holding whatever is left
+// over. Lengths are in elements, or in units of time for time decay.
+type planLayout struct {
+	windowing   window.Windowing
+	timeDecayed bool
+	rate        float64
+}
+
+func (layout *planLayout) toUnits(time int64) int64 {
+	if layout.timeDecayed {
+		return time
+	}
+	return int64(math.Round(float64(time) * layout.rate))
+}
+
+func (layout *planLayout) toTime(units int64) int64 {
+	if layout.timeDecayed {
+		return units
+	}
+	return int64(math.Round(float64(units) / layout.rate))
+}
+
+func (layout *planLayout) elementsPerUnit() float64 {
+	if layout.timeDecayed {
+		return layout.rate
+	}
+	return 1
+}
+
+func (layout *planLayout) getWindows(units int64) ([]int64, error) {
+	maxWindowSize := layout.windowing.GetSeq().MaxWindowSize()
+	if maxWindowSize > 0 && units/maxWindowSize > maxPlanWindows {
+		return nil, errors.New("too many windows to simulate")
+	}
+	windows := layout.windowing.GetWindowsCoveringUpto(units)
+	covered := int64(0)
+	for _, length := range windows {
+		covered += length
+	}
+	if covered < units {
+		windows = append(windows, units-covered)
+	}
+	return windows, nil
+}
+
+func (layout *planLayout) estimate(
+	windows []int64,
+	units int64,
+	query *PlanQuery,
+	config *PlanConfig) *PlanQueryEstimate {
+	// Query range as offsets from the newest element, [a, b].
+	a := layout.toUnits(query.Age)
+	b := layout.toUnits(query.Age+query.Length) - 1
+	if b < a {
+		b = a
+	}
+	perUnit := layout.elementsPerUnit()
+
+	bounds := &stats.Bounds{}
+	meanvar := &stats.Stats{}
+	partialWindows := 0
+	start := int64(0)
+	for _, length := range windows {
+		end := start + length - 1
+		overlap := stats.WindowOverlap(start, end, a, b)
+		if overlap > 0 {
+			count := int64(math.Round(float64(length) * perUnit))
+			info := &WindowInfo{
+				Start:   start,
+				End:     end,
+				Sum:     float64(count) * config.MeanValue,
+				Overlap: overlap,
+				Length:  length,
+				Count:   count,
+			}
+			UpdateEstimate(bounds, meanvar, info)
+			if overlap < length {
+				partialWindows++
+			}
+		}
+		start = end + 1
+	}
+
+	ci := stats.ConvertStatsBoundsToCIWithMethod(
+		bounds,
+		meanvar,
+		config.Params.SDMultiplier,
+		config.Params.ConfidenceLevel,
+		config.Params.CIMethod)
+	estimate := &PlanQueryEstimate{
+		Query:          query,
+		Expected:       float64(b-a+1) * perUnit * config.MeanValue,
+		HalfWidth:      (ci.UpperCI - ci.LowerCI) / 2,
+		PartialWindows: partialWindows,
+	}
+	if estimate.HalfWidth > 0 {
+		estimate.RelativeError = estimate.HalfWidth / math.Abs(estimate.Expected)
+	}
+
+	// Elements are numbered from the oldest one in the windowing.
+	mergedAt, ok := layout.windowing.GetFirstContainingTime(units-1-b, units-1-a, units)
+	if ok {
+		estimate.MergedAt = layout.toTime(mergedAt)
+		estimate.Merges = true
+	}
+	return estimate
+}
+
+// SimulateWindowing plays a steady stream through windowing offline, and
+// reports the number of windows, the storage and the expected error of
+// config.Queries every config.Step up to config.Horizon. The layout is the
+// ideal one, ignoring buffering and batched merges, and errors follow the
+// estimator of the sum and count operators.
+func SimulateWindowing(windowing window.Windowing, config *PlanConfig) (*Plan, error) {
+	if config.Rate <= 0 {
+		return nil, errors.New("rate must be positive")
+	}
+	if config.Horizon <= 0 || config.Step <= 0 {
+		return nil, errors.New("horizon and step must be positive")
+	}
+	if config.Params == nil {
+		return nil, errors.New("query params not set")
+	}
+	if config.Fields < 0 {
+		return nil, errors.New("fields must not be negative")
+	}
+
+	bytesPerWindow, err := getBytesPerWindow()
+	if err != nil {
+		return nil, err
+	}
+	layout := &planLayout{
+		windowing:   windowing,
+		timeDecayed: window.IsTimeDecayed(windowing),
+		rate:        config.Rate,
+	}
+	if timeWindowing, ok := windowing.(*window.TimeWindowing); ok {
+		layout.windowing = timeWindowing.Windowing
+	}
+
+	plan := &Plan{
+		BytesPerWindow: bytesPerWindow,
+		BytesPerEntry:  getBytesPerEntry(config.Fields),
+		Snapshots:      make([]*PlanSnapshot, 0, config.Horizon/config.Step+1),
+	}
+	for t := config.Step; ; t += config.Step {
+		if t > config.Horizon {
+			t = config.Horizon
+		}
+		units := layout.toUnits(t)
+		windows, err := layout.getWindows(units)
+		if err != nil {
+			return nil, err
+		}
+		elements := int64(math.Round(float64(t) * config.Rate))
+		snapshot := &PlanSnapshot{
+			Time:     t,
+			Elements: elements,
+			Windows:  int64(len(windows)),
+			Bytes:    int64(len(windows)) * bytesPerWindow,
+			RawBytes: elements * plan.BytesPerEntry,
+			Queries:  make([]*PlanQueryEstimate, 0, len(config.Queries)),
+		}
+		for _, query := range config.Queries {
+			if query.Age+query.Length > t {
+				continue
+			}
+			snapshot.Queries = append(snapshot.Queries,
+				layout.estimate(windows, units, query, config))
+		}
+		plan.Snapshots = append(plan.Snapshots, snapshot)
+		if t == config.Horizon {
+			break
+		}
+	}
+	return plan, nil
+}
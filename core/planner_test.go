@@ -0,0 +1,151 @@
+package core
+
+import (
+	"github.com/stretchr/testify/assert"
+	"summarydb/window"
+	"testing"
+)
+
+func getPlanTestConfig() *PlanConfig {
+	return &PlanConfig{
+		Rate:      1,
+		Horizon:   100,
+		Step:      50,
+		MeanValue: 1,
+		Queries: []*PlanQuery{
+			{Age: 10, Length: 10},
+			{Age: 5, Length: 10},
+			{Age: 60, Length: 10},
+		},
+		Params: &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1},
+	}
+}
+
+func getUniformWindowing(t *testing.T, length int64) window.Windowing {
+	seq, err := window.NewUniformLengthsSequence(length)
+	assert.NoError(t, err)
+	return window.NewWindowing(seq)
+}
+
+func TestSimulateWindowing_Uniform(t *testing.T) {
+	windowing := getUniformWindowing(t, 10)
+	plan, err := SimulateWindowing(windowing, getPlanTestConfig())
+	assert.NoError(t, err)
+	assert.Len(t, plan.Snapshots, 2)
+
+	first := plan.Snapshots[0]
+	assert.Equal(t, int64(50), first.Time)
+	assert.Equal(t, int64(50), first.Elements)
+	assert.Equal(t, int64(5), first.Windows)
+	assert.Equal(t, 5*plan.BytesPerWindow, first.Bytes)
+	// Timestamp, value and landmark flag.
+	assert.Equal(t, int64(17), plan.BytesPerEntry)
+	assert.Equal(t, int64(50*17), first.RawBytes)
+	// The oldest query only fits in the last snapshot.
+	assert.Len(t, first.Queries, 2)
+
+	last := plan.Snapshots[1]
+	assert.Equal(t, int64(100), last.Time)
+	assert.Equal(t, int64(10), last.Windows)
+	assert.Len(t, last.Queries, 3)
+
+	aligned := last.Queries[0]
+	assert.Equal(t, 10.0, aligned.Expected)
+	assert.Equal(t, 0, aligned.PartialWindows)
+	assert.Equal(t, 0.0, aligned.RelativeError)
+	assert.True(t, aligned.Merges)
+	assert.Equal(t, int64(100), aligned.MergedAt)
+
+	straddling := last.Queries[1]
+	assert.Equal(t, 10.0, straddling.Expected)
+	assert.Equal(t, 2, straddling.PartialWindows)
+	assert.Greater(t, straddling.RelativeError, 0.0)
+	// Windows slide over the elements as they age, so the range lines up
+	// with a single window a few elements later.
+	assert.True(t, straddling.Merges)
+	assert.Greater(t, straddling.MergedAt, int64(100))
+
+	// Longer than any window, so never merged.
+	config := getPlanTestConfig()
+	config.Queries = []*PlanQuery{{Age: 0, Length: 20}}
+	plan, err = SimulateWindowing(getUniformWindowing(t, 10), config)
+	assert.NoError(t, err)
+	assert.False(t, plan.Snapshots[1].Queries[0].Merges)
+
+	// Records log their extra fields after the first.
+	config = getPlanTestConfig()
+	config.Fields = 3
+	plan, err = SimulateWindowing(windowing, config)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(17+2*8), plan.BytesPerEntry)
+	assert.Equal(t, int64(50*(17+2*8)), plan.Snapshots[0].RawBytes)
+}
+
+func TestSimulateWindowing_Exponential(t *testing.T) {
+	config := getPlanTestConfig()
+	config.Rate = 1000
+	config.Horizon = 1000
+	config.Step = 100
+	config.Queries = []*PlanQuery{{Age: 500, Length: 10}}
+
+	windowing := window.NewWindowing(window.NewExponentialLengthsSequence(2))
+	plan, err := SimulateWindowing(windowing, config)
+	assert.NoError(t, err)
+	assert.Len(t, plan.Snapshots, 10)
+
+	// Logarithmic growth of the number of windows.
+	assert.Less(t, plan.Snapshots[9].Windows, int64(25))
+	for i := 1; i < len(plan.Snapshots); i++ {
+		assert.GreaterOrEqual(t, plan.Snapshots[i].Windows, plan.Snapshots[i-1].Windows)
+	}
+
+	// The same query gets older and coarser over time.
+	estimates := make([]*PlanQueryEstimate, 0)
+	for _, snapshot := range plan.Snapshots {
+		estimates = append(estimates, snapshot.Queries...)
+	}
+	assert.Len(t, estimates, 5)
+	query := estimates[len(estimates)-1]
+	assert.Equal(t, 10000.0, query.Expected)
+	assert.Greater(t, query.RelativeError, 0.0)
+	assert.True(t, query.Merges)
+	assert.GreaterOrEqual(t, query.MergedAt, int64(1000))
+}
+
+func TestSimulateWindowing_TimeDecay(t *testing.T) {
+	config := getPlanTestConfig()
+	config.Rate = 4
+
+	windowing := window.NewTimeWindowing(getUniformWindowing(t, 10))
+	plan, err := SimulateWindowing(windowing, config)
+	assert.NoError(t, err)
+
+	last := plan.Snapshots[1]
+	assert.Equal(t, int64(400), last.Elements)
+	// Windows span 10 units of time, whatever the rate.
+	assert.Equal(t, int64(10), last.Windows)
+	assert.Equal(t, 40.0, last.Queries[0].Expected)
+	assert.Equal(t, 0.0, last.Queries[0].RelativeError)
+	assert.Greater(t, last.Queries[1].RelativeError, 0.0)
+}
+
+func TestSimulateWindowing_Invalid(t *testing.T) {
+	windowing := getUniformWindowing(t, 1)
+
+	config := getPlanTestConfig()
+	config.Rate = 0
+	_, err := SimulateWindowing(windowing, config)
+	assert.Error(t, err)
+
+	config = getPlanTestConfig()
+	config.Rate = 1e6
+	config.Horizon = 1e6
+	config.Step = 1e6
+	_, err = SimulateWindowing(windowing, config)
+	assert.Error(t, err)
+
+	config = getPlanTestConfig()
+	config.Fields = -1
+	_, err = SimulateWindowing(windowing, config)
+	assert.Error(t, err)
+}
@@ -0,0 +1,39 @@
+package core
+
+import (
+	"errors"
+	"github.com/dgraph-io/badger/v2"
+	"path"
+)
+
+// ErrReadOnly is returned when writing to a DB, or one of its streams, opened
+// by OpenReadOnly.
+var ErrReadOnly = errors.New("db is opened read-only")
+
+// OpenReadOnly opens a DB to query it, without changing any of its files:
+// streams can't be appended to, run or changed. The values logged to the WAL
+// but not yet summarized, and the open landmarks, are not restored, queries
+// only see the windows written to the DB.
+//
+// The DB can't be opened while a writer has it open, it is meant to query a
+// copy of the DB or one whose ingestion is stopped. Several readers can open
+// it at once.
+func OpenReadOnly(path string) (*DB, error) {
+	return OpenReadOnlyWithOptions(path, DefaultOptions())
+}
+
+func OpenReadOnlyWithOptions(dirName string, options *Options) (*DB, error) {
+	dbPath := path.Join(dirName, "badger")
+	badgerDb, err := badger.Open(options.Badger(dbPath).WithReadOnly(true))
+	if err != nil {
+		return nil, err
+	}
+	db := newDB(dirName, options, badgerDb)
+	db.readOnly = true
+	err = db.ReadDB()
+	if err != nil {
+		_ = db.backend.Close()
+		return nil, err
+	}
+	return db, nil
+}
@@ -0,0 +1,196 @@
+package core
+
+import (
+	"errors"
+	"summarydb/protos"
+	"sync/atomic"
+)
+
+// Field of the records appended to a stream. Each field is summarized by its
+// own operators, in its own data table of every summary window.
+type Field struct {
+	Name      string
+	Operators []string
+}
+
+var errRecordStream = errors.New("stream has fields, use AppendRecord and QueryField")
+
+func validateFields(fields []Field) error {
+	if len(fields) == 0 {
+		return errors.New("no fields")
+	}
+	names := make(map[string]bool)
+	for _, field := range fields {
+		if names[field.Name] {
+			return errors.New("duplicate field " + field.Name)
+		}
+		names[field.Name] = true
+		if len(field.Operators) == 0 {
+			return errors.New("no operators for field " + field.Name)
+		}
+		for _, op := range field.Operators {
+			if _, ok := OpNameOpTypeMap[op]; !ok {
+				return errors.New("unknown operator " + op)
+			}
+		}
+	}
+	return nil
+}
+
+func (stream *Stream) setFields(fields []Field) *Stream {
+	stream.fields = fields
+	stream.manager.SetFields(fields)
+	return stream
+}
+
+// Fields returns the schema of the records, nil for a stream of values.
+func (stream *Stream) Fields() []Field {
+	return stream.fields
+}
+
+func (stream *Stream) fieldIndex(name string) int {
+	for i, field := range stream.fields {
+		if field.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// AppendRecord appends a value for every field at timestamp, as a single
+// element and WAL entry. Records can't be kept by landmarks. Late records
+// follow the out of order policy, except that they can't be reordered or
+// folded: those policies reject them.
+func (stream *Stream) AppendRecord(timestamp int64, record map[string]float64) error {
+	stream.appendMutex.Lock()
+	defer stream.appendMutex.Unlock()
+	if !stream.backendSet {
+		panic("backend not set")
+	}
+	if stream.readOnly {
+		return ErrReadOnly
+	}
+	if !stream.running {
+		panic("stream is not running")
+	}
+	if stream.fields == nil {
+		return errors.New("stream has no fields")
+	}
+	if stream.landmarkWindow != nil {
+		return errors.New("records cannot be appended to landmarks")
+	}
+	if len(record) != len(stream.fields) {
+		return errors.New("record does not match the fields")
+	}
+	values := make([]float64, len(stream.fields))
+	for i, field := range stream.fields {
+		value, ok := record[field.Name]
+		if !ok {
+			return errors.New("missing field " + field.Name)
+		}
+		values[i] = value
+	}
+
+	lastTimestamp := atomic.LoadInt64(&stream.pipeline.lastTimestamp)
+	if timestamp < lastTimestamp {
+		switch stream.outOfOrderPolicy {
+		case RejectOutOfOrder, ReorderOutOfOrder, FoldOutOfOrder:
+			stream.pipeline.countOutOfOrder(true)
+			return &OutOfOrderError{Timestamp: timestamp, LastTimestamp: lastTimestamp}
+		case DropOutOfOrder:
+			stream.pipeline.countOutOfOrder(true)
+			return nil
+		}
+		// Clamped and counted by the pipeline.
+	}
+	err := stream.applyLandmarkRetention(timestamp)
+	if err != nil {
+		return err
+	}
+	return stream.pipeline.AppendRecord(timestamp, values)
+}
+
+// QueryField answers op over the values of a field in [startTime, endTime].
+func (stream *Stream) QueryField(
+	field string,
+	op string,
+	startTime int64,
+	endTime int64,
+	params *QueryParams) (*AggResult, error) {
+	i := stream.fieldIndex(field)
+	if i < 0 {
+		return nil, errors.New("field not found")
+	}
+	opCompute := stream.manager.fields[i].GetOp(op)
+	if opCompute == nil {
+		return nil, errors.New("op not found")
+	}
+
+	stream.appendMutex.Lock()
+	summaryWindows, _, err := stream.getWindowsInRange(startTime, endTime)
+	stream.appendMutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// The windows as seen by the field's operators.
+	fieldWindows := make([]*SummaryWindow, 0, len(summaryWindows))
+	for _, summaryWindow := range summaryWindows {
+		fieldWindow := NewSummaryWindow(
+			summaryWindow.TimeStart, summaryWindow.TimeEnd,
+			summaryWindow.CountStart, summaryWindow.CountEnd)
+		if summaryWindow.Fields != nil {
+			fieldWindow.Data = summaryWindow.Fields[i]
+		}
+		fieldWindows = append(fieldWindows, fieldWindow)
+	}
+	return opCompute.Query(
+		fieldWindows,
+		[]*LandmarkWindow{},
+		startTime,
+		endTime,
+		params), nil
+}
+
+func serializeFields(fields []Field, streamProto *protos.Stream) error {
+	fieldsProto, err := streamProto.NewFields(int32(len(fields)))
+	if err != nil {
+		return err
+	}
+	for i, field := range fields {
+		fieldProto := fieldsProto.At(i)
+		err = fieldProto.SetName(field.Name)
+		if err != nil {
+			return err
+		}
+		opsProto, err := fieldProto.NewOperators(int32(len(field.Operators)))
+		if err != nil {
+			return err
+		}
+		for j, op := range field.Operators {
+			opsProto.Set(j, OpNameOpTypeMap[op].GetOpType())
+		}
+	}
+	return nil
+}
+
+func deserializeFields(streamProto *protos.Stream) ([]Field, error) {
+	fieldsProto, err := streamProto.Fields()
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]Field, fieldsProto.Len())
+	for i := range fields {
+		fieldProto := fieldsProto.At(i)
+		name, err := fieldProto.Name()
+		if err != nil {
+			return nil, err
+		}
+		opsProto, err := fieldProto.Operators()
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = Field{Name: name, Operators: OpProtosToOpNames(opsProto)}
+	}
+	return fields, nil
+}
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+)
+
+// SetRetention deletes the windows of the stream ending more than maxAge
+// before now, whenever retention is applied. 0 falls back on the default
+// retention of the DB, and a negative maxAge keeps the windows forever.
+func (stream *Stream) SetRetention(maxAge int64) *Stream {
+	stream.retention = maxAge
+	return stream
+}
+
+func (stream *Stream) Retention() int64 {
+	return stream.retention
+}
+
+// ApplyRetention deletes the windows ending more than the retention of the
+// stream before now, see TruncateBefore. The landmark being appended to is
+// kept, along with the windows from its start.
+func (stream *Stream) ApplyRetention(now int64) error {
+	return stream.applyRetention(now, stream.retention)
+}
+
+func (stream *Stream) applyRetention(now int64, maxAge int64) error {
+	if maxAge <= 0 || now-maxAge > now {
+		return nil
+	}
+	stream.appendMutex.Lock()
+	defer stream.appendMutex.Unlock()
+	cutoff := now - maxAge
+	if stream.landmarkWindow != nil && stream.landmarkWindow.TimeStart < cutoff {
+		cutoff = stream.landmarkWindow.TimeStart
+	}
+	return stream.truncateBefore(cutoff)
+}
+
+// retentionJob applies retention to the streams of a DB every interval,
+// until stopped.
+type retentionJob struct {
+	stop   chan struct{}
+	done   chan struct{}
+	logger *log.Logger
+}
+
+// SetDefaultRetention sets the retention of the streams which don't set
+// their own, and persists it with the DB. 0 keeps their windows forever.
+func (db *DB) SetDefaultRetention(maxAge int64) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.readOnly {
+		return ErrReadOnly
+	}
+	db.defaultRetention = maxAge
+	dbBuf, err := db.Serialize()
+	if err != nil {
+		return err
+	}
+	return db.mds.PutDB(dbBuf)
+}
+
+// SetRetention sets the retention of a stream, and persists it with the
+// stream, see Stream.SetRetention.
+func (db *DB) SetRetention(streamId int64, maxAge int64) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.readOnly {
+		return ErrReadOnly
+	}
+	stream, ok := db.streams[streamId]
+	if !ok {
+		return errors.New("stream not found")
+	}
+	stream.SetRetention(maxAge)
+	return db.WriteStream(stream)
+}
+
+// ApplyRetention deletes the windows of every stream ending more than its
+// retention, or the default one, before now. All the streams are gone
+// through, the first error is returned. Streams can't be created or deleted
+// meanwhile.
+func (db *DB) ApplyRetention(now int64) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.readOnly {
+		return ErrReadOnly
+	}
+	var firstErr error = nil
+	for _, stream := range db.streams {
+		maxAge := stream.retention
+		if maxAge == 0 {
+			maxAge = db.defaultRetention
+		}
+		err := stream.applyRetention(now, maxAge)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// StartRetention applies retention in the background every interval, until
+// StopRetention or Close. clock tells the current time, in the unit of the
+// timestamps, e.g. time.Now().Unix(). Errors are logged, and retried at the
+// next interval.
+func (db *DB) StartRetention(interval time.Duration, clock func() int64) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.readOnly {
+		return ErrReadOnly
+	}
+	if db.retention != nil {
+		return errors.New("retention already started")
+	}
+	job := &retentionJob{
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+		logger: log.New(os.Stdout, "[Retention]", log.LstdFlags),
+	}
+	db.retention = job
+	go func() {
+		defer close(job.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				err := db.ApplyRetention(clock())
+				if err != nil {
+					job.logger.Println(err)
+				}
+			case <-job.stop:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// StopRetention stops the background retention, and waits for the one being
+// applied to finish.
+func (db *DB) StopRetention() {
+	db.mu.Lock()
+	job := db.retention
+	db.retention = nil
+	db.mu.Unlock()
+	if job == nil {
+		return
+	}
+	close(job.stop)
+	<-job.done
+}
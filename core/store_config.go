@@ -1,7 +1,23 @@
 package core
 
+import "summarydb/protos"
+
 type StoreConfig struct {
 	EachBufferSize  int64
 	NumBuffer       int64
 	WindowsPerMerge int64
 }
+
+func (config *StoreConfig) Serialize(configProto *protos.StoreConfig) {
+	configProto.SetEachBufferSize(config.EachBufferSize)
+	configProto.SetNumBuffer(config.NumBuffer)
+	configProto.SetWindowsPerMerge(config.WindowsPerMerge)
+}
+
+func DeserializeStoreConfig(configProto *protos.StoreConfig) *StoreConfig {
+	return &StoreConfig{
+		EachBufferSize:  configProto.EachBufferSize(),
+		NumBuffer:       configProto.NumBuffer(),
+		WindowsPerMerge: configProto.WindowsPerMerge(),
+	}
+}
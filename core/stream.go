@@ -4,11 +4,15 @@ import (
 	"capnproto.org/go/capnp/v3"
 	"context"
 	"errors"
+	"math"
 	"path"
 	"strconv"
 	"summarydb/protos"
+	"summarydb/stats"
 	"summarydb/storage"
 	"summarydb/window"
+	"sync"
+	"sync/atomic"
 )
 
 type Stream struct {
@@ -20,17 +24,59 @@ type Stream struct {
 	running        bool
 	landmarkWindow *LandmarkWindow
 	ctx            context.Context
+	// Operators added by a migration which could not re-summarize the older
+	// windows, mapped to the first timestamp they cover.
+	operatorsSince map[string]int64
+	// Rules opening landmarks automatically, nil when landmarks are only
+	// opened by StartLandmark. autoLandmark is set while the open landmark
+	// was opened by them.
+	landmarkTriggers *LandmarkTriggers
+	autoLandmark     bool
+	// Landmarks ending more than landmarkRetention before the latest value
+	// are folded into the summary windows, the oldest one at landmarkExpiry.
+	// math.MinInt64 when it has to be looked up again.
+	landmarkRetention int64
+	landmarkExpiry    int64
+	// What becomes of late values. With ReorderOutOfOrder up to
+	// reorderWindow values are held back in reorder.
+	outOfOrderPolicy OutOfOrderPolicy
+	reorderWindow    int
+	reorder          *reorderBuffer
+	// Late values queued by the fold policy, and the start of the first
+	// summary window they may go to, math.MinInt64 when it has to be looked
+	// up again.
+	lateValues       []Landmark
+	firstWindowStart int64
+	// Serializes the producers, and the flushes racing with them.
+	appendMutex sync.Mutex
+	// Queue in front of Append for AppendAsync, nil unless set.
+	async *asyncAppender
+	// Schema of the records, nil for a stream of values.
+	fields []Field
+	// Unique name and labels of the stream, see DB.NewLabelledStream.
+	name   string
+	labels Labels
+	// Age after which windows are deleted, see SetRetention.
+	retention int64
+	// Buffering of the pipeline, nil when it is unbuffered.
+	config *StoreConfig
+	// Set by OpenReadOnly, the stream only serves queries.
+	readOnly bool
 }
 
-func newWAL(dirName string, id int64) (*storage.Log, error) {
+func walPath(dirName string, id int64) string {
+	return path.Join(dirName, "wal-"+strconv.Itoa(int(id)))
+}
+
+func newWAL(dirName string, id int64, readOnly bool) (*storage.Log, error) {
 	if dirName == "" {
 		return nil, nil
 	}
-	walPath := path.Join(dirName, "wal-"+strconv.Itoa(int(id)))
-	walOpts := storage.DefaultOptions
+	walOpts := *storage.DefaultOptions
 	walOpts.NoSync = true
 	walOpts.NoCopy = true
-	return storage.OpenLog(walPath, walOpts)
+	walOpts.ReadOnly = readOnly
+	return storage.OpenLog(walPath(dirName, id), &walOpts)
 }
 
 func NewStreamWithId(
@@ -38,39 +84,105 @@ func NewStreamWithId(
 	id int64,
 	operatorNames []string,
 	windowing window.Windowing) (*Stream, error) {
+	return newStream(dirName, id, operatorNames, windowing, false)
+}
+
+func newStream(
+	dirName string,
+	id int64,
+	operatorNames []string,
+	windowing window.Windowing,
+	readOnly bool) (*Stream, error) {
 	manager := NewStreamWindowManager(id, operatorNames)
-	wal, err := newWAL(dirName, id)
+	wal, err := newWAL(dirName, id, readOnly)
 	if err != nil {
 		return nil, err
 	}
 	pipeline := NewPipeline(windowing).SetWAL(wal)
 
 	return &Stream{
-		streamId:       id,
-		pipeline:       pipeline,
-		manager:        manager,
-		backendSet:     false,
-		running:        false,
-		landmarkWindow: nil,
-		ctx:            nil,
+		streamId:          id,
+		pipeline:          pipeline,
+		manager:           manager,
+		backendSet:        false,
+		running:           false,
+		landmarkWindow:    nil,
+		ctx:               nil,
+		operatorsSince:    make(map[string]int64),
+		landmarkTriggers:  nil,
+		autoLandmark:      false,
+		landmarkRetention: 0,
+		landmarkExpiry:    math.MinInt64,
+		outOfOrderPolicy:  ClampOutOfOrder,
+		reorderWindow:     0,
+		reorder:           newReorderBuffer(),
+		lateValues:        make([]Landmark, 0),
+		firstWindowStart:  math.MinInt64,
+		async:             nil,
+		fields:            nil,
+		name:              "",
+		labels:            nil,
+		retention:         0,
+		config:            nil,
+		readOnly:          readOnly,
 	}, nil
 }
 
+// SetConfig sets the buffering of the pipeline, which is persisted with the
+// stream. It can't be changed while the stream is running.
 func (stream *Stream) SetConfig(config *StoreConfig) *Stream {
+	stream.config = config
 	stream.pipeline.SetBufferSize(config.EachBufferSize)
 	stream.pipeline.SetWindowsPerMerge(config.WindowsPerMerge)
 	stream.pipeline.SetNumBuffers(config.NumBuffer)
 	return stream
 }
 
+// SetLandmarkTriggers sets the rules opening and closing landmarks as values
+// are appended, nil turns them off.
+func (stream *Stream) SetLandmarkTriggers(triggers *LandmarkTriggers) *Stream {
+	stream.landmarkTriggers = triggers
+	return stream
+}
+
+// SetOutOfOrderPolicy sets what becomes of values appended with a timestamp
+// before the latest one. reorderWindow is the number of values held back by
+// ReorderOutOfOrder. Held back values are not in the WAL, nor answered by
+// queries, until they are appended on their turn or by DrainReorderBuffer.
+func (stream *Stream) SetOutOfOrderPolicy(policy OutOfOrderPolicy, reorderWindow int) *Stream {
+	stream.outOfOrderPolicy = policy
+	stream.reorderWindow = reorderWindow
+	return stream
+}
+
+// Config returns the buffering of the pipeline, nil when it is unbuffered.
+func (stream *Stream) Config() *StoreConfig {
+	return stream.config
+}
+
+// SetWALSync makes every append fsync the WAL, rather than only flushes.
+func (stream *Stream) SetWALSync(sync bool) *Stream {
+	if stream.pipeline.wal != nil {
+		stream.pipeline.wal.SetNoSync(!sync)
+	}
+	return stream
+}
+
 func (stream *Stream) SetBackend(backend storage.Backend, cacheEnabled bool) *Stream {
-	stream.manager.SetBackingStore(NewBackingStore(backend, cacheEnabled))
+	return stream.SetBackingStore(NewBackingStore(backend, cacheEnabled))
+}
+
+func (stream *Stream) SetBackingStore(store *BackingStore) *Stream {
+	stream.manager.SetBackingStore(store)
 	stream.pipeline.SetWindowManager(stream.manager)
 	stream.backendSet = true
 	return stream
 }
 
 func (stream *Stream) Run() error {
+	if stream.readOnly {
+		return ErrReadOnly
+	}
 	if stream.ctx == nil {
 		stream.ctx = context.Background()
 	}
@@ -79,6 +191,10 @@ func (stream *Stream) Run() error {
 	}
 	stream.running = true
 	stream.pipeline.Run(stream.ctx)
+	if stream.async != nil {
+		stream.async = stream.async.reopen()
+		go stream.async.run(stream)
+	}
 	return nil
 }
 
@@ -94,31 +210,193 @@ func (stream *Stream) PrimeUp() error {
 	if err != nil {
 		return err
 	}
-	err = stream.pipeline.Restore()
+	if stream.readOnly {
+		// The values logged but not yet summarized, or in the open
+		// landmark, stay in the WAL.
+		return nil
+	}
+	landmarkWindow, auto, err := stream.pipeline.Restore()
 	if err != nil {
 		return err
 	}
+	stream.landmarkWindow = landmarkWindow
+	stream.autoLandmark = auto
+	if auto && stream.landmarkTriggers != nil {
+		landmarks := landmarkWindow.Landmarks
+		stream.landmarkTriggers.resume(landmarks[len(landmarks)-1])
+	}
 	return nil
 }
 
+// Append is safe to call from several goroutines. Values are appended one at
+// a time, in the order the calls get hold of the stream.
 func (stream *Stream) Append(timestamp int64, value float64) error {
+	stream.appendMutex.Lock()
+	defer stream.appendMutex.Unlock()
+	return stream.append(timestamp, value)
+}
+
+func (stream *Stream) append(timestamp int64, value float64) error {
 	if !stream.backendSet {
 		panic("backend not set")
 	}
+	if stream.readOnly {
+		return ErrReadOnly
+	}
 	if !stream.running {
 		panic("stream is not running")
 	}
-	var err error
-	if stream.landmarkWindow != nil {
-		stream.landmarkWindow.Insert(timestamp, value)
-		err = nil
-	} else {
-		err = stream.pipeline.Append(timestamp, value)
+	if stream.fields != nil {
+		return errRecordStream
 	}
-	return err
+	if stream.outOfOrderPolicy != ReorderOutOfOrder && stream.reorder.Len() > 0 {
+		// Left over from the reorder policy.
+		err := stream.drainReorderBuffer()
+		if err != nil {
+			return err
+		}
+	}
+
+	lastTimestamp := atomic.LoadInt64(&stream.pipeline.lastTimestamp)
+	if timestamp < lastTimestamp {
+		switch stream.outOfOrderPolicy {
+		case RejectOutOfOrder, ReorderOutOfOrder:
+			stream.pipeline.countOutOfOrder(true)
+			return &OutOfOrderError{Timestamp: timestamp, LastTimestamp: lastTimestamp}
+		case DropOutOfOrder:
+			stream.pipeline.countOutOfOrder(true)
+			return nil
+		case FoldOutOfOrder:
+			return stream.foldLate(timestamp, value, lastTimestamp)
+		}
+		// Clamped and counted by the pipeline.
+	}
+	if stream.outOfOrderPolicy == ReorderOutOfOrder {
+		if stream.reorder.Add(timestamp, value) {
+			stream.pipeline.countOutOfOrder(false)
+		}
+		for stream.reorder.Len() > stream.reorderWindow {
+			oldest := stream.reorder.Oldest()
+			err := stream.appendInOrder(oldest.Timestamp, oldest.Value)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return stream.appendInOrder(timestamp, value)
+}
+
+// DrainReorderBuffer appends every value held back by the reorder policy.
+func (stream *Stream) DrainReorderBuffer() error {
+	stream.appendMutex.Lock()
+	defer stream.appendMutex.Unlock()
+	return stream.drainReorderBuffer()
+}
+
+func (stream *Stream) drainReorderBuffer() error {
+	for stream.reorder.Len() > 0 {
+		oldest := stream.reorder.Oldest()
+		err := stream.appendInOrder(oldest.Timestamp, oldest.Value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (stream *Stream) appendInOrder(timestamp int64, value float64) error {
+	err := stream.applyLandmarkRetention(timestamp)
+	if err != nil {
+		return err
+	}
+	if stream.landmarkTriggers != nil {
+		err = stream.applyLandmarkTriggers(timestamp, value)
+		if err != nil {
+			return err
+		}
+	}
+	if stream.landmarkWindow == nil {
+		return stream.pipeline.Append(timestamp, value)
+	}
+	err = stream.pipeline.AppendLandmark(timestamp, value, stream.autoLandmark)
+	if err != nil {
+		return err
+	}
+	// At the timestamp logged by the pipeline.
+	stream.landmarkWindow.Insert(atomic.LoadInt64(&stream.pipeline.lastTimestamp), value)
+	return nil
+}
+
+// AppendBatch appends values[i] at timestamps[i], in order. Outside landmarks
+// the values go through the pipeline, and the WAL, in one batch. Landmark
+// triggers, an open landmark and out of order policies other than clamping
+// need to see each value, and fall back to Append.
+func (stream *Stream) AppendBatch(timestamps []int64, values []float64) error {
+	stream.appendMutex.Lock()
+	defer stream.appendMutex.Unlock()
+	if !stream.backendSet {
+		panic("backend not set")
+	}
+	if stream.readOnly {
+		return ErrReadOnly
+	}
+	if !stream.running {
+		panic("stream is not running")
+	}
+	if stream.fields != nil {
+		return errRecordStream
+	}
+	if len(timestamps) != len(values) {
+		return errors.New("timestamps and values differ in length")
+	}
+	if len(timestamps) == 0 {
+		return nil
+	}
+	if stream.landmarkWindow != nil || stream.landmarkTriggers != nil ||
+		stream.outOfOrderPolicy != ClampOutOfOrder || stream.reorder.Len() > 0 {
+		for i, timestamp := range timestamps {
+			err := stream.append(timestamp, values[i])
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	err := stream.applyLandmarkRetention(timestamps[len(timestamps)-1])
+	if err != nil {
+		return err
+	}
+	return stream.pipeline.AppendBatch(timestamps, values)
+}
+
+// Opens a landmark when a rule fires, and closes the landmarks it opened
+// after the quiet period. Landmarks opened by StartLandmark are left alone.
+func (stream *Stream) applyLandmarkTriggers(timestamp int64, value float64) error {
+	fired := stream.landmarkTriggers.Fires(timestamp, value, stream.pipeline.GetBaselineStats())
+	if fired {
+		if stream.landmarkWindow == nil {
+			stream.landmarkWindow = NewLandmarkWindow(timestamp)
+			stream.autoLandmark = true
+		}
+		return nil
+	}
+	if stream.autoLandmark && stream.landmarkTriggers.IsQuiet(timestamp) {
+		landmarks := stream.landmarkWindow.Landmarks
+		return stream.endLandmark(landmarks[len(landmarks)-1].Timestamp)
+	}
+	return nil
 }
 
 func (stream *Stream) StartLandmark(timestamp int64) error {
+	stream.appendMutex.Lock()
+	defer stream.appendMutex.Unlock()
+	if stream.readOnly {
+		return ErrReadOnly
+	}
+	if stream.fields != nil {
+		return errRecordStream
+	}
 	if stream.landmarkWindow != nil {
 		return errors.New("already appending as landmarks")
 	}
@@ -127,61 +405,210 @@ func (stream *Stream) StartLandmark(timestamp int64) error {
 }
 
 func (stream *Stream) EndLandmark(timestamp int64) error {
+	stream.appendMutex.Lock()
+	defer stream.appendMutex.Unlock()
+	return stream.endLandmark(timestamp)
+}
+
+func (stream *Stream) endLandmark(timestamp int64) error {
+	if stream.readOnly {
+		return ErrReadOnly
+	}
 	if stream.landmarkWindow == nil {
 		return errors.New("no running landmark")
 	}
 	stream.landmarkWindow.Close(timestamp)
 	err := stream.manager.PutLandmarkWindow(stream.landmarkWindow)
+	if err != nil {
+		return err
+	}
+	// The values logged so far no longer belong to an open landmark.
+	err = stream.manager.PutCountAndTime(
+		storage.Landmark, atomic.LoadInt64(&stream.pipeline.numElements), timestamp)
 	stream.landmarkWindow = nil
+	stream.autoLandmark = false
+	stream.landmarkExpiry = math.MinInt64
 	return err
 }
 
+// PromoteToLandmark keeps the values appended in [t0, t1] at full
+// resolution, after the fact. The raw values are read back from the WAL into
+// a landmark window, and the summary windows holding them are summarized
+// again without them, so that queries don't count them twice.
+func (stream *Stream) PromoteToLandmark(t0, t1 int64) (*LandmarkWindow, error) {
+	stream.appendMutex.Lock()
+	defer stream.appendMutex.Unlock()
+	if !stream.backendSet {
+		return nil, errors.New("backend not set")
+	}
+	if stream.readOnly {
+		return nil, ErrReadOnly
+	}
+	if t0 > t1 {
+		return nil, errors.New("invalid landmark range")
+	}
+	if stream.pipeline.wal == nil {
+		return nil, errors.New("cannot promote without wal")
+	}
+	if stream.fields != nil {
+		return nil, errRecordStream
+	}
+	if stream.landmarkWindow != nil && stream.landmarkWindow.TimeStart <= t1 {
+		return nil, errors.New("overlaps the running landmark")
+	}
+
+	summaryWindows, landmarkWindows, err := stream.getWindowsInRange(t0, t1)
+	if err != nil {
+		return nil, err
+	}
+	for _, landmarkWindow := range landmarkWindows {
+		if landmarkWindow.TimeStart <= t1 && t0 <= landmarkWindow.TimeEnd {
+			return nil, errors.New("overlaps an existing landmark")
+		}
+	}
+
+	landmarkWindow := NewLandmarkWindow(t0)
+	countStart, countEnd := int64(-1), int64(-1)
+	resummarized := make([]*SummaryWindow, 0)
+	for _, summaryWindow := range summaryWindows {
+		if summaryWindow.TimeEnd < t0 || summaryWindow.TimeStart > t1 {
+			continue
+		}
+		if summaryWindow.Folded {
+			return nil, errFoldedWindow
+		}
+		values, counts, err := stream.readRawEntries(summaryWindow.CountStart, summaryWindow.CountEnd)
+		if err != nil {
+			return nil, err
+		}
+		newWindow := NewSummaryWindow(
+			summaryWindow.TimeStart, summaryWindow.TimeEnd,
+			summaryWindow.CountStart, summaryWindow.CountEnd)
+		for i, value := range values {
+			if t0 <= value.Timestamp && value.Timestamp <= t1 {
+				landmarkWindow.Insert(value.Timestamp, value.Value)
+				if countStart == -1 {
+					countStart = counts[i]
+				}
+				countEnd = counts[i]
+			} else {
+				stream.manager.InsertIntoSummaryWindow(newWindow, value.Timestamp, value.Value)
+			}
+		}
+		resummarized = append(resummarized, newWindow)
+	}
+	landmarkWindow.Close(t1)
+	// Timestamps don't decrease through the WAL, and no landmark overlaps:
+	// the promoted values are the contiguous elements between these.
+	landmarkWindow.promote(countStart, countEnd)
+
+	err = stream.manager.LandmarkBrew(landmarkWindow, resummarized)
+	if err != nil {
+		return nil, err
+	}
+	stream.landmarkExpiry = math.MinInt64
+	return landmarkWindow, nil
+}
+
 func (stream *Stream) Flush() error {
-	return stream.pipeline.Flush(false)
+	stream.appendMutex.Lock()
+	defer stream.appendMutex.Unlock()
+	if stream.readOnly {
+		return nil
+	}
+	return stream.flush()
 }
 
 func (stream *Stream) Close() error {
+	if stream.running && stream.async != nil {
+		err := stream.async.close()
+		if err != nil {
+			return err
+		}
+	}
+	stream.appendMutex.Lock()
+	defer stream.appendMutex.Unlock()
+	if stream.running {
+		err := stream.drainReorderBuffer()
+		if err != nil {
+			return err
+		}
+	}
 	err := stream.pipeline.Flush(true)
 	if err != nil {
 		return err
 	}
+	err = stream.foldLateValues()
+	if err != nil {
+		return err
+	}
 	err = stream.pipeline.wal.Close()
 	if err != nil {
 		return err
 	}
 	stream.ctx = nil
+	stream.running = false
 	return nil
 }
 
-func (stream *Stream) Query(
-	op string,
-	startTime int64,
-	endTime int64,
-	params *QueryParams) (*AggResult, error) {
+// Statistics returns a snapshot of the interarrival and value statistics
+// of all the values appended to the stream.
+func (stream *Stream) Statistics() *stats.StreamStatistics {
+	return stream.pipeline.GetStatistics()
+}
+
+// Callers hold appendMutex, as the pending writes are flushed.
+func (stream *Stream) getWindowsInRange(startTime, endTime int64) (
+	[]*SummaryWindow, []*LandmarkWindow, error) {
 	if !stream.backendSet {
 		panic("backend not set")
 	}
 
 	if stream.running {
 		// sync writes
-		err := stream.Flush()
+		err := stream.flush()
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
 	summaryWindows, err := stream.pipeline.streamWindowManager.
 		GetSummaryWindowInRange(startTime, endTime)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	landmarkWindows, err := stream.pipeline.streamWindowManager.
 		GetLandmarkWindowInRange(startTime, endTime)
+	if err != nil {
+		return nil, nil, err
+	}
+	return summaryWindows, landmarkWindows, nil
+}
+
+func (stream *Stream) Query(
+	op string,
+	startTime int64,
+	endTime int64,
+	params *QueryParams) (*AggResult, error) {
+	if stream.fields != nil {
+		return nil, errRecordStream
+	}
+	stream.appendMutex.Lock()
+	summaryWindows, landmarkWindows, err := stream.getWindowsInRange(startTime, endTime)
+	stream.appendMutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	err = stream.checkOperatorCoverage(op, startTime, summaryWindows)
 	if err != nil {
 		return nil, err
 	}
 
 	opCompute := stream.manager.operators.GetOp(op)
+	if opCompute == nil {
+		return nil, errors.New("op not found")
+	}
 
 	return opCompute.Query(
 		summaryWindows,
@@ -191,7 +618,130 @@ func (stream *Stream) Query(
 		params), nil
 }
 
+// QueryWithAccuracy answers op over [startTime, endTime] and reports whether
+// the relative error is within maxRelativeError. Misses are answered again
+// from the raw values in the WAL, see the package level QueryWithAccuracy.
+func (stream *Stream) QueryWithAccuracy(
+	op string,
+	startTime int64,
+	endTime int64,
+	maxRelativeError float64,
+	params *QueryParams) (*AccuracyResult, error) {
+	if stream.fields != nil {
+		return nil, errRecordStream
+	}
+	stream.appendMutex.Lock()
+	summaryWindows, landmarkWindows, err := stream.getWindowsInRange(startTime, endTime)
+	stream.appendMutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	opCompute := stream.manager.operators.GetOp(op)
+	if opCompute == nil {
+		return nil, errors.New("op not found")
+	}
+	err = stream.checkOperatorCoverage(op, startTime, summaryWindows)
+	if err != nil {
+		return nil, err
+	}
+
+	var readRaw RawValueReader = nil
+	if stream.pipeline.wal != nil {
+		readRaw = stream.readRawValues
+	}
+
+	return QueryWithAccuracy(
+		opCompute,
+		summaryWindows,
+		landmarkWindows,
+		startTime,
+		endTime,
+		maxRelativeError,
+		params,
+		readRaw)
+}
+
+// Summary windows from before op was added hold no data for it.
+func (stream *Stream) checkOperatorCoverage(
+	op string, startTime int64, summaryWindows []*SummaryWindow) error {
+	since, ok := stream.operatorsSince[op]
+	if !ok {
+		return nil
+	}
+	for _, summaryWindow := range summaryWindows {
+		if summaryWindow.TimeEnd >= startTime && summaryWindow.TimeStart < since {
+			return errors.New("op does not cover windows from before it was added")
+		}
+	}
+	return nil
+}
+
+// Summary windows number their elements from 0, the WAL from 1. Values kept
+// by landmark windows are left out, as they are by the summaries.
+func (stream *Stream) readRawValues(countStart, countEnd int64) ([]Landmark, error) {
+	landmarks, _, err := stream.readRawEntries(countStart, countEnd)
+	return landmarks, err
+}
+
+// readRawEntries also returns the element of each value. The values of
+// promoted landmark windows are not flagged in the WAL, they are left out by
+// the elements the windows were promoted from.
+func (stream *Stream) readRawEntries(countStart, countEnd int64) ([]Landmark, []int64, error) {
+	landmarks := make([]Landmark, 0, countEnd-countStart+1)
+	counts := make([]int64, 0, countEnd-countStart+1)
+	for n := countStart; n <= countEnd; n++ {
+		timestamp, value, landmark, err := stream.pipeline.readWALEntry(uint64(n + 1))
+		if err != nil {
+			return nil, nil, err
+		}
+		if landmark {
+			continue
+		}
+		landmarks = append(landmarks, Landmark{
+			Timestamp: timestamp,
+			Value:     value,
+		})
+		counts = append(counts, n)
+	}
+	if len(landmarks) == 0 {
+		return landmarks, counts, nil
+	}
+
+	landmarkWindows, err := stream.manager.GetLandmarkWindowInRange(
+		landmarks[0].Timestamp, landmarks[len(landmarks)-1].Timestamp)
+	if err != nil {
+		return nil, nil, err
+	}
+	promoted := func(n int64) bool {
+		for _, landmarkWindow := range landmarkWindows {
+			if landmarkWindow.holdsElement(n) {
+				return true
+			}
+		}
+		return false
+	}
+	summarized, summarizedCounts := landmarks[:0], counts[:0]
+	for i, landmark := range landmarks {
+		if !promoted(counts[i]) {
+			summarized = append(summarized, landmark)
+			summarizedCounts = append(summarizedCounts, counts[i])
+		}
+	}
+	return summarized, summarizedCounts, nil
+}
+
 func (stream *Stream) Serialize() ([]byte, error) {
+	return stream.serialize(
+		stream.manager.operators,
+		stream.pipeline.windowing,
+		stream.operatorsSince)
+}
+
+func (stream *Stream) serialize(
+	opSet *OpSet,
+	windowing window.Windowing,
+	operatorsSince map[string]int64) ([]byte, error) {
 	msg, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
 	if err != nil {
 		return nil, err
@@ -205,7 +755,6 @@ func (stream *Stream) Serialize() ([]byte, error) {
 	streamProto.SetId(stream.streamId)
 
 	// Operators
-	opSet := stream.manager.operators
 	opProtoList, err := streamProto.NewOperators(int32(len(opSet.ops)))
 	if err != nil {
 		return nil, err
@@ -217,12 +766,97 @@ func (stream *Stream) Serialize() ([]byte, error) {
 	}
 
 	// Windowing
-	seq := stream.pipeline.windowing.GetSeq()
+	seq := windowing.GetSeq()
 	windowProto := streamProto.Window()
 	err = seq.Serialize(&windowProto)
 	if err != nil {
 		return nil, err
 	}
+	if window.IsTimeDecayed(windowing) {
+		streamProto.SetDecay(protos.Decay_time)
+	} else {
+		streamProto.SetDecay(protos.Decay_count)
+	}
+
+	// Statistics
+	statisticsProto, err := streamProto.NewStatistics()
+	if err != nil {
+		return nil, err
+	}
+	statistics, statisticsEnd := stream.pipeline.snapshotStatistics()
+	err = statistics.Serialize(&statisticsProto)
+	if err != nil {
+		return nil, err
+	}
+	streamProto.SetStatisticsEnd(statisticsEnd)
+
+	// Retention
+	streamProto.SetRetention(stream.retention)
+
+	// Buffering
+	if stream.config != nil {
+		configProto, err := streamProto.NewConfig()
+		if err != nil {
+			return nil, err
+		}
+		stream.config.Serialize(&configProto)
+	}
+
+	// Landmarks
+	streamProto.SetLandmarkRetention(stream.landmarkRetention)
+	if stream.landmarkTriggers != nil {
+		triggersProto, err := streamProto.NewLandmarkTriggers()
+		if err != nil {
+			return nil, err
+		}
+		err = stream.landmarkTriggers.Serialize(&triggersProto)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Out of order values
+	policyProto, err := stream.outOfOrderPolicy.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	streamProto.SetOutOfOrderPolicy(policyProto)
+	streamProto.SetReorderWindow(uint32(stream.reorderWindow))
+
+	// Fields of the records
+	if stream.fields != nil {
+		err = serializeFields(stream.fields, &streamProto)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Name and labels
+	err = streamProto.SetName(stream.name)
+	if err != nil {
+		return nil, err
+	}
+	if len(stream.labels) > 0 {
+		err = serializeLabels(stream.labels, &streamProto)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Operators added by migrations
+	if len(operatorsSince) > 0 {
+		sinceProtoList, err := streamProto.NewOperatorsSince(int32(len(operatorsSince)))
+		if err != nil {
+			return nil, err
+		}
+		it = 0
+		for name, since := range operatorsSince {
+			sinceProto := sinceProtoList.At(it)
+			sinceProto.SetOp(opSet.GetOp(name).GetOpType())
+			sinceProto.SetTimestamp(since)
+			it += 1
+		}
+	}
 
 	// Marshal
 	buf, err := msg.Marshal()
@@ -234,6 +868,10 @@ func (stream *Stream) Serialize() ([]byte, error) {
 }
 
 func DeserializeStream(dirName string, buf []byte) (*Stream, error) {
+	return deserializeStream(dirName, buf, false)
+}
+
+func deserializeStream(dirName string, buf []byte, readOnly bool) (*Stream, error) {
 	msg, err := capnp.Unmarshal(buf)
 	if err != nil {
 		return nil, err
@@ -256,6 +894,86 @@ func DeserializeStream(dirName string, buf []byte) (*Stream, error) {
 	if err != nil {
 		return nil, err
 	}
-	windowing := window.NewGenericWindowing(seq)
-	return NewStreamWithId(dirName, id, opNames, windowing)
+	var windowing window.Windowing = window.NewWindowing(seq)
+	if streamProto.Decay() == protos.Decay_time {
+		windowing = window.NewTimeWindowing(windowing)
+	}
+	stream, err := newStream(dirName, id, opNames, windowing, readOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	if streamProto.HasStatistics() {
+		statisticsProto, err := streamProto.Statistics()
+		if err != nil {
+			return nil, err
+		}
+		statistics := stats.NewStreamStatistics()
+		err = statistics.Deserialize(&statisticsProto)
+		if err != nil {
+			return nil, err
+		}
+		stream.pipeline.SetStatistics(statistics, streamProto.StatisticsEnd())
+	}
+
+	stream.SetRetention(streamProto.Retention())
+	if streamProto.HasConfig() {
+		configProto, err := streamProto.Config()
+		if err != nil {
+			return nil, err
+		}
+		stream.SetConfig(DeserializeStoreConfig(&configProto))
+	}
+	stream.SetLandmarkRetention(streamProto.LandmarkRetention())
+	if streamProto.HasLandmarkTriggers() {
+		triggersProto, err := streamProto.LandmarkTriggers()
+		if err != nil {
+			return nil, err
+		}
+		triggers, err := DeserializeLandmarkTriggers(&triggersProto)
+		if err != nil {
+			return nil, err
+		}
+		stream.SetLandmarkTriggers(triggers)
+	}
+
+	policy, err := DeserializeOutOfOrderPolicy(streamProto.OutOfOrderPolicy())
+	if err != nil {
+		return nil, err
+	}
+	stream.SetOutOfOrderPolicy(policy, int(streamProto.ReorderWindow()))
+
+	if streamProto.HasFields() {
+		fields, err := deserializeFields(&streamProto)
+		if err != nil {
+			return nil, err
+		}
+		stream.setFields(fields)
+	}
+
+	name, err := streamProto.Name()
+	if err != nil {
+		return nil, err
+	}
+	stream.name = name
+	if streamProto.HasLabels() {
+		labels, err := deserializeLabels(&streamProto)
+		if err != nil {
+			return nil, err
+		}
+		stream.labels = labels
+	}
+
+	if streamProto.HasOperatorsSince() {
+		sinceProtoList, err := streamProto.OperatorsSince()
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < sinceProtoList.Len(); i++ {
+			sinceProto := sinceProtoList.At(i)
+			name := OpTypeOpStringMap[sinceProto.Op()]
+			stream.operatorsSince[name] = sinceProto.Timestamp()
+		}
+	}
+	return stream, nil
 }
@@ -0,0 +1,174 @@
+package core
+
+import (
+	"errors"
+	"math"
+)
+
+type LandmarkInfo struct {
+	TimeStart int64
+	TimeEnd   int64
+	NumValues int
+}
+
+// ListLandmarks describes the landmark windows of the stream, oldest first.
+// A landmark still being appended to is not included.
+func (stream *Stream) ListLandmarks() ([]*LandmarkInfo, error) {
+	if !stream.backendSet {
+		return nil, errors.New("backend not set")
+	}
+	landmarkWindows, err := stream.manager.GetLandmarkWindowInRange(math.MinInt64, math.MaxInt64)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]*LandmarkInfo, 0, len(landmarkWindows))
+	for _, landmarkWindow := range landmarkWindows {
+		infos = append(infos, &LandmarkInfo{
+			TimeStart: landmarkWindow.TimeStart,
+			TimeEnd:   landmarkWindow.TimeEnd,
+			NumValues: len(landmarkWindow.Landmarks),
+		})
+	}
+	return infos, nil
+}
+
+func (stream *Stream) getLandmarkWindow(timeStart int64) (*LandmarkWindow, error) {
+	if !stream.backendSet {
+		return nil, errors.New("backend not set")
+	}
+	if !stream.manager.landmarkIndex.GetTree().Exists(timeStart) {
+		return nil, errors.New("landmark not found")
+	}
+	return stream.manager.GetLandmarkWindow(timeStart)
+}
+
+// DeleteLandmark drops the landmark window starting at timeStart, along with
+// its values.
+func (stream *Stream) DeleteLandmark(timeStart int64) error {
+	stream.appendMutex.Lock()
+	defer stream.appendMutex.Unlock()
+	if stream.readOnly {
+		return ErrReadOnly
+	}
+	_, err := stream.getLandmarkWindow(timeStart)
+	if err != nil {
+		return err
+	}
+	stream.landmarkExpiry = math.MinInt64
+	return stream.manager.DeleteLandmarkWindow(timeStart)
+}
+
+// FoldLandmark moves the values of the landmark window starting at timeStart
+// into the summary windows around them, where they decay like any other
+// value. Each value goes to the latest window starting at or before it,
+// which is stretched to cover it.
+//
+// Folded values only live in the summaries, the WAL doesn't place them in
+// any window.
+func (stream *Stream) FoldLandmark(timeStart int64) error {
+	stream.appendMutex.Lock()
+	defer stream.appendMutex.Unlock()
+	return stream.foldLandmark(timeStart)
+}
+
+func (stream *Stream) foldLandmark(timeStart int64) error {
+	if stream.readOnly {
+		return ErrReadOnly
+	}
+	landmarkWindow, err := stream.getLandmarkWindow(timeStart)
+	if err != nil {
+		return err
+	}
+	summaryWindows, _, err := stream.getWindowsInRange(
+		landmarkWindow.TimeStart, landmarkWindow.TimeEnd)
+	if err != nil {
+		return err
+	}
+	if len(summaryWindows) == 0 {
+		return errors.New("no summary windows to fold into")
+	}
+
+	folded := make([]*SummaryWindow, 0, len(summaryWindows))
+	for _, summaryWindow := range summaryWindows {
+		// Copy, the windows may be shared with the cache.
+		folded = append(folded, stream.manager.MergeSummaryWindows(
+			[]*SummaryWindow{summaryWindow}))
+	}
+	changed := make(map[int64]bool)
+	i := 0
+	for _, landmark := range landmarkWindow.Landmarks {
+		for i+1 < len(folded) && folded[i+1].TimeStart <= landmark.Timestamp {
+			i++
+		}
+		summaryWindow := folded[i]
+		stream.manager.InsertIntoSummaryWindow(summaryWindow, landmark.Timestamp, landmark.Value)
+		if summaryWindow.TimeEnd < landmark.Timestamp {
+			summaryWindow.TimeEnd = landmark.Timestamp
+		}
+		summaryWindow.Folded = true
+		changed[summaryWindow.Id()] = true
+	}
+
+	updated := make([]*SummaryWindow, 0, len(changed))
+	for _, summaryWindow := range folded {
+		if changed[summaryWindow.Id()] {
+			updated = append(updated, summaryWindow)
+		}
+	}
+	stream.landmarkExpiry = math.MinInt64
+	err = stream.manager.LandmarkFoldBrew(landmarkWindow.Id(), updated)
+	if err != nil {
+		return err
+	}
+	return stream.pipeline.merger.Widen(updated)
+}
+
+// SetLandmarkRetention folds landmark windows into the summary windows once
+// they end more than maxAge before the latest appended value. 0 keeps them
+// forever.
+func (stream *Stream) SetLandmarkRetention(maxAge int64) *Stream {
+	stream.landmarkRetention = maxAge
+	stream.landmarkExpiry = math.MinInt64
+	return stream
+}
+
+// ApplyLandmarkRetention folds every landmark window which ended more than
+// the retention before now.
+func (stream *Stream) ApplyLandmarkRetention(now int64) error {
+	stream.appendMutex.Lock()
+	defer stream.appendMutex.Unlock()
+	return stream.foldExpiredLandmarks(now)
+}
+
+func (stream *Stream) foldExpiredLandmarks(now int64) error {
+	if stream.landmarkRetention <= 0 {
+		return nil
+	}
+	landmarkTree := stream.manager.landmarkIndex.GetTree()
+	for !landmarkTree.IsEmpty() {
+		oldest, _ := landmarkTree.Min()
+		landmarkWindow, err := stream.manager.GetLandmarkWindow(oldest)
+		if err != nil {
+			return err
+		}
+		expiry := landmarkWindow.TimeEnd + stream.landmarkRetention
+		if now <= expiry {
+			stream.landmarkExpiry = expiry
+			return nil
+		}
+		err = stream.foldLandmark(landmarkWindow.Id())
+		if err != nil {
+			return err
+		}
+	}
+	stream.landmarkExpiry = math.MaxInt64
+	return nil
+}
+
+// Folds expired landmarks as time moves past the oldest one.
+func (stream *Stream) applyLandmarkRetention(timestamp int64) error {
+	if stream.landmarkRetention <= 0 || timestamp <= stream.landmarkExpiry {
+		return nil
+	}
+	return stream.foldExpiredLandmarks(timestamp)
+}
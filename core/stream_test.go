@@ -2,6 +2,7 @@ package core
 
 import (
 	"github.com/stretchr/testify/assert"
+	"summarydb/storage"
 	"summarydb/window"
 	"testing"
 )
@@ -34,6 +35,73 @@ func TestStream_Serialize_Deserialize_Power(t *testing.T) {
 	testStreamSerializeDeserialize(t, power)
 }
 
+func TestStream_Serialize_Deserialize_Statistics(t *testing.T) {
+	windowing := window.NewGenericWindowing(window.NewExponentialLengthsSequence(2))
+	stream, err := NewStreamWithId("", 0, []string{"count"}, windowing)
+	assert.NoError(t, err)
+	for i := int64(0); i < 10; i++ {
+		stream.pipeline.updateStatistics(3*i, float64(i), false)
+	}
+	bytes, err := stream.Serialize()
+	assert.NoError(t, err)
+
+	newStream, err := DeserializeStream("", bytes)
+	assert.NoError(t, err)
+	assert.Equal(t, stream.Statistics(), newStream.Statistics())
+}
+
+func TestStream_Serialize_Deserialize_TimeDecay(t *testing.T) {
+	exp := window.NewExponentialLengthsSequence(2)
+	windowing := window.NewTimeWindowing(window.NewGenericWindowing(exp))
+	stream, err := NewStreamWithId("", 0, []string{"count"}, windowing)
+	assert.NoError(t, err)
+	bytes, err := stream.Serialize()
+	assert.NoError(t, err)
+
+	newStream, err := DeserializeStream("", bytes)
+	assert.NoError(t, err)
+	assert.True(t, window.IsTimeDecayed(newStream.pipeline.windowing))
+	assert.True(t, exp.Equals(newStream.pipeline.windowing.GetSeq()))
+
+	// Count decay stays the default.
+	stream, err = NewStreamWithId("", 0, []string{"count"},
+		window.NewGenericWindowing(exp))
+	assert.NoError(t, err)
+	bytes, err = stream.Serialize()
+	assert.NoError(t, err)
+	newStream, err = DeserializeStream("", bytes)
+	assert.NoError(t, err)
+	assert.False(t, window.IsTimeDecayed(newStream.pipeline.windowing))
+}
+
+func TestStream_Serialize_Deserialize_Config(t *testing.T) {
+	windowing := window.NewGenericWindowing(window.NewExponentialLengthsSequence(2))
+	stream, err := NewStreamWithId("", 0, []string{"count"}, windowing)
+	assert.NoError(t, err)
+	bytes, err := stream.Serialize()
+	assert.NoError(t, err)
+	newStream, err := DeserializeStream("", bytes)
+	assert.NoError(t, err)
+	assert.Nil(t, newStream.Config())
+	assert.Equal(t, int64(0), newStream.pipeline.bufferSize)
+
+	config := &StoreConfig{EachBufferSize: 8, NumBuffer: 2, WindowsPerMerge: 4}
+	stream.SetConfig(config)
+	bufferSize := stream.pipeline.bufferSize
+	assert.Greater(t, bufferSize, int64(0))
+	// Setting it again doesn't grow the buffers.
+	stream.SetConfig(config)
+	assert.Equal(t, bufferSize, stream.pipeline.bufferSize)
+
+	bytes, err = stream.Serialize()
+	assert.NoError(t, err)
+	newStream, err = DeserializeStream("", bytes)
+	assert.NoError(t, err)
+	assert.Equal(t, config, newStream.Config())
+	assert.Equal(t, bufferSize, newStream.pipeline.bufferSize)
+	assert.Equal(t, int64(4), newStream.pipeline.merger.windowsPerBatch)
+}
+
 func BenchmarkStream_Serialize(b *testing.B) {
 	power := window.NewPowerLengthsSequence(1, 2, 3, 4)
 	windowing := window.NewGenericWindowing(power)
@@ -95,3 +163,57 @@ func BenchmarkStream_SerializeDeserialize(b *testing.B) {
 		}
 	}
 }
+
+func TestStream_Migrate_WithoutWAL(t *testing.T) {
+	backend := storage.NewInMemoryBackend()
+	mds := storage.NewSimpleMetadataStore().SetBackend(backend)
+	windowing := window.NewWindowing(window.NewExponentialLengthsSequence(2))
+	stream, err := NewStreamWithId("", 0, []string{"count"}, windowing)
+	assert.NoError(t, err)
+	stream.SetBackend(backend, false)
+	for i := int64(0); i < 100; i++ {
+		err := stream.pipeline.Append(i, float64(i))
+		assert.NoError(t, err)
+	}
+
+	err = stream.Migrate(NewStreamMigration().AddOperators("sum"), mds)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), stream.operatorsSince["sum"])
+	for i := int64(100); i < 200; i++ {
+		err := stream.pipeline.Append(i, 1)
+		assert.NoError(t, err)
+	}
+
+	params := &QueryParams{ConfidenceLevel: 0.95, SDMultiplier: 1}
+	// The older windows carry no sums.
+	_, err = stream.Query("sum", 0, 199, params)
+	assert.Error(t, err)
+	result, err := stream.Query("count", 0, 199, params)
+	assert.NoError(t, err)
+	assert.Equal(t, 200.0, result.value.Count.Value)
+
+	// The tag survives a round trip through the metadata.
+	buf, err := mds.GetStream(0)
+	assert.NoError(t, err)
+	newStream, err := DeserializeStream("", buf)
+	assert.NoError(t, err)
+	assert.Equal(t, stream.operatorsSince, newStream.operatorsSince)
+	assert.True(t, stream.manager.operators.Equals(newStream.manager.operators))
+
+	// Unknown, duplicate and missing operators are rejected.
+	err = stream.Migrate(NewStreamMigration().AddOperators("median"), mds)
+	assert.Error(t, err)
+	err = stream.Migrate(NewStreamMigration().AddOperators("count"), mds)
+	assert.Error(t, err)
+	err = stream.Migrate(NewStreamMigration().RemoveOperators("max"), mds)
+	assert.Error(t, err)
+	err = stream.Migrate(NewStreamMigration().RemoveOperators("count", "sum"), mds)
+	assert.Error(t, err)
+
+	// Removing the operator drops its tag.
+	err = stream.Migrate(NewStreamMigration().RemoveOperators("sum"), mds)
+	assert.NoError(t, err)
+	assert.Empty(t, stream.operatorsSince)
+	_, err = stream.Query("sum", 0, 199, params)
+	assert.Error(t, err)
+}
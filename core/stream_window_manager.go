@@ -1,7 +1,6 @@
 package core
 
 import (
-	"math"
 	"summarydb/storage"
 	"summarydb/tree"
 )
@@ -11,7 +10,10 @@ type StreamWindowManager struct {
 	summaryIndex  *storage.QueryIndex
 	landmarkIndex *storage.QueryIndex
 	operators     *OpSet
-	backingStore  *BackingStore
+	// Operators summarizing each field of the records, nil for streams of
+	// values.
+	fields       []*OpSet
+	backingStore *BackingStore
 }
 
 // TODO: Make this return []*DataTable
@@ -29,9 +31,21 @@ func NewStreamWindowManager(id int64, operatorNames []string) *StreamWindowManag
 		summaryIndex:  storage.NewQueryIndex(),
 		landmarkIndex: storage.NewQueryIndex(),
 		operators:     NewOpSet(operatorNames),
+		fields:        nil,
 	}
 }
 
+func (manager *StreamWindowManager) SetFields(fields []Field) {
+	manager.fields = make([]*OpSet, len(fields))
+	for i, field := range fields {
+		manager.fields[i] = NewOpSet(field.Operators)
+	}
+}
+
+func (manager *StreamWindowManager) NumFields() int {
+	return len(manager.fields)
+}
+
 func (manager *StreamWindowManager) SetBackingStore(store *BackingStore) {
 	manager.backingStore = store
 }
@@ -59,18 +73,45 @@ func (manager *StreamWindowManager) MergeSummaryWindows(summaryWindows []*Summar
 		firstWindow.CountStart,
 		lastWindow.CountEnd)
 
+	for _, window := range summaryWindows {
+		mergedWindow.Folded = mergedWindow.Folded || window.Folded
+	}
+
 	opData := GetDataFromWindows(summaryWindows)
 	mergedWindow.Data = manager.operators.Merge(opData)
+	if manager.fields != nil {
+		mergedWindow.Fields = make([]*DataTable, len(manager.fields))
+		for i, opSet := range manager.fields {
+			fieldData := make([]DataTable, 0, len(summaryWindows))
+			for _, window := range summaryWindows {
+				if window.Fields != nil {
+					fieldData = append(fieldData, *window.Fields[i])
+				}
+			}
+			mergedWindow.Fields[i] = opSet.Merge(fieldData)
+		}
+	}
 	return mergedWindow
 }
 
 func (manager *StreamWindowManager) InsertIntoSummaryWindow(window *SummaryWindow, ts int64, value float64) {
-	if value == -math.MaxFloat64 {
-		return
-	}
 	manager.operators.Insert(window.Data, value, ts)
 }
 
+// InsertRecordIntoSummaryWindow summarizes each field of the record in its
+// own data table.
+func (manager *StreamWindowManager) InsertRecordIntoSummaryWindow(window *SummaryWindow, ts int64, record []float64) {
+	if window.Fields == nil {
+		window.Fields = make([]*DataTable, len(manager.fields))
+		for i := range window.Fields {
+			window.Fields[i] = NewDataTable()
+		}
+	}
+	for i, opSet := range manager.fields {
+		opSet.Insert(window.Fields[i], record[i], ts)
+	}
+}
+
 func (manager *StreamWindowManager) GetSummaryWindow(swid int64) (*SummaryWindow, error) {
 	return manager.backingStore.Get(manager.id, swid)
 }
@@ -151,6 +192,14 @@ func (manager *StreamWindowManager) GetMergerIndex() (*MergerIndex, error) {
 	return manager.backingStore.GetMergerIndex(manager.id)
 }
 
+func (manager *StreamWindowManager) PutHeap(heap *tree.MinHeap) error {
+	return manager.backingStore.PutHeap(manager.id, heap)
+}
+
+func (manager *StreamWindowManager) PutMergerIndex(index *MergerIndex) error {
+	return manager.backingStore.PutMergerIndex(manager.id, index)
+}
+
 func (manager *StreamWindowManager) PutCountAndTime(
 	compType storage.CompType,
 	count int64,
@@ -188,3 +237,28 @@ func (manager *StreamWindowManager) MergerBrew(
 		manager.id, count, timestamp,
 		pendingMerges, heap, index)
 }
+
+func (manager *StreamWindowManager) LandmarkBrew(
+	landmark *LandmarkWindow, windows []*SummaryWindow) error {
+	manager.landmarkIndex.Add(landmark.Id())
+	return manager.backingStore.LandmarkBrew(manager.id, landmark, windows)
+}
+
+func (manager *StreamWindowManager) LandmarkFoldBrew(
+	landmarkID int64, windows []*SummaryWindow) error {
+	manager.landmarkIndex.Remove(landmarkID)
+	return manager.backingStore.LandmarkFoldBrew(manager.id, landmarkID, windows)
+}
+
+func (manager *StreamWindowManager) TruncateBrew(
+	windowIDs []int64, landmarkIDs []int64,
+	heap *tree.MinHeap, index *MergerIndex) error {
+	for _, swid := range windowIDs {
+		manager.summaryIndex.Remove(swid)
+	}
+	for _, lwid := range landmarkIDs {
+		manager.landmarkIndex.Remove(lwid)
+	}
+	return manager.backingStore.TruncateBrew(
+		manager.id, windowIDs, landmarkIDs, heap, index)
+}
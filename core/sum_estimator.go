@@ -1,6 +1,9 @@
 package core
 
-import "summarydb/stats"
+import (
+	"math"
+	"summarydb/stats"
+)
 
 type WindowInfo struct {
 	Start   int64
@@ -8,6 +11,7 @@ type WindowInfo struct {
 	Sum     float64
 	Overlap int64
 	Length  int64
+	Count   int64
 }
 
 func NewWindowInfo() *WindowInfo {
@@ -17,6 +21,7 @@ func NewWindowInfo() *WindowInfo {
 		Sum:     0,
 		Overlap: 1,
 		Length:  1,
+		Count:   0,
 	}
 }
 
@@ -24,6 +29,7 @@ func (wi *WindowInfo) SetValues(window *SummaryWindow, value float64) {
 	wi.Start = window.TimeStart
 	wi.End = window.TimeEnd
 	wi.Sum = value
+	wi.Count = window.Size()
 }
 
 func (wi *WindowInfo) SetLengthAndOverlap(t0 int64, t1 int64) {
@@ -31,11 +37,14 @@ func (wi *WindowInfo) SetLengthAndOverlap(t0 int64, t1 int64) {
 	wi.Length = stats.WindowLength(wi.Start, wi.End)
 }
 
-func GetSumStats(t0, t1 int64,
+// Splits the windows overlapping [t0, t1] into the exact landmark
+// contribution, the partially overlapping first and last windows, and the
+// fully covered windows in between.
+func getSumWindowInfos(t0, t1 int64,
 	summaryWindows []*SummaryWindow,
 	landmarkWindows []*LandmarkWindow,
 	getSummaryData func(*DataTable) float64,
-	getLandmarkData func(float64) float64) (*stats.Bounds, *stats.Stats) {
+	getLandmarkData func(float64) float64) (landmark, first, middle, last *WindowInfo) {
 	firstWindow := NewWindowInfo()
 	lastWindow := NewWindowInfo()
 	middleWindow := NewWindowInfo()
@@ -86,6 +95,17 @@ func GetSumStats(t0, t1 int64,
 		}
 	}
 
+	return landmarkWindow, firstWindow, middleWindow, lastWindow
+}
+
+func GetSumStats(t0, t1 int64,
+	summaryWindows []*SummaryWindow,
+	landmarkWindows []*LandmarkWindow,
+	getSummaryData func(*DataTable) float64,
+	getLandmarkData func(float64) float64) (*stats.Bounds, *stats.Stats) {
+	landmarkWindow, firstWindow, middleWindow, lastWindow := getSumWindowInfos(
+		t0, t1, summaryWindows, landmarkWindows, getSummaryData, getLandmarkData)
+
 	bounds := &stats.Bounds{
 		Lower: 0,
 		Upper: 0,
@@ -113,6 +133,17 @@ func UpdateEstimate(bounds *stats.Bounds, stats *stats.Stats, info *WindowInfo)
 	if info.Overlap > 0 {
 		ratio := float64(info.Overlap) / float64(info.Length)
 		stats.Mean += info.Sum * ratio
-		stats.Var += info.Sum * ratio * (1 - ratio)
+		variance := info.Sum * ratio * (1 - ratio)
+		stats.Var += variance
+		if info.Overlap < info.Length {
+			// The variance of a partial window is estimated from its Count
+			// elements, so it carries Count - 1 degrees of freedom.
+			if info.Count > 1 {
+				stats.VarSquaresOverDF += variance * variance / float64(info.Count-1)
+			} else if variance > 0 {
+				stats.VarSquaresOverDF += math.Inf(1)
+			}
+			stats.RangeSquares += info.Sum * info.Sum
+		}
 	}
 }
@@ -2,6 +2,8 @@ package core
 
 import (
 	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"summarydb/stats"
 	"testing"
 )
 
@@ -63,3 +65,69 @@ func TestGetSumStats_Landmarks(t *testing.T) {
 	assert.Equal(t, stats.Mean, 6.9)
 	assert.Equal(t, stats.Var, 0.49)
 }
+
+// Each synthetic window holds a few elements at uniformly random timestamps,
+// so the number of elements in a partially overlapping window is binomial,
+// matching the model behind GetSumStats.
+func ciCoverage(method stats.CIMethod, confidenceLevel float64) float64 {
+	rng := rand.New(rand.NewSource(42))
+	const numTrials = 4000
+
+	covered := 0
+	for trial := 0; trial < numTrials; trial++ {
+		summaryWindows := make([]*SummaryWindow, 0)
+		timestamps := make([]int64, 0)
+		timeStart, countStart := int64(0), int64(0)
+		for i := 0; i < 8; i++ {
+			length := int64(10 + rng.Intn(40))
+			count := int64(2 + rng.Intn(4))
+			for j := int64(0); j < count; j++ {
+				timestamps = append(timestamps, timeStart+rng.Int63n(length))
+			}
+			window := NewSummaryWindow(timeStart, timeStart+length-1,
+				countStart, countStart+count-1)
+			window.Data.Count.Value = float64(count)
+			summaryWindows = append(summaryWindows, window)
+			timeStart += length
+			countStart += count
+		}
+
+		t0 := rng.Int63n(timeStart)
+		t1 := t0 + rng.Int63n(timeStart-t0)
+		truth := 0.0
+		for _, ts := range timestamps {
+			if t0 <= ts && ts <= t1 {
+				truth += 1
+			}
+		}
+
+		var windows []*SummaryWindow
+		for _, window := range summaryWindows {
+			if window.TimeEnd >= t0 && window.TimeStart <= t1 {
+				windows = append(windows, window)
+			}
+		}
+		bounds, meanvar := GetSumStats(t0, t1, windows, []*LandmarkWindow{}, getValue, identity)
+		ci := stats.ConvertStatsBoundsToCIWithMethod(bounds, meanvar, 1, confidenceLevel, method)
+		if ci.LowerCI-1e-9 <= truth && truth <= ci.UpperCI+1e-9 {
+			covered++
+		}
+	}
+	return float64(covered) / numTrials
+}
+
+func TestConvertStatsBoundsToCI_Coverage(t *testing.T) {
+	for _, confidenceLevel := range []float64{0.8, 0.95} {
+		normal := ciCoverage(stats.NormalCI, confidenceLevel)
+		studentT := ciCoverage(stats.StudentTCI, confidenceLevel)
+		chebyshev := ciCoverage(stats.ChebyshevCI, confidenceLevel)
+		hoeffding := ciCoverage(stats.HoeffdingCI, confidenceLevel)
+		t.Logf("confidence %v: normal %v, student-t %v, chebyshev %v, hoeffding %v",
+			confidenceLevel, normal, studentT, chebyshev, hoeffding)
+
+		assert.GreaterOrEqual(t, studentT, normal)
+		assert.GreaterOrEqual(t, studentT, confidenceLevel)
+		assert.GreaterOrEqual(t, chebyshev, confidenceLevel)
+		assert.GreaterOrEqual(t, hoeffding, confidenceLevel)
+	}
+}
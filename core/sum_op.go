@@ -29,6 +29,14 @@ func (op *SumOp) Merge(retData *DataTable, values []DataTable) {
 	}
 }
 
+func (op *SumOp) GetSummaryData(table *DataTable) float64 {
+	return table.Sum.Value
+}
+
+func (op *SumOp) GetLandmarkData(value float64) float64 {
+	return value
+}
+
 func (op *SumOp) EmptyQuery() *AggResult {
 	return &AggResult{
 		value: NewDataTable(),
@@ -45,18 +53,15 @@ func (op *SumOp) Query(windows []*SummaryWindow,
 	bounds, meanvar := GetSumStats(t0, t1,
 		windows,
 		landmarkWindows,
-		func(table *DataTable) float64 {
-			return table.Sum.Value
-		},
-		func(value float64) float64 {
-			return value
-		})
+		op.GetSummaryData,
+		op.GetLandmarkData)
 
-	ci := stats.ConvertStatsBoundsToCI(
+	ci := stats.ConvertStatsBoundsToCIWithMethod(
 		bounds,
 		meanvar,
 		params.SDMultiplier,
-		params.ConfidenceLevel)
+		params.ConfidenceLevel,
+		params.CIMethod)
 
 	aggData := NewDataTable()
 	aggData.Sum.Value = ci.Mean
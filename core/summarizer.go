@@ -99,7 +99,13 @@ func (s *Summarizer) Run(
 					for i := iStart; i <= iEnd; i += 1 {
 						// ingestBuffer[iStart:iEnd] are guaranteed to exist.
 						timestamp, value, _ := ingestBuffer.Get(i)
-						s.streamWindowManager.InsertIntoSummaryWindow(window, timestamp, value)
+						if ingestBuffer.IsLandmark(i) {
+							continue
+						} else if record := ingestBuffer.GetRecord(i); record != nil {
+							s.streamWindowManager.InsertRecordIntoSummaryWindow(window, timestamp, record)
+						} else {
+							s.streamWindowManager.InsertIntoSummaryWindow(window, timestamp, value)
+						}
 					}
 
 					writerQueue <- window
@@ -16,6 +16,12 @@ type SummaryWindow struct {
 	CountStart int64
 	CountEnd   int64
 	Data       *DataTable
+	// One per field of the stream's records, nil for streams of values.
+	Fields []*DataTable
+	// Set once late or landmark values are folded in. They are not logged
+	// to the WAL as elements of the window, which can't be summarized again
+	// from it.
+	Folded bool
 }
 
 var shutdownSummaryWindow *SummaryWindow = nil
@@ -48,6 +54,8 @@ func NewSummaryWindow(timeStart, timeEnd, countStart, countEnd int64) *SummaryWi
 		CountStart: countStart,
 		CountEnd:   countEnd,
 		Data:       NewDataTable(),
+		Fields:     nil,
+		Folded:     false,
 	}
 	return &window
 }
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"errors"
+	"math"
+)
+
+// TruncateBefore drops the summary and landmark windows ending before
+// timestamp, along with the WAL entries of their values. A summary window
+// spanning timestamp is kept whole, and so is the landmark being appended
+// to, which can't start before timestamp.
+func (stream *Stream) TruncateBefore(timestamp int64) error {
+	stream.appendMutex.Lock()
+	defer stream.appendMutex.Unlock()
+	return stream.truncateBefore(timestamp)
+}
+
+func (stream *Stream) truncateBefore(timestamp int64) error {
+	if !stream.backendSet {
+		return errors.New("backend not set")
+	}
+	if stream.readOnly {
+		return ErrReadOnly
+	}
+	if stream.landmarkWindow != nil && stream.landmarkWindow.TimeStart < timestamp {
+		return errors.New("cannot truncate an open landmark")
+	}
+	if stream.pipeline.wal != nil {
+		// Fails on a closed stream, before anything is deleted.
+		_, err := stream.pipeline.wal.FirstIndex()
+		if err != nil {
+			return err
+		}
+	}
+	if stream.running {
+		// Brings the windows, and the merges, up to date.
+		err := stream.flush()
+		if err != nil {
+			return err
+		}
+	}
+
+	windowIDs := make([]int64, 0)
+	var firstKept *SummaryWindow = nil
+	for _, swid := range stream.manager.summaryIndex.GetOverlappingWindowIDs(math.MinInt64, timestamp) {
+		summaryWindow, err := stream.manager.GetSummaryWindow(swid)
+		if err != nil {
+			return err
+		}
+		if summaryWindow.TimeEnd >= timestamp {
+			firstKept = summaryWindow
+			break
+		}
+		windowIDs = append(windowIDs, swid)
+	}
+	landmarkIDs := make([]int64, 0)
+	landmarkWindows, err := stream.manager.GetLandmarkWindowInRange(math.MinInt64, timestamp)
+	if err != nil {
+		return err
+	}
+	for _, landmarkWindow := range landmarkWindows {
+		if landmarkWindow.TimeEnd < timestamp {
+			landmarkIDs = append(landmarkIDs, landmarkWindow.Id())
+		}
+	}
+	if len(windowIDs) == 0 && len(landmarkIDs) == 0 {
+		return nil
+	}
+
+	err = stream.pipeline.merger.Truncate(windowIDs, landmarkIDs)
+	if err != nil {
+		return err
+	}
+	stream.landmarkExpiry = math.MinInt64
+	stream.firstWindowStart = math.MinInt64
+	return stream.truncateWAL(firstKept)
+}
+
+// Drops the WAL entries before the first kept window. The latest entry is
+// always kept, it tells the count and time of the stream when reopened.
+func (stream *Stream) truncateWAL(firstKept *SummaryWindow) error {
+	wal := stream.pipeline.wal
+	if wal == nil {
+		return nil
+	}
+	first, err := wal.FirstIndex()
+	if err != nil {
+		return err
+	}
+	last, err := wal.LastIndex()
+	if err != nil {
+		return err
+	}
+	index := last
+	if firstKept != nil && uint64(firstKept.CountStart+1) < last {
+		// Summary windows number their elements from 0, the WAL from 1.
+		index = uint64(firstKept.CountStart + 1)
+	}
+	if index <= first {
+		return nil
+	}
+	return wal.TruncateFront(index)
+}
@@ -60,8 +60,9 @@ func (w *Writer) Process(summaryWindow *SummaryWindow) (*MergeEvent, error) {
 		return nil, err
 	}
 	mergerEvent := &MergeEvent{
-		Id:   summaryWindow.Id(),
-		Size: size,
+		Id:      summaryWindow.Id(),
+		Size:    size,
+		TimeEnd: summaryWindow.TimeEnd,
 	}
 	return mergerEvent, nil
 }
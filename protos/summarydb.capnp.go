@@ -166,12 +166,12 @@ type ProtoSummaryWindow struct{ capnp.Struct }
 const ProtoSummaryWindow_TypeID = 0xd03e3591895dbdfb
 
 func NewProtoSummaryWindow(s *capnp.Segment) (ProtoSummaryWindow, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 32, PointerCount: 1})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 40, PointerCount: 2})
 	return ProtoSummaryWindow{st}, err
 }
 
 func NewRootProtoSummaryWindow(s *capnp.Segment) (ProtoSummaryWindow, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 32, PointerCount: 1})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 40, PointerCount: 2})
 	return ProtoSummaryWindow{st}, err
 }
 
@@ -241,12 +241,44 @@ func (s ProtoSummaryWindow) NewOpData() (DataTable, error) {
 	return ss, err
 }
 
+func (s ProtoSummaryWindow) Fields() (DataTable_List, error) {
+	p, err := s.Struct.Ptr(1)
+	return DataTable_List{List: p.List()}, err
+}
+
+func (s ProtoSummaryWindow) HasFields() bool {
+	return s.Struct.HasPtr(1)
+}
+
+func (s ProtoSummaryWindow) SetFields(v DataTable_List) error {
+	return s.Struct.SetPtr(1, v.List.ToPtr())
+}
+
+// NewFields sets the fields field to a newly
+// allocated DataTable_List, preferring placement in s's segment.
+func (s ProtoSummaryWindow) NewFields(n int32) (DataTable_List, error) {
+	l, err := NewDataTable_List(s.Struct.Segment(), n)
+	if err != nil {
+		return DataTable_List{}, err
+	}
+	err = s.Struct.SetPtr(1, l.List.ToPtr())
+	return l, err
+}
+
+func (s ProtoSummaryWindow) Folded() bool {
+	return s.Struct.Bit(256)
+}
+
+func (s ProtoSummaryWindow) SetFolded(v bool) {
+	s.Struct.SetBit(256, v)
+}
+
 // ProtoSummaryWindow_List is a list of ProtoSummaryWindow.
 type ProtoSummaryWindow_List struct{ capnp.List }
 
 // NewProtoSummaryWindow creates a new list of ProtoSummaryWindow.
 func NewProtoSummaryWindow_List(s *capnp.Segment, sz int32) (ProtoSummaryWindow_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 32, PointerCount: 1}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 40, PointerCount: 2}, sz)
 	return ProtoSummaryWindow_List{l}, err
 }
 
@@ -281,12 +313,12 @@ type ProtoLandmarkWindow struct{ capnp.Struct }
 const ProtoLandmarkWindow_TypeID = 0xcdf64d2c4abfe20f
 
 func NewProtoLandmarkWindow(s *capnp.Segment) (ProtoLandmarkWindow, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 2})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 40, PointerCount: 2})
 	return ProtoLandmarkWindow{st}, err
 }
 
 func NewRootProtoLandmarkWindow(s *capnp.Segment) (ProtoLandmarkWindow, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 2})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 40, PointerCount: 2})
 	return ProtoLandmarkWindow{st}, err
 }
 
@@ -364,12 +396,36 @@ func (s ProtoLandmarkWindow) NewValues(n int32) (capnp.Float64List, error) {
 	return l, err
 }
 
+func (s ProtoLandmarkWindow) Promoted() bool {
+	return s.Struct.Bit(128)
+}
+
+func (s ProtoLandmarkWindow) SetPromoted(v bool) {
+	s.Struct.SetBit(128, v)
+}
+
+func (s ProtoLandmarkWindow) Cs() int64 {
+	return int64(s.Struct.Uint64(24))
+}
+
+func (s ProtoLandmarkWindow) SetCs(v int64) {
+	s.Struct.SetUint64(24, uint64(v))
+}
+
+func (s ProtoLandmarkWindow) Ce() int64 {
+	return int64(s.Struct.Uint64(32))
+}
+
+func (s ProtoLandmarkWindow) SetCe(v int64) {
+	s.Struct.SetUint64(32, uint64(v))
+}
+
 // ProtoLandmarkWindow_List is a list of ProtoLandmarkWindow.
 type ProtoLandmarkWindow_List struct{ capnp.List }
 
 // NewProtoLandmarkWindow creates a new list of ProtoLandmarkWindow.
 func NewProtoLandmarkWindow_List(s *capnp.Segment, sz int32) (ProtoLandmarkWindow_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 2}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 40, PointerCount: 2}, sz)
 	return ProtoLandmarkWindow_List{l}, err
 }
 
@@ -486,243 +542,2030 @@ func (s PowerWindow) SetP(v int64) {
 	s.Struct.SetUint64(0, uint64(v))
 }
 
-func (s PowerWindow) Q() int64 {
-	return int64(s.Struct.Uint64(8))
+func (s PowerWindow) Q() int64 {
+	return int64(s.Struct.Uint64(8))
+}
+
+func (s PowerWindow) SetQ(v int64) {
+	s.Struct.SetUint64(8, uint64(v))
+}
+
+func (s PowerWindow) R() int64 {
+	return int64(s.Struct.Uint64(16))
+}
+
+func (s PowerWindow) SetR(v int64) {
+	s.Struct.SetUint64(16, uint64(v))
+}
+
+func (s PowerWindow) S() int64 {
+	return int64(s.Struct.Uint64(24))
+}
+
+func (s PowerWindow) SetS(v int64) {
+	s.Struct.SetUint64(24, uint64(v))
+}
+
+// PowerWindow_List is a list of PowerWindow.
+type PowerWindow_List struct{ capnp.List }
+
+// NewPowerWindow creates a new list of PowerWindow.
+func NewPowerWindow_List(s *capnp.Segment, sz int32) (PowerWindow_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 32, PointerCount: 0}, sz)
+	return PowerWindow_List{l}, err
+}
+
+func (s PowerWindow_List) At(i int) PowerWindow { return PowerWindow{s.List.Struct(i)} }
+
+func (s PowerWindow_List) Set(i int, v PowerWindow) error { return s.List.SetStruct(i, v.Struct) }
+
+func (s PowerWindow_List) String() string {
+	str, _ := text.MarshalList(0xc06345e07edc6c60, s.List)
+	return str
+}
+
+// PowerWindow_Future is a wrapper for a PowerWindow promised by a client call.
+type PowerWindow_Future struct{ *capnp.Future }
+
+func (p PowerWindow_Future) Struct() (PowerWindow, error) {
+	s, err := p.Future.Struct()
+	return PowerWindow{s}, err
+}
+
+type UniformWindow struct{ capnp.Struct }
+
+// UniformWindow_TypeID is the unique identifier for the type UniformWindow.
+const UniformWindow_TypeID = 0x8ff9e1b03450d14e
+
+func NewUniformWindow(s *capnp.Segment) (UniformWindow, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 0})
+	return UniformWindow{st}, err
+}
+
+func NewRootUniformWindow(s *capnp.Segment) (UniformWindow, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 0})
+	return UniformWindow{st}, err
+}
+
+func ReadRootUniformWindow(msg *capnp.Message) (UniformWindow, error) {
+	root, err := msg.Root()
+	return UniformWindow{root.Struct()}, err
+}
+
+func (s UniformWindow) String() string {
+	str, _ := text.Marshal(0x8ff9e1b03450d14e, s.Struct)
+	return str
+}
+
+func (s UniformWindow) Length() int64 {
+	return int64(s.Struct.Uint64(0))
+}
+
+func (s UniformWindow) SetLength(v int64) {
+	s.Struct.SetUint64(0, uint64(v))
+}
+
+// UniformWindow_List is a list of UniformWindow.
+type UniformWindow_List struct{ capnp.List }
+
+// NewUniformWindow creates a new list of UniformWindow.
+func NewUniformWindow_List(s *capnp.Segment, sz int32) (UniformWindow_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 0}, sz)
+	return UniformWindow_List{l}, err
+}
+
+func (s UniformWindow_List) At(i int) UniformWindow { return UniformWindow{s.List.Struct(i)} }
+
+func (s UniformWindow_List) Set(i int, v UniformWindow) error { return s.List.SetStruct(i, v.Struct) }
+
+func (s UniformWindow_List) String() string {
+	str, _ := text.MarshalList(0x8ff9e1b03450d14e, s.List)
+	return str
+}
+
+// UniformWindow_Future is a wrapper for a UniformWindow promised by a client call.
+type UniformWindow_Future struct{ *capnp.Future }
+
+func (p UniformWindow_Future) Struct() (UniformWindow, error) {
+	s, err := p.Future.Struct()
+	return UniformWindow{s}, err
+}
+
+type PolynomialWindow struct{ capnp.Struct }
+
+// PolynomialWindow_TypeID is the unique identifier for the type PolynomialWindow.
+const PolynomialWindow_TypeID = 0xf264bc0a8b38d591
+
+func NewPolynomialWindow(s *capnp.Segment) (PolynomialWindow, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return PolynomialWindow{st}, err
+}
+
+func NewRootPolynomialWindow(s *capnp.Segment) (PolynomialWindow, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return PolynomialWindow{st}, err
+}
+
+func ReadRootPolynomialWindow(msg *capnp.Message) (PolynomialWindow, error) {
+	root, err := msg.Root()
+	return PolynomialWindow{root.Struct()}, err
+}
+
+func (s PolynomialWindow) String() string {
+	str, _ := text.Marshal(0xf264bc0a8b38d591, s.Struct)
+	return str
+}
+
+func (s PolynomialWindow) Coefficients() (capnp.Int64List, error) {
+	p, err := s.Struct.Ptr(0)
+	return capnp.Int64List{List: p.List()}, err
+}
+
+func (s PolynomialWindow) HasCoefficients() bool {
+	return s.Struct.HasPtr(0)
+}
+
+func (s PolynomialWindow) SetCoefficients(v capnp.Int64List) error {
+	return s.Struct.SetPtr(0, v.List.ToPtr())
+}
+
+// NewCoefficients sets the coefficients field to a newly
+// allocated capnp.Int64List, preferring placement in s's segment.
+func (s PolynomialWindow) NewCoefficients(n int32) (capnp.Int64List, error) {
+	l, err := capnp.NewInt64List(s.Struct.Segment(), n)
+	if err != nil {
+		return capnp.Int64List{}, err
+	}
+	err = s.Struct.SetPtr(0, l.List.ToPtr())
+	return l, err
+}
+
+// PolynomialWindow_List is a list of PolynomialWindow.
+type PolynomialWindow_List struct{ capnp.List }
+
+// NewPolynomialWindow creates a new list of PolynomialWindow.
+func NewPolynomialWindow_List(s *capnp.Segment, sz int32) (PolynomialWindow_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return PolynomialWindow_List{l}, err
+}
+
+func (s PolynomialWindow_List) At(i int) PolynomialWindow { return PolynomialWindow{s.List.Struct(i)} }
+
+func (s PolynomialWindow_List) Set(i int, v PolynomialWindow) error {
+	return s.List.SetStruct(i, v.Struct)
+}
+
+func (s PolynomialWindow_List) String() string {
+	str, _ := text.MarshalList(0xf264bc0a8b38d591, s.List)
+	return str
+}
+
+// PolynomialWindow_Future is a wrapper for a PolynomialWindow promised by a client call.
+type PolynomialWindow_Future struct{ *capnp.Future }
+
+func (p PolynomialWindow_Future) Struct() (PolynomialWindow, error) {
+	s, err := p.Future.Struct()
+	return PolynomialWindow{s}, err
+}
+
+type WindowTier struct{ capnp.Struct }
+
+// WindowTier_TypeID is the unique identifier for the type WindowTier.
+const WindowTier_TypeID = 0x8f2db9b73d1480d8
+
+func NewWindowTier(s *capnp.Segment) (WindowTier, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 0})
+	return WindowTier{st}, err
+}
+
+func NewRootWindowTier(s *capnp.Segment) (WindowTier, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 0})
+	return WindowTier{st}, err
+}
+
+func ReadRootWindowTier(msg *capnp.Message) (WindowTier, error) {
+	root, err := msg.Root()
+	return WindowTier{root.Struct()}, err
+}
+
+func (s WindowTier) String() string {
+	str, _ := text.Marshal(0x8f2db9b73d1480d8, s.Struct)
+	return str
+}
+
+func (s WindowTier) Count() int64 {
+	return int64(s.Struct.Uint64(0))
+}
+
+func (s WindowTier) SetCount(v int64) {
+	s.Struct.SetUint64(0, uint64(v))
+}
+
+func (s WindowTier) Length() int64 {
+	return int64(s.Struct.Uint64(8))
+}
+
+func (s WindowTier) SetLength(v int64) {
+	s.Struct.SetUint64(8, uint64(v))
+}
+
+// WindowTier_List is a list of WindowTier.
+type WindowTier_List struct{ capnp.List }
+
+// NewWindowTier creates a new list of WindowTier.
+func NewWindowTier_List(s *capnp.Segment, sz int32) (WindowTier_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 0}, sz)
+	return WindowTier_List{l}, err
+}
+
+func (s WindowTier_List) At(i int) WindowTier { return WindowTier{s.List.Struct(i)} }
+
+func (s WindowTier_List) Set(i int, v WindowTier) error { return s.List.SetStruct(i, v.Struct) }
+
+func (s WindowTier_List) String() string {
+	str, _ := text.MarshalList(0x8f2db9b73d1480d8, s.List)
+	return str
+}
+
+// WindowTier_Future is a wrapper for a WindowTier promised by a client call.
+type WindowTier_Future struct{ *capnp.Future }
+
+func (p WindowTier_Future) Struct() (WindowTier, error) {
+	s, err := p.Future.Struct()
+	return WindowTier{s}, err
+}
+
+type TableWindow struct{ capnp.Struct }
+
+// TableWindow_TypeID is the unique identifier for the type TableWindow.
+const TableWindow_TypeID = 0xe997293d86d4ca21
+
+func NewTableWindow(s *capnp.Segment) (TableWindow, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return TableWindow{st}, err
+}
+
+func NewRootTableWindow(s *capnp.Segment) (TableWindow, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	return TableWindow{st}, err
+}
+
+func ReadRootTableWindow(msg *capnp.Message) (TableWindow, error) {
+	root, err := msg.Root()
+	return TableWindow{root.Struct()}, err
+}
+
+func (s TableWindow) String() string {
+	str, _ := text.Marshal(0xe997293d86d4ca21, s.Struct)
+	return str
+}
+
+func (s TableWindow) Tiers() (WindowTier_List, error) {
+	p, err := s.Struct.Ptr(0)
+	return WindowTier_List{List: p.List()}, err
+}
+
+func (s TableWindow) HasTiers() bool {
+	return s.Struct.HasPtr(0)
+}
+
+func (s TableWindow) SetTiers(v WindowTier_List) error {
+	return s.Struct.SetPtr(0, v.List.ToPtr())
+}
+
+// NewTiers sets the tiers field to a newly
+// allocated WindowTier_List, preferring placement in s's segment.
+func (s TableWindow) NewTiers(n int32) (WindowTier_List, error) {
+	l, err := NewWindowTier_List(s.Struct.Segment(), n)
+	if err != nil {
+		return WindowTier_List{}, err
+	}
+	err = s.Struct.SetPtr(0, l.List.ToPtr())
+	return l, err
+}
+
+// TableWindow_List is a list of TableWindow.
+type TableWindow_List struct{ capnp.List }
+
+// NewTableWindow creates a new list of TableWindow.
+func NewTableWindow_List(s *capnp.Segment, sz int32) (TableWindow_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	return TableWindow_List{l}, err
+}
+
+func (s TableWindow_List) At(i int) TableWindow { return TableWindow{s.List.Struct(i)} }
+
+func (s TableWindow_List) Set(i int, v TableWindow) error { return s.List.SetStruct(i, v.Struct) }
+
+func (s TableWindow_List) String() string {
+	str, _ := text.MarshalList(0xe997293d86d4ca21, s.List)
+	return str
+}
+
+// TableWindow_Future is a wrapper for a TableWindow promised by a client call.
+type TableWindow_Future struct{ *capnp.Future }
+
+func (p TableWindow_Future) Struct() (TableWindow, error) {
+	s, err := p.Future.Struct()
+	return TableWindow{s}, err
+}
+
+type Welford struct{ capnp.Struct }
+
+// Welford_TypeID is the unique identifier for the type Welford.
+const Welford_TypeID = 0xb7c075e7aacf15a0
+
+func NewWelford(s *capnp.Segment) (Welford, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 0})
+	return Welford{st}, err
+}
+
+func NewRootWelford(s *capnp.Segment) (Welford, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 0})
+	return Welford{st}, err
+}
+
+func ReadRootWelford(msg *capnp.Message) (Welford, error) {
+	root, err := msg.Root()
+	return Welford{root.Struct()}, err
+}
+
+func (s Welford) String() string {
+	str, _ := text.Marshal(0xb7c075e7aacf15a0, s.Struct)
+	return str
+}
+
+func (s Welford) Count() uint64 {
+	return s.Struct.Uint64(0)
+}
+
+func (s Welford) SetCount(v uint64) {
+	s.Struct.SetUint64(0, v)
+}
+
+func (s Welford) Mean() float64 {
+	return math.Float64frombits(s.Struct.Uint64(8))
+}
+
+func (s Welford) SetMean(v float64) {
+	s.Struct.SetUint64(8, math.Float64bits(v))
+}
+
+func (s Welford) M2() float64 {
+	return math.Float64frombits(s.Struct.Uint64(16))
+}
+
+func (s Welford) SetM2(v float64) {
+	s.Struct.SetUint64(16, math.Float64bits(v))
+}
+
+// Welford_List is a list of Welford.
+type Welford_List struct{ capnp.List }
+
+// NewWelford creates a new list of Welford.
+func NewWelford_List(s *capnp.Segment, sz int32) (Welford_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 24, PointerCount: 0}, sz)
+	return Welford_List{l}, err
+}
+
+func (s Welford_List) At(i int) Welford { return Welford{s.List.Struct(i)} }
+
+func (s Welford_List) Set(i int, v Welford) error { return s.List.SetStruct(i, v.Struct) }
+
+func (s Welford_List) String() string {
+	str, _ := text.MarshalList(0xb7c075e7aacf15a0, s.List)
+	return str
+}
+
+// Welford_Future is a wrapper for a Welford promised by a client call.
+type Welford_Future struct{ *capnp.Future }
+
+func (p Welford_Future) Struct() (Welford, error) {
+	s, err := p.Future.Struct()
+	return Welford{s}, err
+}
+
+type StreamStatistics struct{ capnp.Struct }
+
+// StreamStatistics_TypeID is the unique identifier for the type StreamStatistics.
+const StreamStatistics_TypeID = 0xa6cd454ce816484c
+
+func NewStreamStatistics(s *capnp.Segment) (StreamStatistics, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 48, PointerCount: 3})
+	return StreamStatistics{st}, err
+}
+
+func NewRootStreamStatistics(s *capnp.Segment) (StreamStatistics, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 48, PointerCount: 3})
+	return StreamStatistics{st}, err
+}
+
+func ReadRootStreamStatistics(msg *capnp.Message) (StreamStatistics, error) {
+	root, err := msg.Root()
+	return StreamStatistics{root.Struct()}, err
+}
+
+func (s StreamStatistics) String() string {
+	str, _ := text.Marshal(0xa6cd454ce816484c, s.Struct)
+	return str
+}
+
+func (s StreamStatistics) FirstArrivalTimestamp() int64 {
+	return int64(s.Struct.Uint64(0))
+}
+
+func (s StreamStatistics) SetFirstArrivalTimestamp(v int64) {
+	s.Struct.SetUint64(0, uint64(v))
+}
+
+func (s StreamStatistics) LastArrivalTimestamp() int64 {
+	return int64(s.Struct.Uint64(8))
+}
+
+func (s StreamStatistics) SetLastArrivalTimestamp(v int64) {
+	s.Struct.SetUint64(8, uint64(v))
+}
+
+func (s StreamStatistics) NumValues() uint64 {
+	return s.Struct.Uint64(16)
+}
+
+func (s StreamStatistics) SetNumValues(v uint64) {
+	s.Struct.SetUint64(16, v)
+}
+
+func (s StreamStatistics) IntervalStats() (Welford, error) {
+	p, err := s.Struct.Ptr(0)
+	return Welford{Struct: p.Struct()}, err
+}
+
+func (s StreamStatistics) HasIntervalStats() bool {
+	return s.Struct.HasPtr(0)
+}
+
+func (s StreamStatistics) SetIntervalStats(v Welford) error {
+	return s.Struct.SetPtr(0, v.Struct.ToPtr())
+}
+
+// NewIntervalStats sets the intervalStats field to a newly
+// allocated Welford struct, preferring placement in s's segment.
+func (s StreamStatistics) NewIntervalStats() (Welford, error) {
+	ss, err := NewWelford(s.Struct.Segment())
+	if err != nil {
+		return Welford{}, err
+	}
+	err = s.Struct.SetPtr(0, ss.Struct.ToPtr())
+	return ss, err
+}
+
+func (s StreamStatistics) ValueStats() (Welford, error) {
+	p, err := s.Struct.Ptr(1)
+	return Welford{Struct: p.Struct()}, err
+}
+
+func (s StreamStatistics) HasValueStats() bool {
+	return s.Struct.HasPtr(1)
+}
+
+func (s StreamStatistics) SetValueStats(v Welford) error {
+	return s.Struct.SetPtr(1, v.Struct.ToPtr())
+}
+
+// NewValueStats sets the valueStats field to a newly
+// allocated Welford struct, preferring placement in s's segment.
+func (s StreamStatistics) NewValueStats() (Welford, error) {
+	ss, err := NewWelford(s.Struct.Segment())
+	if err != nil {
+		return Welford{}, err
+	}
+	err = s.Struct.SetPtr(1, ss.Struct.ToPtr())
+	return ss, err
+}
+
+func (s StreamStatistics) NumOutOfOrder() uint64 {
+	return s.Struct.Uint64(24)
+}
+
+func (s StreamStatistics) SetNumOutOfOrder(v uint64) {
+	s.Struct.SetUint64(24, v)
+}
+
+func (s StreamStatistics) NumDiscarded() uint64 {
+	return s.Struct.Uint64(32)
+}
+
+func (s StreamStatistics) SetNumDiscarded(v uint64) {
+	s.Struct.SetUint64(32, v)
+}
+
+func (s StreamStatistics) HasArrivals() bool {
+	return s.Struct.Bit(320)
+}
+
+func (s StreamStatistics) SetHasArrivals(v bool) {
+	s.Struct.SetBit(320, v)
+}
+
+func (s StreamStatistics) BaselineStats() (Welford, error) {
+	p, err := s.Struct.Ptr(2)
+	return Welford{Struct: p.Struct()}, err
+}
+
+func (s StreamStatistics) HasBaselineStats() bool {
+	return s.Struct.HasPtr(2)
+}
+
+func (s StreamStatistics) SetBaselineStats(v Welford) error {
+	return s.Struct.SetPtr(2, v.Struct.ToPtr())
+}
+
+// NewBaselineStats sets the baselineStats field to a newly
+// allocated Welford struct, preferring placement in s's segment.
+func (s StreamStatistics) NewBaselineStats() (Welford, error) {
+	ss, err := NewWelford(s.Struct.Segment())
+	if err != nil {
+		return Welford{}, err
+	}
+	err = s.Struct.SetPtr(2, ss.Struct.ToPtr())
+	return ss, err
+}
+
+// StreamStatistics_List is a list of StreamStatistics.
+type StreamStatistics_List struct{ capnp.List }
+
+// NewStreamStatistics creates a new list of StreamStatistics.
+func NewStreamStatistics_List(s *capnp.Segment, sz int32) (StreamStatistics_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 48, PointerCount: 3}, sz)
+	return StreamStatistics_List{l}, err
+}
+
+func (s StreamStatistics_List) At(i int) StreamStatistics { return StreamStatistics{s.List.Struct(i)} }
+
+func (s StreamStatistics_List) Set(i int, v StreamStatistics) error {
+	return s.List.SetStruct(i, v.Struct)
+}
+
+func (s StreamStatistics_List) String() string {
+	str, _ := text.MarshalList(0xa6cd454ce816484c, s.List)
+	return str
+}
+
+// StreamStatistics_Future is a wrapper for a StreamStatistics promised by a client call.
+type StreamStatistics_Future struct{ *capnp.Future }
+
+func (p StreamStatistics_Future) Struct() (StreamStatistics, error) {
+	s, err := p.Future.Struct()
+	return StreamStatistics{s}, err
+}
+
+func (p StreamStatistics_Future) IntervalStats() Welford_Future {
+	return Welford_Future{Future: p.Future.Field(0, nil)}
+}
+
+func (p StreamStatistics_Future) ValueStats() Welford_Future {
+	return Welford_Future{Future: p.Future.Field(1, nil)}
+}
+
+func (p StreamStatistics_Future) BaselineStats() Welford_Future {
+	return Welford_Future{Future: p.Future.Field(2, nil)}
+}
+
+type Decay uint16
+
+// Decay_TypeID is the unique identifier for the type Decay.
+const Decay_TypeID = 0xb8826ecab9ed49f1
+
+// Values of Decay.
+const (
+	Decay_count Decay = 0
+	Decay_time  Decay = 1
+)
+
+// String returns the enum's constant name.
+func (c Decay) String() string {
+	switch c {
+	case Decay_count:
+		return "count"
+	case Decay_time:
+		return "time"
+
+	default:
+		return ""
+	}
+}
+
+// DecayFromString returns the enum value with a name,
+// or the zero value if there's no such value.
+func DecayFromString(c string) Decay {
+	switch c {
+	case "count":
+		return Decay_count
+	case "time":
+		return Decay_time
+
+	default:
+		return 0
+	}
+}
+
+type Decay_List struct{ capnp.List }
+
+func NewDecay_List(s *capnp.Segment, sz int32) (Decay_List, error) {
+	l, err := capnp.NewUInt16List(s, sz)
+	return Decay_List{l.List}, err
+}
+
+func (l Decay_List) At(i int) Decay {
+	ul := capnp.UInt16List{List: l.List}
+	return Decay(ul.At(i))
+}
+
+func (l Decay_List) Set(i int, v Decay) {
+	ul := capnp.UInt16List{List: l.List}
+	ul.Set(i, uint16(v))
+}
+
+type OutOfOrderPolicy uint16
+
+// OutOfOrderPolicy_TypeID is the unique identifier for the type OutOfOrderPolicy.
+const OutOfOrderPolicy_TypeID = 0xa9749bda067da804
+
+// Values of OutOfOrderPolicy.
+const (
+	OutOfOrderPolicy_clamp   OutOfOrderPolicy = 0
+	OutOfOrderPolicy_reject  OutOfOrderPolicy = 1
+	OutOfOrderPolicy_drop    OutOfOrderPolicy = 2
+	OutOfOrderPolicy_reorder OutOfOrderPolicy = 3
+	OutOfOrderPolicy_fold    OutOfOrderPolicy = 4
+)
+
+// String returns the enum's constant name.
+func (c OutOfOrderPolicy) String() string {
+	switch c {
+	case OutOfOrderPolicy_clamp:
+		return "clamp"
+	case OutOfOrderPolicy_reject:
+		return "reject"
+	case OutOfOrderPolicy_drop:
+		return "drop"
+	case OutOfOrderPolicy_reorder:
+		return "reorder"
+	case OutOfOrderPolicy_fold:
+		return "fold"
+
+	default:
+		return ""
+	}
+}
+
+// OutOfOrderPolicyFromString returns the enum value with a name,
+// or the zero value if there's no such value.
+func OutOfOrderPolicyFromString(c string) OutOfOrderPolicy {
+	switch c {
+	case "clamp":
+		return OutOfOrderPolicy_clamp
+	case "reject":
+		return OutOfOrderPolicy_reject
+	case "drop":
+		return OutOfOrderPolicy_drop
+	case "reorder":
+		return OutOfOrderPolicy_reorder
+	case "fold":
+		return OutOfOrderPolicy_fold
+
+	default:
+		return 0
+	}
+}
+
+type OutOfOrderPolicy_List struct{ capnp.List }
+
+func NewOutOfOrderPolicy_List(s *capnp.Segment, sz int32) (OutOfOrderPolicy_List, error) {
+	l, err := capnp.NewUInt16List(s, sz)
+	return OutOfOrderPolicy_List{l.List}, err
+}
+
+func (l OutOfOrderPolicy_List) At(i int) OutOfOrderPolicy {
+	ul := capnp.UInt16List{List: l.List}
+	return OutOfOrderPolicy(ul.At(i))
+}
+
+func (l OutOfOrderPolicy_List) Set(i int, v OutOfOrderPolicy) {
+	ul := capnp.UInt16List{List: l.List}
+	ul.Set(i, uint16(v))
+}
+
+type Stream struct{ capnp.Struct }
+type Stream_window Stream
+type Stream_window_Which uint16
+
+const (
+	Stream_window_Which_exp        Stream_window_Which = 0
+	Stream_window_Which_power      Stream_window_Which = 1
+	Stream_window_Which_uniform    Stream_window_Which = 2
+	Stream_window_Which_polynomial Stream_window_Which = 3
+	Stream_window_Which_table      Stream_window_Which = 4
+)
+
+func (w Stream_window_Which) String() string {
+	const s = "exppoweruniformpolynomialtable"
+	switch w {
+	case Stream_window_Which_exp:
+		return s[0:3]
+	case Stream_window_Which_power:
+		return s[3:8]
+	case Stream_window_Which_uniform:
+		return s[8:15]
+	case Stream_window_Which_polynomial:
+		return s[15:25]
+	case Stream_window_Which_table:
+		return s[25:30]
+
+	}
+	return "Stream_window_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
+}
+
+// Stream_TypeID is the unique identifier for the type Stream.
+const Stream_TypeID = 0xcf7581f95c7adbb1
+
+func NewStream(s *capnp.Segment) (Stream, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 48, PointerCount: 9})
+	return Stream{st}, err
+}
+
+func NewRootStream(s *capnp.Segment) (Stream, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 48, PointerCount: 9})
+	return Stream{st}, err
+}
+
+func ReadRootStream(msg *capnp.Message) (Stream, error) {
+	root, err := msg.Root()
+	return Stream{root.Struct()}, err
+}
+
+func (s Stream) String() string {
+	str, _ := text.Marshal(0xcf7581f95c7adbb1, s.Struct)
+	return str
+}
+
+func (s Stream) Id() int64 {
+	return int64(s.Struct.Uint64(0))
+}
+
+func (s Stream) SetId(v int64) {
+	s.Struct.SetUint64(0, uint64(v))
+}
+
+func (s Stream) Operators() (OpType_List, error) {
+	p, err := s.Struct.Ptr(0)
+	return OpType_List{List: p.List()}, err
+}
+
+func (s Stream) HasOperators() bool {
+	return s.Struct.HasPtr(0)
+}
+
+func (s Stream) SetOperators(v OpType_List) error {
+	return s.Struct.SetPtr(0, v.List.ToPtr())
+}
+
+// NewOperators sets the operators field to a newly
+// allocated OpType_List, preferring placement in s's segment.
+func (s Stream) NewOperators(n int32) (OpType_List, error) {
+	l, err := NewOpType_List(s.Struct.Segment(), n)
+	if err != nil {
+		return OpType_List{}, err
+	}
+	err = s.Struct.SetPtr(0, l.List.ToPtr())
+	return l, err
+}
+
+func (s Stream) Window() Stream_window { return Stream_window(s) }
+
+func (s Stream_window) Which() Stream_window_Which {
+	return Stream_window_Which(s.Struct.Uint16(8))
+}
+func (s Stream_window) Exp() (ExpWindow, error) {
+	if s.Struct.Uint16(8) != 0 {
+		panic("Which() != exp")
+	}
+	p, err := s.Struct.Ptr(1)
+	return ExpWindow{Struct: p.Struct()}, err
+}
+
+func (s Stream_window) HasExp() bool {
+	if s.Struct.Uint16(8) != 0 {
+		return false
+	}
+	return s.Struct.HasPtr(1)
+}
+
+func (s Stream_window) SetExp(v ExpWindow) error {
+	s.Struct.SetUint16(8, 0)
+	return s.Struct.SetPtr(1, v.Struct.ToPtr())
+}
+
+// NewExp sets the exp field to a newly
+// allocated ExpWindow struct, preferring placement in s's segment.
+func (s Stream_window) NewExp() (ExpWindow, error) {
+	s.Struct.SetUint16(8, 0)
+	ss, err := NewExpWindow(s.Struct.Segment())
+	if err != nil {
+		return ExpWindow{}, err
+	}
+	err = s.Struct.SetPtr(1, ss.Struct.ToPtr())
+	return ss, err
+}
+
+func (s Stream_window) Power() (PowerWindow, error) {
+	if s.Struct.Uint16(8) != 1 {
+		panic("Which() != power")
+	}
+	p, err := s.Struct.Ptr(1)
+	return PowerWindow{Struct: p.Struct()}, err
+}
+
+func (s Stream_window) HasPower() bool {
+	if s.Struct.Uint16(8) != 1 {
+		return false
+	}
+	return s.Struct.HasPtr(1)
+}
+
+func (s Stream_window) SetPower(v PowerWindow) error {
+	s.Struct.SetUint16(8, 1)
+	return s.Struct.SetPtr(1, v.Struct.ToPtr())
+}
+
+// NewPower sets the power field to a newly
+// allocated PowerWindow struct, preferring placement in s's segment.
+func (s Stream_window) NewPower() (PowerWindow, error) {
+	s.Struct.SetUint16(8, 1)
+	ss, err := NewPowerWindow(s.Struct.Segment())
+	if err != nil {
+		return PowerWindow{}, err
+	}
+	err = s.Struct.SetPtr(1, ss.Struct.ToPtr())
+	return ss, err
+}
+
+func (s Stream_window) Uniform() (UniformWindow, error) {
+	if s.Struct.Uint16(8) != 2 {
+		panic("Which() != uniform")
+	}
+	p, err := s.Struct.Ptr(1)
+	return UniformWindow{Struct: p.Struct()}, err
+}
+
+func (s Stream_window) HasUniform() bool {
+	if s.Struct.Uint16(8) != 2 {
+		return false
+	}
+	return s.Struct.HasPtr(1)
+}
+
+func (s Stream_window) SetUniform(v UniformWindow) error {
+	s.Struct.SetUint16(8, 2)
+	return s.Struct.SetPtr(1, v.Struct.ToPtr())
+}
+
+// NewUniform sets the uniform field to a newly
+// allocated UniformWindow struct, preferring placement in s's segment.
+func (s Stream_window) NewUniform() (UniformWindow, error) {
+	s.Struct.SetUint16(8, 2)
+	ss, err := NewUniformWindow(s.Struct.Segment())
+	if err != nil {
+		return UniformWindow{}, err
+	}
+	err = s.Struct.SetPtr(1, ss.Struct.ToPtr())
+	return ss, err
+}
+
+func (s Stream_window) Polynomial() (PolynomialWindow, error) {
+	if s.Struct.Uint16(8) != 3 {
+		panic("Which() != polynomial")
+	}
+	p, err := s.Struct.Ptr(1)
+	return PolynomialWindow{Struct: p.Struct()}, err
+}
+
+func (s Stream_window) HasPolynomial() bool {
+	if s.Struct.Uint16(8) != 3 {
+		return false
+	}
+	return s.Struct.HasPtr(1)
+}
+
+func (s Stream_window) SetPolynomial(v PolynomialWindow) error {
+	s.Struct.SetUint16(8, 3)
+	return s.Struct.SetPtr(1, v.Struct.ToPtr())
+}
+
+// NewPolynomial sets the polynomial field to a newly
+// allocated PolynomialWindow struct, preferring placement in s's segment.
+func (s Stream_window) NewPolynomial() (PolynomialWindow, error) {
+	s.Struct.SetUint16(8, 3)
+	ss, err := NewPolynomialWindow(s.Struct.Segment())
+	if err != nil {
+		return PolynomialWindow{}, err
+	}
+	err = s.Struct.SetPtr(1, ss.Struct.ToPtr())
+	return ss, err
+}
+
+func (s Stream_window) Table() (TableWindow, error) {
+	if s.Struct.Uint16(8) != 4 {
+		panic("Which() != table")
+	}
+	p, err := s.Struct.Ptr(1)
+	return TableWindow{Struct: p.Struct()}, err
+}
+
+func (s Stream_window) HasTable() bool {
+	if s.Struct.Uint16(8) != 4 {
+		return false
+	}
+	return s.Struct.HasPtr(1)
+}
+
+func (s Stream_window) SetTable(v TableWindow) error {
+	s.Struct.SetUint16(8, 4)
+	return s.Struct.SetPtr(1, v.Struct.ToPtr())
+}
+
+// NewTable sets the table field to a newly
+// allocated TableWindow struct, preferring placement in s's segment.
+func (s Stream_window) NewTable() (TableWindow, error) {
+	s.Struct.SetUint16(8, 4)
+	ss, err := NewTableWindow(s.Struct.Segment())
+	if err != nil {
+		return TableWindow{}, err
+	}
+	err = s.Struct.SetPtr(1, ss.Struct.ToPtr())
+	return ss, err
+}
+
+func (s Stream) Statistics() (StreamStatistics, error) {
+	p, err := s.Struct.Ptr(2)
+	return StreamStatistics{Struct: p.Struct()}, err
+}
+
+func (s Stream) HasStatistics() bool {
+	return s.Struct.HasPtr(2)
+}
+
+func (s Stream) SetStatistics(v StreamStatistics) error {
+	return s.Struct.SetPtr(2, v.Struct.ToPtr())
+}
+
+// NewStatistics sets the statistics field to a newly
+// allocated StreamStatistics struct, preferring placement in s's segment.
+func (s Stream) NewStatistics() (StreamStatistics, error) {
+	ss, err := NewStreamStatistics(s.Struct.Segment())
+	if err != nil {
+		return StreamStatistics{}, err
+	}
+	err = s.Struct.SetPtr(2, ss.Struct.ToPtr())
+	return ss, err
+}
+
+func (s Stream) Decay() Decay {
+	return Decay(s.Struct.Uint16(10))
+}
+
+func (s Stream) SetDecay(v Decay) {
+	s.Struct.SetUint16(10, uint16(v))
+}
+
+func (s Stream) OperatorsSince() (OperatorSince_List, error) {
+	p, err := s.Struct.Ptr(3)
+	return OperatorSince_List{List: p.List()}, err
+}
+
+func (s Stream) HasOperatorsSince() bool {
+	return s.Struct.HasPtr(3)
+}
+
+func (s Stream) SetOperatorsSince(v OperatorSince_List) error {
+	return s.Struct.SetPtr(3, v.List.ToPtr())
+}
+
+// NewOperatorsSince sets the operatorsSince field to a newly
+// allocated OperatorSince_List, preferring placement in s's segment.
+func (s Stream) NewOperatorsSince(n int32) (OperatorSince_List, error) {
+	l, err := NewOperatorSince_List(s.Struct.Segment(), n)
+	if err != nil {
+		return OperatorSince_List{}, err
+	}
+	err = s.Struct.SetPtr(3, l.List.ToPtr())
+	return l, err
+}
+
+func (s Stream) LandmarkTriggers() (LandmarkTriggers, error) {
+	p, err := s.Struct.Ptr(4)
+	return LandmarkTriggers{Struct: p.Struct()}, err
+}
+
+func (s Stream) HasLandmarkTriggers() bool {
+	return s.Struct.HasPtr(4)
+}
+
+func (s Stream) SetLandmarkTriggers(v LandmarkTriggers) error {
+	return s.Struct.SetPtr(4, v.Struct.ToPtr())
+}
+
+// NewLandmarkTriggers sets the landmarkTriggers field to a newly
+// allocated LandmarkTriggers struct, preferring placement in s's segment.
+func (s Stream) NewLandmarkTriggers() (LandmarkTriggers, error) {
+	ss, err := NewLandmarkTriggers(s.Struct.Segment())
+	if err != nil {
+		return LandmarkTriggers{}, err
+	}
+	err = s.Struct.SetPtr(4, ss.Struct.ToPtr())
+	return ss, err
+}
+
+func (s Stream) LandmarkRetention() int64 {
+	return int64(s.Struct.Uint64(16))
+}
+
+func (s Stream) SetLandmarkRetention(v int64) {
+	s.Struct.SetUint64(16, uint64(v))
+}
+
+func (s Stream) OutOfOrderPolicy() OutOfOrderPolicy {
+	return OutOfOrderPolicy(s.Struct.Uint16(12))
+}
+
+func (s Stream) SetOutOfOrderPolicy(v OutOfOrderPolicy) {
+	s.Struct.SetUint16(12, uint16(v))
+}
+
+func (s Stream) ReorderWindow() uint32 {
+	return s.Struct.Uint32(24)
+}
+
+func (s Stream) SetReorderWindow(v uint32) {
+	s.Struct.SetUint32(24, v)
+}
+
+func (s Stream) Fields() (Field_List, error) {
+	p, err := s.Struct.Ptr(5)
+	return Field_List{List: p.List()}, err
+}
+
+func (s Stream) HasFields() bool {
+	return s.Struct.HasPtr(5)
+}
+
+func (s Stream) SetFields(v Field_List) error {
+	return s.Struct.SetPtr(5, v.List.ToPtr())
+}
+
+// NewFields sets the fields field to a newly
+// allocated Field_List, preferring placement in s's segment.
+func (s Stream) NewFields(n int32) (Field_List, error) {
+	l, err := NewField_List(s.Struct.Segment(), n)
+	if err != nil {
+		return Field_List{}, err
+	}
+	err = s.Struct.SetPtr(5, l.List.ToPtr())
+	return l, err
+}
+
+func (s Stream) Name() (string, error) {
+	p, err := s.Struct.Ptr(6)
+	return p.Text(), err
+}
+
+func (s Stream) HasName() bool {
+	return s.Struct.HasPtr(6)
+}
+
+func (s Stream) NameBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(6)
+	return p.TextBytes(), err
+}
+
+func (s Stream) SetName(v string) error {
+	return s.Struct.SetText(6, v)
+}
+
+func (s Stream) Labels() (Label_List, error) {
+	p, err := s.Struct.Ptr(7)
+	return Label_List{List: p.List()}, err
+}
+
+func (s Stream) HasLabels() bool {
+	return s.Struct.HasPtr(7)
+}
+
+func (s Stream) SetLabels(v Label_List) error {
+	return s.Struct.SetPtr(7, v.List.ToPtr())
+}
+
+// NewLabels sets the labels field to a newly
+// allocated Label_List, preferring placement in s's segment.
+func (s Stream) NewLabels(n int32) (Label_List, error) {
+	l, err := NewLabel_List(s.Struct.Segment(), n)
+	if err != nil {
+		return Label_List{}, err
+	}
+	err = s.Struct.SetPtr(7, l.List.ToPtr())
+	return l, err
+}
+
+func (s Stream) Retention() int64 {
+	return int64(s.Struct.Uint64(32))
+}
+
+func (s Stream) SetRetention(v int64) {
+	s.Struct.SetUint64(32, uint64(v))
+}
+
+func (s Stream) Config() (StoreConfig, error) {
+	p, err := s.Struct.Ptr(8)
+	return StoreConfig{Struct: p.Struct()}, err
+}
+
+func (s Stream) HasConfig() bool {
+	return s.Struct.HasPtr(8)
+}
+
+func (s Stream) SetConfig(v StoreConfig) error {
+	return s.Struct.SetPtr(8, v.Struct.ToPtr())
+}
+
+// NewConfig sets the config field to a newly
+// allocated StoreConfig struct, preferring placement in s's segment.
+func (s Stream) NewConfig() (StoreConfig, error) {
+	ss, err := NewStoreConfig(s.Struct.Segment())
+	if err != nil {
+		return StoreConfig{}, err
+	}
+	err = s.Struct.SetPtr(8, ss.Struct.ToPtr())
+	return ss, err
+}
+
+func (s Stream) StatisticsEnd() uint64 {
+	return s.Struct.Uint64(40)
+}
+
+func (s Stream) SetStatisticsEnd(v uint64) {
+	s.Struct.SetUint64(40, v)
+}
+
+// Stream_List is a list of Stream.
+type Stream_List struct{ capnp.List }
+
+// NewStream creates a new list of Stream.
+func NewStream_List(s *capnp.Segment, sz int32) (Stream_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 48, PointerCount: 9}, sz)
+	return Stream_List{l}, err
+}
+
+func (s Stream_List) At(i int) Stream { return Stream{s.List.Struct(i)} }
+
+func (s Stream_List) Set(i int, v Stream) error { return s.List.SetStruct(i, v.Struct) }
+
+func (s Stream_List) String() string {
+	str, _ := text.MarshalList(0xcf7581f95c7adbb1, s.List)
+	return str
+}
+
+// Stream_Future is a wrapper for a Stream promised by a client call.
+type Stream_Future struct{ *capnp.Future }
+
+func (p Stream_Future) Struct() (Stream, error) {
+	s, err := p.Future.Struct()
+	return Stream{s}, err
+}
+
+func (p Stream_Future) Window() Stream_window_Future { return Stream_window_Future{p.Future} }
+
+// Stream_window_Future is a wrapper for a Stream_window promised by a client call.
+type Stream_window_Future struct{ *capnp.Future }
+
+func (p Stream_window_Future) Struct() (Stream_window, error) {
+	s, err := p.Future.Struct()
+	return Stream_window{s}, err
+}
+
+func (p Stream_window_Future) Exp() ExpWindow_Future {
+	return ExpWindow_Future{Future: p.Future.Field(1, nil)}
+}
+
+func (p Stream_window_Future) Power() PowerWindow_Future {
+	return PowerWindow_Future{Future: p.Future.Field(1, nil)}
+}
+
+func (p Stream_window_Future) Uniform() UniformWindow_Future {
+	return UniformWindow_Future{Future: p.Future.Field(1, nil)}
+}
+
+func (p Stream_window_Future) Polynomial() PolynomialWindow_Future {
+	return PolynomialWindow_Future{Future: p.Future.Field(1, nil)}
+}
+
+func (p Stream_window_Future) Table() TableWindow_Future {
+	return TableWindow_Future{Future: p.Future.Field(1, nil)}
+}
+
+func (p Stream_Future) Statistics() StreamStatistics_Future {
+	return StreamStatistics_Future{Future: p.Future.Field(2, nil)}
+}
+
+func (p Stream_Future) LandmarkTriggers() LandmarkTriggers_Future {
+	return LandmarkTriggers_Future{Future: p.Future.Field(4, nil)}
+}
+
+func (p Stream_Future) Config() StoreConfig_Future {
+	return StoreConfig_Future{Future: p.Future.Field(8, nil)}
+}
+
+type StoreConfig struct{ capnp.Struct }
+
+// StoreConfig_TypeID is the unique identifier for the type StoreConfig.
+const StoreConfig_TypeID = 0xbf832f54f5c887ca
+
+func NewStoreConfig(s *capnp.Segment) (StoreConfig, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 0})
+	return StoreConfig{st}, err
+}
+
+func NewRootStoreConfig(s *capnp.Segment) (StoreConfig, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 0})
+	return StoreConfig{st}, err
+}
+
+func ReadRootStoreConfig(msg *capnp.Message) (StoreConfig, error) {
+	root, err := msg.Root()
+	return StoreConfig{root.Struct()}, err
+}
+
+func (s StoreConfig) String() string {
+	str, _ := text.Marshal(0xbf832f54f5c887ca, s.Struct)
+	return str
+}
+
+func (s StoreConfig) EachBufferSize() int64 {
+	return int64(s.Struct.Uint64(0))
+}
+
+func (s StoreConfig) SetEachBufferSize(v int64) {
+	s.Struct.SetUint64(0, uint64(v))
+}
+
+func (s StoreConfig) NumBuffer() int64 {
+	return int64(s.Struct.Uint64(8))
+}
+
+func (s StoreConfig) SetNumBuffer(v int64) {
+	s.Struct.SetUint64(8, uint64(v))
+}
+
+func (s StoreConfig) WindowsPerMerge() int64 {
+	return int64(s.Struct.Uint64(16))
+}
+
+func (s StoreConfig) SetWindowsPerMerge(v int64) {
+	s.Struct.SetUint64(16, uint64(v))
+}
+
+// StoreConfig_List is a list of StoreConfig.
+type StoreConfig_List struct{ capnp.List }
+
+// NewStoreConfig creates a new list of StoreConfig.
+func NewStoreConfig_List(s *capnp.Segment, sz int32) (StoreConfig_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 24, PointerCount: 0}, sz)
+	return StoreConfig_List{l}, err
+}
+
+func (s StoreConfig_List) At(i int) StoreConfig { return StoreConfig{s.List.Struct(i)} }
+
+func (s StoreConfig_List) Set(i int, v StoreConfig) error { return s.List.SetStruct(i, v.Struct) }
+
+func (s StoreConfig_List) String() string {
+	str, _ := text.MarshalList(0xbf832f54f5c887ca, s.List)
+	return str
+}
+
+// StoreConfig_Future is a wrapper for a StoreConfig promised by a client call.
+type StoreConfig_Future struct{ *capnp.Future }
+
+func (p StoreConfig_Future) Struct() (StoreConfig, error) {
+	s, err := p.Future.Struct()
+	return StoreConfig{s}, err
+}
+
+type Label struct{ capnp.Struct }
+
+// Label_TypeID is the unique identifier for the type Label.
+const Label_TypeID = 0xe24478bb6405cb74
+
+func NewLabel(s *capnp.Segment) (Label, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return Label{st}, err
+}
+
+func NewRootLabel(s *capnp.Segment) (Label, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return Label{st}, err
+}
+
+func ReadRootLabel(msg *capnp.Message) (Label, error) {
+	root, err := msg.Root()
+	return Label{root.Struct()}, err
+}
+
+func (s Label) String() string {
+	str, _ := text.Marshal(0xe24478bb6405cb74, s.Struct)
+	return str
+}
+
+func (s Label) Name() (string, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.Text(), err
+}
+
+func (s Label) HasName() bool {
+	return s.Struct.HasPtr(0)
+}
+
+func (s Label) NameBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s Label) SetName(v string) error {
+	return s.Struct.SetText(0, v)
+}
+
+func (s Label) Value() (string, error) {
+	p, err := s.Struct.Ptr(1)
+	return p.Text(), err
+}
+
+func (s Label) HasValue() bool {
+	return s.Struct.HasPtr(1)
+}
+
+func (s Label) ValueBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(1)
+	return p.TextBytes(), err
+}
+
+func (s Label) SetValue(v string) error {
+	return s.Struct.SetText(1, v)
+}
+
+// Label_List is a list of Label.
+type Label_List struct{ capnp.List }
+
+// NewLabel creates a new list of Label.
+func NewLabel_List(s *capnp.Segment, sz int32) (Label_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2}, sz)
+	return Label_List{l}, err
+}
+
+func (s Label_List) At(i int) Label { return Label{s.List.Struct(i)} }
+
+func (s Label_List) Set(i int, v Label) error { return s.List.SetStruct(i, v.Struct) }
+
+func (s Label_List) String() string {
+	str, _ := text.MarshalList(0xe24478bb6405cb74, s.List)
+	return str
+}
+
+// Label_Future is a wrapper for a Label promised by a client call.
+type Label_Future struct{ *capnp.Future }
+
+func (p Label_Future) Struct() (Label, error) {
+	s, err := p.Future.Struct()
+	return Label{s}, err
+}
+
+type Field struct{ capnp.Struct }
+
+// Field_TypeID is the unique identifier for the type Field.
+const Field_TypeID = 0xe8034329ad3d142d
+
+func NewField(s *capnp.Segment) (Field, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return Field{st}, err
+}
+
+func NewRootField(s *capnp.Segment) (Field, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	return Field{st}, err
+}
+
+func ReadRootField(msg *capnp.Message) (Field, error) {
+	root, err := msg.Root()
+	return Field{root.Struct()}, err
+}
+
+func (s Field) String() string {
+	str, _ := text.Marshal(0xe8034329ad3d142d, s.Struct)
+	return str
+}
+
+func (s Field) Name() (string, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.Text(), err
+}
+
+func (s Field) HasName() bool {
+	return s.Struct.HasPtr(0)
+}
+
+func (s Field) NameBytes() ([]byte, error) {
+	p, err := s.Struct.Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s Field) SetName(v string) error {
+	return s.Struct.SetText(0, v)
+}
+
+func (s Field) Operators() (OpType_List, error) {
+	p, err := s.Struct.Ptr(1)
+	return OpType_List{List: p.List()}, err
+}
+
+func (s Field) HasOperators() bool {
+	return s.Struct.HasPtr(1)
+}
+
+func (s Field) SetOperators(v OpType_List) error {
+	return s.Struct.SetPtr(1, v.List.ToPtr())
+}
+
+// NewOperators sets the operators field to a newly
+// allocated OpType_List, preferring placement in s's segment.
+func (s Field) NewOperators(n int32) (OpType_List, error) {
+	l, err := NewOpType_List(s.Struct.Segment(), n)
+	if err != nil {
+		return OpType_List{}, err
+	}
+	err = s.Struct.SetPtr(1, l.List.ToPtr())
+	return l, err
+}
+
+// Field_List is a list of Field.
+type Field_List struct{ capnp.List }
+
+// NewField creates a new list of Field.
+func NewField_List(s *capnp.Segment, sz int32) (Field_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 2}, sz)
+	return Field_List{l}, err
+}
+
+func (s Field_List) At(i int) Field { return Field{s.List.Struct(i)} }
+
+func (s Field_List) Set(i int, v Field) error { return s.List.SetStruct(i, v.Struct) }
+
+func (s Field_List) String() string {
+	str, _ := text.MarshalList(0xe8034329ad3d142d, s.List)
+	return str
+}
+
+// Field_Future is a wrapper for a Field promised by a client call.
+type Field_Future struct{ *capnp.Future }
+
+func (p Field_Future) Struct() (Field, error) {
+	s, err := p.Future.Struct()
+	return Field{s}, err
+}
+
+type ThresholdRule struct{ capnp.Struct }
+
+// ThresholdRule_TypeID is the unique identifier for the type ThresholdRule.
+const ThresholdRule_TypeID = 0x9365d0d364718c56
+
+func NewThresholdRule(s *capnp.Segment) (ThresholdRule, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 0})
+	return ThresholdRule{st}, err
+}
+
+func NewRootThresholdRule(s *capnp.Segment) (ThresholdRule, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 0})
+	return ThresholdRule{st}, err
+}
+
+func ReadRootThresholdRule(msg *capnp.Message) (ThresholdRule, error) {
+	root, err := msg.Root()
+	return ThresholdRule{root.Struct()}, err
+}
+
+func (s ThresholdRule) String() string {
+	str, _ := text.Marshal(0x9365d0d364718c56, s.Struct)
+	return str
+}
+
+func (s ThresholdRule) Lower() float64 {
+	return math.Float64frombits(s.Struct.Uint64(0))
+}
+
+func (s ThresholdRule) SetLower(v float64) {
+	s.Struct.SetUint64(0, math.Float64bits(v))
+}
+
+func (s ThresholdRule) Upper() float64 {
+	return math.Float64frombits(s.Struct.Uint64(8))
+}
+
+func (s ThresholdRule) SetUpper(v float64) {
+	s.Struct.SetUint64(8, math.Float64bits(v))
+}
+
+// ThresholdRule_List is a list of ThresholdRule.
+type ThresholdRule_List struct{ capnp.List }
+
+// NewThresholdRule creates a new list of ThresholdRule.
+func NewThresholdRule_List(s *capnp.Segment, sz int32) (ThresholdRule_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 0}, sz)
+	return ThresholdRule_List{l}, err
+}
+
+func (s ThresholdRule_List) At(i int) ThresholdRule { return ThresholdRule{s.List.Struct(i)} }
+
+func (s ThresholdRule_List) Set(i int, v ThresholdRule) error { return s.List.SetStruct(i, v.Struct) }
+
+func (s ThresholdRule_List) String() string {
+	str, _ := text.MarshalList(0x9365d0d364718c56, s.List)
+	return str
+}
+
+// ThresholdRule_Future is a wrapper for a ThresholdRule promised by a client call.
+type ThresholdRule_Future struct{ *capnp.Future }
+
+func (p ThresholdRule_Future) Struct() (ThresholdRule, error) {
+	s, err := p.Future.Struct()
+	return ThresholdRule{s}, err
+}
+
+type SigmaRule struct{ capnp.Struct }
+
+// SigmaRule_TypeID is the unique identifier for the type SigmaRule.
+const SigmaRule_TypeID = 0xb0739abbaf647dce
+
+func NewSigmaRule(s *capnp.Segment) (SigmaRule, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 0})
+	return SigmaRule{st}, err
+}
+
+func NewRootSigmaRule(s *capnp.Segment) (SigmaRule, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 0})
+	return SigmaRule{st}, err
+}
+
+func ReadRootSigmaRule(msg *capnp.Message) (SigmaRule, error) {
+	root, err := msg.Root()
+	return SigmaRule{root.Struct()}, err
+}
+
+func (s SigmaRule) String() string {
+	str, _ := text.Marshal(0xb0739abbaf647dce, s.Struct)
+	return str
+}
+
+func (s SigmaRule) Sigmas() float64 {
+	return math.Float64frombits(s.Struct.Uint64(0))
+}
+
+func (s SigmaRule) SetSigmas(v float64) {
+	s.Struct.SetUint64(0, math.Float64bits(v))
+}
+
+func (s SigmaRule) MinValues() uint64 {
+	return s.Struct.Uint64(8)
+}
+
+func (s SigmaRule) SetMinValues(v uint64) {
+	s.Struct.SetUint64(8, v)
+}
+
+// SigmaRule_List is a list of SigmaRule.
+type SigmaRule_List struct{ capnp.List }
+
+// NewSigmaRule creates a new list of SigmaRule.
+func NewSigmaRule_List(s *capnp.Segment, sz int32) (SigmaRule_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 0}, sz)
+	return SigmaRule_List{l}, err
+}
+
+func (s SigmaRule_List) At(i int) SigmaRule { return SigmaRule{s.List.Struct(i)} }
+
+func (s SigmaRule_List) Set(i int, v SigmaRule) error { return s.List.SetStruct(i, v.Struct) }
+
+func (s SigmaRule_List) String() string {
+	str, _ := text.MarshalList(0xb0739abbaf647dce, s.List)
+	return str
+}
+
+// SigmaRule_Future is a wrapper for a SigmaRule promised by a client call.
+type SigmaRule_Future struct{ *capnp.Future }
+
+func (p SigmaRule_Future) Struct() (SigmaRule, error) {
+	s, err := p.Future.Struct()
+	return SigmaRule{s}, err
+}
+
+type RateOfChangeRule struct{ capnp.Struct }
+
+// RateOfChangeRule_TypeID is the unique identifier for the type RateOfChangeRule.
+const RateOfChangeRule_TypeID = 0xcd7789d4f6786809
+
+func NewRateOfChangeRule(s *capnp.Segment) (RateOfChangeRule, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 0})
+	return RateOfChangeRule{st}, err
 }
 
-func (s PowerWindow) SetQ(v int64) {
-	s.Struct.SetUint64(8, uint64(v))
+func NewRootRateOfChangeRule(s *capnp.Segment) (RateOfChangeRule, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 0})
+	return RateOfChangeRule{st}, err
 }
 
-func (s PowerWindow) R() int64 {
-	return int64(s.Struct.Uint64(16))
+func ReadRootRateOfChangeRule(msg *capnp.Message) (RateOfChangeRule, error) {
+	root, err := msg.Root()
+	return RateOfChangeRule{root.Struct()}, err
 }
 
-func (s PowerWindow) SetR(v int64) {
-	s.Struct.SetUint64(16, uint64(v))
+func (s RateOfChangeRule) String() string {
+	str, _ := text.Marshal(0xcd7789d4f6786809, s.Struct)
+	return str
 }
 
-func (s PowerWindow) S() int64 {
-	return int64(s.Struct.Uint64(24))
+func (s RateOfChangeRule) MaxRate() float64 {
+	return math.Float64frombits(s.Struct.Uint64(0))
 }
 
-func (s PowerWindow) SetS(v int64) {
-	s.Struct.SetUint64(24, uint64(v))
+func (s RateOfChangeRule) SetMaxRate(v float64) {
+	s.Struct.SetUint64(0, math.Float64bits(v))
 }
 
-// PowerWindow_List is a list of PowerWindow.
-type PowerWindow_List struct{ capnp.List }
+// RateOfChangeRule_List is a list of RateOfChangeRule.
+type RateOfChangeRule_List struct{ capnp.List }
 
-// NewPowerWindow creates a new list of PowerWindow.
-func NewPowerWindow_List(s *capnp.Segment, sz int32) (PowerWindow_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 32, PointerCount: 0}, sz)
-	return PowerWindow_List{l}, err
+// NewRateOfChangeRule creates a new list of RateOfChangeRule.
+func NewRateOfChangeRule_List(s *capnp.Segment, sz int32) (RateOfChangeRule_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 0}, sz)
+	return RateOfChangeRule_List{l}, err
 }
 
-func (s PowerWindow_List) At(i int) PowerWindow { return PowerWindow{s.List.Struct(i)} }
+func (s RateOfChangeRule_List) At(i int) RateOfChangeRule { return RateOfChangeRule{s.List.Struct(i)} }
 
-func (s PowerWindow_List) Set(i int, v PowerWindow) error { return s.List.SetStruct(i, v.Struct) }
+func (s RateOfChangeRule_List) Set(i int, v RateOfChangeRule) error {
+	return s.List.SetStruct(i, v.Struct)
+}
 
-func (s PowerWindow_List) String() string {
-	str, _ := text.MarshalList(0xc06345e07edc6c60, s.List)
+func (s RateOfChangeRule_List) String() string {
+	str, _ := text.MarshalList(0xcd7789d4f6786809, s.List)
 	return str
 }
 
-// PowerWindow_Future is a wrapper for a PowerWindow promised by a client call.
-type PowerWindow_Future struct{ *capnp.Future }
+// RateOfChangeRule_Future is a wrapper for a RateOfChangeRule promised by a client call.
+type RateOfChangeRule_Future struct{ *capnp.Future }
 
-func (p PowerWindow_Future) Struct() (PowerWindow, error) {
+func (p RateOfChangeRule_Future) Struct() (RateOfChangeRule, error) {
 	s, err := p.Future.Struct()
-	return PowerWindow{s}, err
+	return RateOfChangeRule{s}, err
 }
 
-type Stream struct{ capnp.Struct }
-type Stream_window Stream
-type Stream_window_Which uint16
+type LandmarkRule struct{ capnp.Struct }
+type LandmarkRule_Which uint16
 
 const (
-	Stream_window_Which_exp   Stream_window_Which = 0
-	Stream_window_Which_power Stream_window_Which = 1
+	LandmarkRule_Which_threshold    LandmarkRule_Which = 0
+	LandmarkRule_Which_sigma        LandmarkRule_Which = 1
+	LandmarkRule_Which_rateOfChange LandmarkRule_Which = 2
 )
 
-func (w Stream_window_Which) String() string {
-	const s = "exppower"
+func (w LandmarkRule_Which) String() string {
+	const s = "thresholdsigmarateOfChange"
 	switch w {
-	case Stream_window_Which_exp:
-		return s[0:3]
-	case Stream_window_Which_power:
-		return s[3:8]
+	case LandmarkRule_Which_threshold:
+		return s[0:9]
+	case LandmarkRule_Which_sigma:
+		return s[9:14]
+	case LandmarkRule_Which_rateOfChange:
+		return s[14:26]
 
 	}
-	return "Stream_window_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
+	return "LandmarkRule_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
-// Stream_TypeID is the unique identifier for the type Stream.
-const Stream_TypeID = 0xcf7581f95c7adbb1
+// LandmarkRule_TypeID is the unique identifier for the type LandmarkRule.
+const LandmarkRule_TypeID = 0xc6f07f0e071f2c9a
 
-func NewStream(s *capnp.Segment) (Stream, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 2})
-	return Stream{st}, err
+func NewLandmarkRule(s *capnp.Segment) (LandmarkRule, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return LandmarkRule{st}, err
 }
 
-func NewRootStream(s *capnp.Segment) (Stream, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 2})
-	return Stream{st}, err
+func NewRootLandmarkRule(s *capnp.Segment) (LandmarkRule, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return LandmarkRule{st}, err
 }
 
-func ReadRootStream(msg *capnp.Message) (Stream, error) {
+func ReadRootLandmarkRule(msg *capnp.Message) (LandmarkRule, error) {
 	root, err := msg.Root()
-	return Stream{root.Struct()}, err
+	return LandmarkRule{root.Struct()}, err
 }
 
-func (s Stream) String() string {
-	str, _ := text.Marshal(0xcf7581f95c7adbb1, s.Struct)
+func (s LandmarkRule) String() string {
+	str, _ := text.Marshal(0xc6f07f0e071f2c9a, s.Struct)
 	return str
 }
 
-func (s Stream) Id() int64 {
-	return int64(s.Struct.Uint64(0))
-}
-
-func (s Stream) SetId(v int64) {
-	s.Struct.SetUint64(0, uint64(v))
+func (s LandmarkRule) Which() LandmarkRule_Which {
+	return LandmarkRule_Which(s.Struct.Uint16(0))
 }
-
-func (s Stream) Operators() (OpType_List, error) {
+func (s LandmarkRule) Threshold() (ThresholdRule, error) {
+	if s.Struct.Uint16(0) != 0 {
+		panic("Which() != threshold")
+	}
 	p, err := s.Struct.Ptr(0)
-	return OpType_List{List: p.List()}, err
+	return ThresholdRule{Struct: p.Struct()}, err
 }
 
-func (s Stream) HasOperators() bool {
+func (s LandmarkRule) HasThreshold() bool {
+	if s.Struct.Uint16(0) != 0 {
+		return false
+	}
 	return s.Struct.HasPtr(0)
 }
 
-func (s Stream) SetOperators(v OpType_List) error {
-	return s.Struct.SetPtr(0, v.List.ToPtr())
+func (s LandmarkRule) SetThreshold(v ThresholdRule) error {
+	s.Struct.SetUint16(0, 0)
+	return s.Struct.SetPtr(0, v.Struct.ToPtr())
 }
 
-// NewOperators sets the operators field to a newly
-// allocated OpType_List, preferring placement in s's segment.
-func (s Stream) NewOperators(n int32) (OpType_List, error) {
-	l, err := NewOpType_List(s.Struct.Segment(), n)
+// NewThreshold sets the threshold field to a newly
+// allocated ThresholdRule struct, preferring placement in s's segment.
+func (s LandmarkRule) NewThreshold() (ThresholdRule, error) {
+	s.Struct.SetUint16(0, 0)
+	ss, err := NewThresholdRule(s.Struct.Segment())
 	if err != nil {
-		return OpType_List{}, err
+		return ThresholdRule{}, err
 	}
-	err = s.Struct.SetPtr(0, l.List.ToPtr())
-	return l, err
+	err = s.Struct.SetPtr(0, ss.Struct.ToPtr())
+	return ss, err
 }
 
-func (s Stream) Window() Stream_window { return Stream_window(s) }
-
-func (s Stream_window) Which() Stream_window_Which {
-	return Stream_window_Which(s.Struct.Uint16(8))
-}
-func (s Stream_window) Exp() (ExpWindow, error) {
-	if s.Struct.Uint16(8) != 0 {
-		panic("Which() != exp")
+func (s LandmarkRule) Sigma() (SigmaRule, error) {
+	if s.Struct.Uint16(0) != 1 {
+		panic("Which() != sigma")
 	}
-	p, err := s.Struct.Ptr(1)
-	return ExpWindow{Struct: p.Struct()}, err
+	p, err := s.Struct.Ptr(0)
+	return SigmaRule{Struct: p.Struct()}, err
 }
 
-func (s Stream_window) HasExp() bool {
-	if s.Struct.Uint16(8) != 0 {
+func (s LandmarkRule) HasSigma() bool {
+	if s.Struct.Uint16(0) != 1 {
 		return false
 	}
-	return s.Struct.HasPtr(1)
+	return s.Struct.HasPtr(0)
 }
 
-func (s Stream_window) SetExp(v ExpWindow) error {
-	s.Struct.SetUint16(8, 0)
-	return s.Struct.SetPtr(1, v.Struct.ToPtr())
+func (s LandmarkRule) SetSigma(v SigmaRule) error {
+	s.Struct.SetUint16(0, 1)
+	return s.Struct.SetPtr(0, v.Struct.ToPtr())
 }
 
-// NewExp sets the exp field to a newly
-// allocated ExpWindow struct, preferring placement in s's segment.
-func (s Stream_window) NewExp() (ExpWindow, error) {
-	s.Struct.SetUint16(8, 0)
-	ss, err := NewExpWindow(s.Struct.Segment())
+// NewSigma sets the sigma field to a newly
+// allocated SigmaRule struct, preferring placement in s's segment.
+func (s LandmarkRule) NewSigma() (SigmaRule, error) {
+	s.Struct.SetUint16(0, 1)
+	ss, err := NewSigmaRule(s.Struct.Segment())
 	if err != nil {
-		return ExpWindow{}, err
+		return SigmaRule{}, err
 	}
-	err = s.Struct.SetPtr(1, ss.Struct.ToPtr())
+	err = s.Struct.SetPtr(0, ss.Struct.ToPtr())
 	return ss, err
 }
 
-func (s Stream_window) Power() (PowerWindow, error) {
-	if s.Struct.Uint16(8) != 1 {
-		panic("Which() != power")
+func (s LandmarkRule) RateOfChange() (RateOfChangeRule, error) {
+	if s.Struct.Uint16(0) != 2 {
+		panic("Which() != rateOfChange")
 	}
-	p, err := s.Struct.Ptr(1)
-	return PowerWindow{Struct: p.Struct()}, err
+	p, err := s.Struct.Ptr(0)
+	return RateOfChangeRule{Struct: p.Struct()}, err
 }
 
-func (s Stream_window) HasPower() bool {
-	if s.Struct.Uint16(8) != 1 {
+func (s LandmarkRule) HasRateOfChange() bool {
+	if s.Struct.Uint16(0) != 2 {
 		return false
 	}
-	return s.Struct.HasPtr(1)
+	return s.Struct.HasPtr(0)
 }
 
-func (s Stream_window) SetPower(v PowerWindow) error {
-	s.Struct.SetUint16(8, 1)
-	return s.Struct.SetPtr(1, v.Struct.ToPtr())
+func (s LandmarkRule) SetRateOfChange(v RateOfChangeRule) error {
+	s.Struct.SetUint16(0, 2)
+	return s.Struct.SetPtr(0, v.Struct.ToPtr())
 }
 
-// NewPower sets the power field to a newly
-// allocated PowerWindow struct, preferring placement in s's segment.
-func (s Stream_window) NewPower() (PowerWindow, error) {
-	s.Struct.SetUint16(8, 1)
-	ss, err := NewPowerWindow(s.Struct.Segment())
+// NewRateOfChange sets the rateOfChange field to a newly
+// allocated RateOfChangeRule struct, preferring placement in s's segment.
+func (s LandmarkRule) NewRateOfChange() (RateOfChangeRule, error) {
+	s.Struct.SetUint16(0, 2)
+	ss, err := NewRateOfChangeRule(s.Struct.Segment())
 	if err != nil {
-		return PowerWindow{}, err
+		return RateOfChangeRule{}, err
 	}
-	err = s.Struct.SetPtr(1, ss.Struct.ToPtr())
+	err = s.Struct.SetPtr(0, ss.Struct.ToPtr())
 	return ss, err
 }
 
-// Stream_List is a list of Stream.
-type Stream_List struct{ capnp.List }
+// LandmarkRule_List is a list of LandmarkRule.
+type LandmarkRule_List struct{ capnp.List }
 
-// NewStream creates a new list of Stream.
-func NewStream_List(s *capnp.Segment, sz int32) (Stream_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 2}, sz)
-	return Stream_List{l}, err
+// NewLandmarkRule creates a new list of LandmarkRule.
+func NewLandmarkRule_List(s *capnp.Segment, sz int32) (LandmarkRule_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1}, sz)
+	return LandmarkRule_List{l}, err
 }
 
-func (s Stream_List) At(i int) Stream { return Stream{s.List.Struct(i)} }
+func (s LandmarkRule_List) At(i int) LandmarkRule { return LandmarkRule{s.List.Struct(i)} }
 
-func (s Stream_List) Set(i int, v Stream) error { return s.List.SetStruct(i, v.Struct) }
+func (s LandmarkRule_List) Set(i int, v LandmarkRule) error { return s.List.SetStruct(i, v.Struct) }
 
-func (s Stream_List) String() string {
-	str, _ := text.MarshalList(0xcf7581f95c7adbb1, s.List)
+func (s LandmarkRule_List) String() string {
+	str, _ := text.MarshalList(0xc6f07f0e071f2c9a, s.List)
 	return str
 }
 
-// Stream_Future is a wrapper for a Stream promised by a client call.
-type Stream_Future struct{ *capnp.Future }
+// LandmarkRule_Future is a wrapper for a LandmarkRule promised by a client call.
+type LandmarkRule_Future struct{ *capnp.Future }
 
-func (p Stream_Future) Struct() (Stream, error) {
+func (p LandmarkRule_Future) Struct() (LandmarkRule, error) {
 	s, err := p.Future.Struct()
-	return Stream{s}, err
+	return LandmarkRule{s}, err
 }
 
-func (p Stream_Future) Window() Stream_window_Future { return Stream_window_Future{p.Future} }
+func (p LandmarkRule_Future) Threshold() ThresholdRule_Future {
+	return ThresholdRule_Future{Future: p.Future.Field(0, nil)}
+}
 
-// Stream_window_Future is a wrapper for a Stream_window promised by a client call.
-type Stream_window_Future struct{ *capnp.Future }
+func (p LandmarkRule_Future) Sigma() SigmaRule_Future {
+	return SigmaRule_Future{Future: p.Future.Field(0, nil)}
+}
 
-func (p Stream_window_Future) Struct() (Stream_window, error) {
+func (p LandmarkRule_Future) RateOfChange() RateOfChangeRule_Future {
+	return RateOfChangeRule_Future{Future: p.Future.Field(0, nil)}
+}
+
+type LandmarkTriggers struct{ capnp.Struct }
+
+// LandmarkTriggers_TypeID is the unique identifier for the type LandmarkTriggers.
+const LandmarkTriggers_TypeID = 0xe6ad72d296041770
+
+func NewLandmarkTriggers(s *capnp.Segment) (LandmarkTriggers, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return LandmarkTriggers{st}, err
+}
+
+func NewRootLandmarkTriggers(s *capnp.Segment) (LandmarkTriggers, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return LandmarkTriggers{st}, err
+}
+
+func ReadRootLandmarkTriggers(msg *capnp.Message) (LandmarkTriggers, error) {
+	root, err := msg.Root()
+	return LandmarkTriggers{root.Struct()}, err
+}
+
+func (s LandmarkTriggers) String() string {
+	str, _ := text.Marshal(0xe6ad72d296041770, s.Struct)
+	return str
+}
+
+func (s LandmarkTriggers) Rules() (LandmarkRule_List, error) {
+	p, err := s.Struct.Ptr(0)
+	return LandmarkRule_List{List: p.List()}, err
+}
+
+func (s LandmarkTriggers) HasRules() bool {
+	return s.Struct.HasPtr(0)
+}
+
+func (s LandmarkTriggers) SetRules(v LandmarkRule_List) error {
+	return s.Struct.SetPtr(0, v.List.ToPtr())
+}
+
+// NewRules sets the rules field to a newly
+// allocated LandmarkRule_List, preferring placement in s's segment.
+func (s LandmarkTriggers) NewRules(n int32) (LandmarkRule_List, error) {
+	l, err := NewLandmarkRule_List(s.Struct.Segment(), n)
+	if err != nil {
+		return LandmarkRule_List{}, err
+	}
+	err = s.Struct.SetPtr(0, l.List.ToPtr())
+	return l, err
+}
+
+func (s LandmarkTriggers) QuietPeriod() int64 {
+	return int64(s.Struct.Uint64(0))
+}
+
+func (s LandmarkTriggers) SetQuietPeriod(v int64) {
+	s.Struct.SetUint64(0, uint64(v))
+}
+
+// LandmarkTriggers_List is a list of LandmarkTriggers.
+type LandmarkTriggers_List struct{ capnp.List }
+
+// NewLandmarkTriggers creates a new list of LandmarkTriggers.
+func NewLandmarkTriggers_List(s *capnp.Segment, sz int32) (LandmarkTriggers_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1}, sz)
+	return LandmarkTriggers_List{l}, err
+}
+
+func (s LandmarkTriggers_List) At(i int) LandmarkTriggers { return LandmarkTriggers{s.List.Struct(i)} }
+
+func (s LandmarkTriggers_List) Set(i int, v LandmarkTriggers) error {
+	return s.List.SetStruct(i, v.Struct)
+}
+
+func (s LandmarkTriggers_List) String() string {
+	str, _ := text.MarshalList(0xe6ad72d296041770, s.List)
+	return str
+}
+
+// LandmarkTriggers_Future is a wrapper for a LandmarkTriggers promised by a client call.
+type LandmarkTriggers_Future struct{ *capnp.Future }
+
+func (p LandmarkTriggers_Future) Struct() (LandmarkTriggers, error) {
 	s, err := p.Future.Struct()
-	return Stream_window{s}, err
+	return LandmarkTriggers{s}, err
 }
 
-func (p Stream_window_Future) Exp() ExpWindow_Future {
-	return ExpWindow_Future{Future: p.Future.Field(1, nil)}
+type OperatorSince struct{ capnp.Struct }
+
+// OperatorSince_TypeID is the unique identifier for the type OperatorSince.
+const OperatorSince_TypeID = 0xefae2bdb491429a2
+
+func NewOperatorSince(s *capnp.Segment) (OperatorSince, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 0})
+	return OperatorSince{st}, err
 }
 
-func (p Stream_window_Future) Power() PowerWindow_Future {
-	return PowerWindow_Future{Future: p.Future.Field(1, nil)}
+func NewRootOperatorSince(s *capnp.Segment) (OperatorSince, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 0})
+	return OperatorSince{st}, err
+}
+
+func ReadRootOperatorSince(msg *capnp.Message) (OperatorSince, error) {
+	root, err := msg.Root()
+	return OperatorSince{root.Struct()}, err
+}
+
+func (s OperatorSince) String() string {
+	str, _ := text.Marshal(0xefae2bdb491429a2, s.Struct)
+	return str
+}
+
+func (s OperatorSince) Op() OpType {
+	return OpType(s.Struct.Uint16(0))
+}
+
+func (s OperatorSince) SetOp(v OpType) {
+	s.Struct.SetUint16(0, uint16(v))
+}
+
+func (s OperatorSince) Timestamp() int64 {
+	return int64(s.Struct.Uint64(8))
+}
+
+func (s OperatorSince) SetTimestamp(v int64) {
+	s.Struct.SetUint64(8, uint64(v))
+}
+
+// OperatorSince_List is a list of OperatorSince.
+type OperatorSince_List struct{ capnp.List }
+
+// NewOperatorSince creates a new list of OperatorSince.
+func NewOperatorSince_List(s *capnp.Segment, sz int32) (OperatorSince_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 0}, sz)
+	return OperatorSince_List{l}, err
+}
+
+func (s OperatorSince_List) At(i int) OperatorSince { return OperatorSince{s.List.Struct(i)} }
+
+func (s OperatorSince_List) Set(i int, v OperatorSince) error { return s.List.SetStruct(i, v.Struct) }
+
+func (s OperatorSince_List) String() string {
+	str, _ := text.MarshalList(0xefae2bdb491429a2, s.List)
+	return str
+}
+
+// OperatorSince_Future is a wrapper for a OperatorSince promised by a client call.
+type OperatorSince_Future struct{ *capnp.Future }
+
+func (p OperatorSince_Future) Struct() (OperatorSince, error) {
+	s, err := p.Future.Struct()
+	return OperatorSince{s}, err
 }
 
 type DB struct{ capnp.Struct }
@@ -731,12 +2574,12 @@ type DB struct{ capnp.Struct }
 const DB_TypeID = 0xa008ac86fde19106
 
 func NewDB(s *capnp.Segment) (DB, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 1})
 	return DB{st}, err
 }
 
 func NewRootDB(s *capnp.Segment) (DB, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 1})
 	return DB{st}, err
 }
 
@@ -774,12 +2617,28 @@ func (s DB) NewStreamIds(n int32) (capnp.Int64List, error) {
 	return l, err
 }
 
+func (s DB) NextStreamId() int64 {
+	return int64(s.Struct.Uint64(0))
+}
+
+func (s DB) SetNextStreamId(v int64) {
+	s.Struct.SetUint64(0, uint64(v))
+}
+
+func (s DB) DefaultRetention() int64 {
+	return int64(s.Struct.Uint64(8))
+}
+
+func (s DB) SetDefaultRetention(v int64) {
+	s.Struct.SetUint64(8, uint64(v))
+}
+
 // DB_List is a list of DB.
 type DB_List struct{ capnp.List }
 
 // NewDB creates a new list of DB.
 func NewDB_List(s *capnp.Segment, sz int32) (DB_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 1}, sz)
 	return DB_List{l}, err
 }
 
@@ -1025,12 +2884,12 @@ type MergerIndex struct{ capnp.Struct }
 const MergerIndex_TypeID = 0xf1223767bd770235
 
 func NewMergerIndex(s *capnp.Segment) (MergerIndex, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
 	return MergerIndex{st}, err
 }
 
 func NewRootMergerIndex(s *capnp.Segment) (MergerIndex, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
 	return MergerIndex{st}, err
 }
 
@@ -1068,12 +2927,20 @@ func (s MergerIndex) NewItems(n int32) (MergerIndexItem_List, error) {
 	return l, err
 }
 
+func (s MergerIndex) FirstCStart() int64 {
+	return int64(s.Struct.Uint64(0))
+}
+
+func (s MergerIndex) SetFirstCStart(v int64) {
+	s.Struct.SetUint64(0, uint64(v))
+}
+
 // MergerIndex_List is a list of MergerIndex.
 type MergerIndex_List struct{ capnp.List }
 
 // NewMergerIndex creates a new list of MergerIndex.
 func NewMergerIndex_List(s *capnp.Segment, sz int32) (MergerIndex_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 1}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1}, sz)
 	return MergerIndex_List{l}, err
 }
 
@@ -1094,91 +2961,222 @@ func (p MergerIndex_Future) Struct() (MergerIndex, error) {
 	return MergerIndex{s}, err
 }
 
-const schema_91f0805429cab961 = "x\xda\x8cVoh\x1be\x18\x7f\x9e\xf7\xbdK\xd2m" +
-	".\xb9]`\x1a\x9dqc\xc2Z\xed\xec\x1f\x8b\xac\xa8" +
-	"\x9d\xd2B3Z\xd6\xb7\xad(\xa3\x83]\x93\xdb\x16\xec" +
-	"\xe5n\x97\x8bI\xa6\x1b+\xac\xdd:60\xa0(\x88" +
-	"\xe0'?)\"2dJ\xa9(\xfaA\x9d\xff@\x14" +
-	"\xa6h\xf7A\xbfj\x11EDO\x9eKr\x17n\xc9" +
-	"\xf4S\xde{\xf8\xf1<\xbf\xf7\xf7\xfb=w\xe9\x93\xf9" +
-	"~\xd6/\xff \x03\x88Q9\xe2\xa6\x86.\xce\xde\xb3" +
-	"\xbc\xb6\x0c\xcav\xe6jW>\xee\x9e=\xf3K\x0d\x00" +
-	"\xd5\xaf\xd9\x86\xba\xce\xa2\x00\xea\xf7\xec\x05@\xf7\xdbs" +
-	"\xfb\xee\xfa\xe8\xf4\xdc9\x10\xdb\xb1\x05)E\x01\x06w" +
-	"\xf2aT\xfb9\x81{y\x19\xd0\x8d\xd4\xd6\xff^~" +
-	"-\xf6\x0a(\xdb1\xc0\xcaH\x88\x15\xfe\x95\xfa\xbc\x87" +
-	"\xad\xf1\x11@\xf7\xb3j\xff7\x17.\x9f|\xab]\xe3" +
-	"\x0f9C\xf5K\x0f|\x95\xbf\x01\xe8\x1eY\xf8\xee\xf4" +
-	"\x8fc\xd9\xf7\x08,\x85\xc0y)\x85j\x95\x8ejI" +
-	"\xfa\x19\xd0\xbd\xfd\xdd\x0b\xef\xef\xbe\xb6\xf1\x01\x88;\x91" +
-	"\xb9o^;9\xf7\xe7b\xe9sx\x8cE\x91\xa14" +
-	"\x98\x91{\x10P\x1521\xae\xf6\xad\xdd=|p\xcb" +
-	"'\xd4\x98\x87\x1a\xbf.oBuU\xa6\xc6Wdb" +
-	"\xb1\xfe\xd2\xabG\x1f\xfc\xe3\xedO\x09\x8c!\xb0\x88l" +
-	"BU\x8b\x10\xf8p\x84\xee\x17\xbf\xbev\xe0\xde\xc9\xdf" +
-	"\xaf\x86\xee'\x93\xb6\x83\xa7\"\x87P\xady\xe8K\x11" +
-	"\xe2\xec\xb3l\x83V\x97\xa2\x1bj-\xea\x81\xa3\xc4\xe3" +
-	"\xaf\xd5\xc3+\xb5\xa1\x87\xbf\x08\xa9\xe1\xe9<\xb8#6" +
-	"\x8dj\x7f\x8c\x8e\xbd\xb14\x02\xbaC\xac\xbcz\xec\x81" +
-	"]\xbf\xb6qe\xf0\xa1\xae\x14\xaa\x93]\xd4;\xd3E" +
-	"\xb4\xa5m?\xbd\xf3\xf2\xe5\xa7\x7fkga\xb5\xeb\xba" +
-	"\xba\xe4a\x17\xbbF\xa0\xd7\xb5l\xd31\x8b\xf7\x15Y" +
-	"\xc904\xbb\x9a\x9b\xdf\x9b\xd5\xac\x825|0m\xcd" +
-	"V-}\x0aQ\xdc\x8a\x0c@yd\x00\x00Q\xd9\xb7" +
-	"\x0b\x00\x99\xd2OO\\\xe9\xa6'I\xd9I?\xb2r" +
-	"[\x0f@:k\x96\x0aN\xb4X2\xd2\xf3\x0b\xa6i" +
-	"D\xb3F1jh\x95\xf8Q[?\xe1O\xe3\xa1i" +
-	"\x93\xba}L\xb73\x85\xdc\x88^\xc98\xbaAcc" +
-	"\\\x02\x90\x10@\xe9\xee\x01\x10\xbb9\x8a>\x86\x0ab" +
-	"\x12\xa9\xd8K\xc5=\x1c\xc5\xfd\x0c\xe3\xc5r>\x872" +
-	"0\x94\x01\xe3\xd9\xb1\x82\xff\xd0\xf1~\xa3\xf8(\x0d\x91" +
-	"\xfc!\xb7L\x03\x88-\x1c\xc5\x1e\x86n\xd1\xb1u\xcd" +
-	"\xc8\xe4\x00\x8b\xb8\x15p\x8a\xa3\xd7pkK\xc3\xf0\x15" +
-	"\xc6u\xcd\"\xea@m\xb7\xf8m\xc7\x06\x00\xc4~\x8e" +
-	"b\x82\xb8\xb3:\xf7\xcc\x01\x001\xceQ\xcc2T\x18" +
-	"Oz\x02\x0bBNp\x14O0L?\xa5-\x94\xf4" +
-	"\x96K\xe4M;\xefT\x81\xd4\x06\x86\x12`:_\xc8" +
-	"\xe9\x95\xe6SGVSfY\xb7\x1f\xcf\x17r\xdc," +
-	"\x13\xb1\x84OL\xdb\x06 \xe68\x8a\xe3-\xa2\xeaT" +
-	"<\xc2Q,\x101V'\x96\xa7b\x8e\xa3\xb0\x18*" +
-	"\x9c'\x91\x03(\x06\x15\x8fs\x14\x0eC\xb4\x9aL\xf1" +
-	"\x84\x7f\xb2\xfdS\xf1\x063\xc2,g<\xb9\xf7\x96\xf3" +
-	"\xf1B\xce,\x93\xf3w\xb8.\xd6\xa7S\xc4\x9a\xe6\xef" +
-	"\xc0\x7f\xa8L\xf3{\x07\x02\xfb\xa3z\xc5\xc2D\xb0\xda" +
-	"\x80\x98\x00L[twL\x04/\x9ez\xbd#\x8bQ" +
-	"\xcd\xd1f\xb5\xf9\x05\x1dn\xeaaC\xaa\x0c\xf1\x1a\xe5" +
-	"(\xa6Z\xa4\x9a\xdc\x15\x18[\xdf\x04\xdc\x0c\x0c7\x03" +
-	"\xd2\x0e\xf8\xe7b\xc9h\x9e;\xb2\x19\xabX\xe4\x9bY" +
-	"\xae\xb3i\x09*\x05?\xc6Q$\x19\xc6\xe7\xb5\xa2~" +
-	"C+)\x1c\x02\xaaOh\x85\x9c\xa1\xd9O\xb6\x0fC" +
-	"\xaa]\x18RA\x18\xb0\x99\x85C\x0d\xdb\xcfR\x16\x1a" +
-	"^,\x0e\x03\x88g8\x8a\x17\x19r\xc7\xb7\x9b;A" +
-	"\x82\x9d\xbc\xa1\x17\x1d\xcd\x00n\x857j\xc4\xcb\xba_" +
-	"\xdd\x1c\xda\xb3\xf0\xe2\xce\xa4\xbd\xb0\x84\x1cJ\x05\x0e\xf9" +
-	"\x06M7\xbc\xc8\x11}l\xf9\xa6(\xda00\x1e\xbc" +
-	"1\\\xd3\xd2m\xcd1\xed\x96u\x8f\x07\x9fW@\x8f" +
-	"f\xd9s\xe3\xe6\"\xcf\xd4\x8b\x9eq\xe8i\x9c\xf49" +
-	"\x9e\"\x8e\x95\x86tM\x92\x8b\xa9\x86t\xe7[R\xb4" +
-	"D\xc53\x1c\xc5\xc5\x96\x85[\xa1\xe2Y\x8e\xe2Y\x86" +
-	"(%Q\x02P.\x91\xf0\xe79\x8a\xe7:\x09\xcf\xb3" +
-	"A5\xebWGL\x8b\xc2\x8e\x89\xe0\xdb\xf9\x1f\xbb\xe1" +
-	"\xbf\xa0\xb9^\x09\xc5q\xa0\x11\xc7\xdd\x0c\xd3yG7" +
-	"|\x09\x13\xc1\x1f\x8f\xba\x84\x1d=\x1d\x8f\xea\x9a\xf5\xff" +
-	"\xdb\xfa\x7f;\xeam\xff\x0d\x00\x00\xff\xff\x89 #\xe3"
+const schema_91f0805429cab961 = "x\xda\x94Y}\x8c\x14U\xb6?\xe7\xde\xfe\x9a\x99\x1e" +
+	"\xaa{\xaa\xf9\x98~\xf0\xda!\x90\xc8\x08\x0a\x0cD%" +
+	"\xf0\x06\x07x\x01\x03\x8f\xa9\xe9Q\xde#\x98X\xd3}" +
+	"{\xa6|\xfdEu53\xc3{\x08\xf8\x1e ,\xc6" +
+	"\xb0_\xeejL\xcc\xfeaV\xa3\xab\xeeJ\x80U\x02" +
+	"\xec\xaa\x11\x14e]\xcd\xba\xab&jv\x15\x8cf\x85" +
+	"\xa0\xbb\xae`m\xce\xed\xea\xaa\xa6\xa7\xc7\xc1\xbf\xa6\xea" +
+	"\xd4\xaf\xcf\xd7\xbd\xe7w\xce\xbd3\xbf=\xb4\x9c-\xf0" +
+	"\x1fj\x01\xd0\xee\xf5\x07\xec\xf8\xe2\xfd\xfd\xd7\xed>\xb6" +
+	"\x1b\xa2S\x99\xad\x1f95\xa7\x7f\xc7\xe7\x07\x00P=" +
+	"\xe1\xbf\xa0\x9e\xf6\x07\x01\xd4\x93\xfe\x07\x00\xed\xb7\xf7\xdc" +
+	"|\xcdKwo\xda\x03\xdaT\xacA\xfa\x82\x00]\xad" +
+	"\x81%\xa8\xce\x08\x10\xb8=0L\xe0\x1d\xb1e\x87\x8e" +
+	"\xcc\xbb\xbf\x11\xb8\x1chCu\x97\x04\xef\x94\xe0\xff\xf8" +
+	"]\xef\xa2\xa7?\xf8J\x82\xb1\x0e\xfc^\xa0\x13\xd5\xcf" +
+	"$\xf8\\\xa0\x1b\xd0\xbe}\xff\xe6\xf4\xef\xcf\x88\x1f4" +
+	"\xd2\xdc\x14\xecD\xb5=H\xe0\xc9A\xd2\x1c8\xf0\xc1" +
+	"\xe5\xddO\x84\x1e\xa9\x03\xfb\x91 \x9b\x83o\xa8\xdb$" +
+	"x4\xf8\x14\xa0\xbdv\xf5\x94\xb3kW\x9d~\x94\xc0" +
+	"\x81\x1a0'\xd5\x0bB=\xa8\xde\x12\xa2\xc7e!\x1b" +
+	"\x01m\xdf\xcf\xb7\x05\xfe\xf4\x90\xf5X}\xe2\xba\x8c\xe6" +
+	"\x1eTG\x9bIq\xb9y;\xa0\xfd\xda\xb6\xf4S\xcf" +
+	"=Xz\xba\x91\xcb\x8f57\xa3zD\x82\x0f6\x93" +
+	"\xcb\xaf\x8d.\xf8\xc3\xbe\x83[\x7f\xd5\x08<\xb9\x85\xa1" +
+	"\xda\xd1B\xe0\x19-\xe4\xf2#\x93_\x7f\xfc\xe3\xf2\xf1" +
+	"C\x04\xe6W\x82\xd5#-\xffP_\x94\xd8\x13\x12{" +
+	"~\xcdgGN\xe5\xef9<f\xa1\xff+\xfc\x89*" +
+	"\xc2\x04\xd4\xc37\x02\xda\xa7\xf6\xbc\xfcE\xff\x0d\xffw" +
+	"\xac\x81\xd2.\x11\x8e\xa3Z\x96\xe0\xcda\xd2zg\xf6" +
+	"\xdd\xbb\xdf_\x95:N`_\xbd\xbb\xadqTg\xb7" +
+	"\x12\xb8\xa3\xf5c@\xfb_\x9e\xdb\xf7\x9bY\xef\\\xf8" +
+	"-h\xff\x8a\x01\xfb\x99w\xb6n\xfajg\xf9u\xb8" +
+	"\xad)\x88~\xf4u}\xd1\xda\x89\x80]\x97[\x13\x08" +
+	"h?87\x11\x9c\xb4\xfd\xf3\x97\xeav\xc5*\x0cr" +
+	"\x80\xae\xa82\x13\xd5\x0eE\xa6B!GF\xe7\x1f\x9b" +
+	"\xbdd}\xf8\x95F^\x1fU\x9aQ=-\xc1'%" +
+	"\xf8\x83\x87\x1e\xcd,\xfd\xdb\xa1W\x1b\xed8=\xd2\x8c" +
+	"\xea\xe6\x08\x81s\x11\xdaqMC#_\xbe\xb9w\xf8" +
+	"t#\xf0}\x91\x1eT\x1f\x96\xe0\x9fJ\xb0\xf2\xe1\xb1" +
+	"[\xe7\xae\xfbR\x82\xfd5\x9b\x88\x11\xfaHd#\xaa" +
+	"\xa7\x09\xddu2r?\x05\xe9\xe6\xa0n\xcf5\xc9m" +
+	"\xd9vA\xdd\xd5F\xe8\x9dm{8\xa0\xfd\xf5\xd1;" +
+	"\xf6\x1eX\xfcog\x1a*\x1f\x9d\xd2\x87\xea}S\xe8" +
+	"q\xef\x14\xa9\xdcz\xc5\x9f~nd\xe5\x87\x10\x9d\x8a" +
+	"uh\xb5c\xda'\xea\xbci\xf44g\x1am\xbb\xe2" +
+	"T\xdf\x8f\xdf0\x9f\xfc\xa8.HY)]\xbb\xa6\xf5" +
+	"\xa0\xfa#\x89> \xd1\xf3b\xcb\x9e\x9c\xb3\x82\x9fm" +
+	"\xa4\x19\xdb?Q[\xdb\xe9\xa9\xa9\x9d\xb0\x1d\xa7\xde\xdc" +
+	"\xbdl\xce\x03\xe7\xea\xb0R\xf1h{\x1c\xd5\xbd\x12\xbc" +
+	"\xab\x9d\xd2\xf7\xb39\xb15\xef\\\xf7\x8b\xbf6\xda\xfd" +
+	"\x07\xdb;Q}Q\x82OH\xcd\x8b\xd9\xf0\xd1\xc1\x1b" +
+	"g\x9eo\xe8sG<\x8e\xea\x828\xa1\xe7\xc5\x09}" +
+	"\xe0\xad\x9b\xbe\xd7\xfc|\xfaB#?\x1e\x8e\xf7\xa0\xfa" +
+	"\xa4\x04?\x16'?|m\x1f\xfd\xfa\xe1\x83\xffs\xb1" +
+	"\x01X}/\xfe\xa1zNb\xff\x12\xef\x86yv\xd1" +
+	",X\x85\xd2\x0d%V\xce\xe5ts4=p}J" +
+	"/\xe6\x8bK\xd6'\x8a\xfd\xa3E\xd1\x8b\xa8MC\x06" +
+	"\x10\xbde!\x00b\xf4\xe6\x99\x00\xc8\xa2\x0b\xe8\x8dG" +
+	"\xe7\xd0\x9b/\xdaA\x7f\xfc\xd1\xf6N\x80D\xaaP\xce" +
+	"[\xc1R9\x97\x18\xc8\x16\x0a\xb9`*W\x0a\xe6\xf4" +
+	"\x11%c\x8a\xcd\xae5^gm\x9d0\x07\x85\xb9&" +
+	"\x9f\xee\x16#k,\x91#\xb3!\xee\x03\xf0!@t" +
+	"N'\x806\x8b\xa36\x9fa\x141\x86$\x9cG\xc2" +
+	"k9j\x8b\x18*\xa5a#\x8d~`\xe8\x07TR" +
+	"\xab\xf2\xee\xcb\xb8\x167\x18\xf9ta\xb8\xdf\x10h\xd6" +
+	"\x19[\xd8\xc8\xd8\x12\xcfX%\xc2\xaa\x81\xee\xac\xc8\x0f" +
+	"ZC\x13\xda\xbb-od\x0afn\x83\xa1\x90]2" +
+	"\xe9sM\xb6\x92\xf6\x10G-\xc6\xaeZ_\xff\x90)" +
+	"JC\x85lZ\xe9+g\xc5\xd5\x84\xb0\xb0&\x84l" +
+	"aX\x98\xd8\x02\x0c[\x00\x13\xe5b\xd1{\x1bwG" +
+	"\xac\xc4\x1e2\x13v\xcd\xac\xea\x03\xd0Vr\xd4\xeed" +
+	"X\xb5r\xc7]\x00\xda&\x8e\xda\x10\xc3(\xc3\x98\xdc" +
+	":b\x1f\x806\xc4Q\xb3\x18\xda%\xcb\x14znM" +
+	"\x1a\xb0\x84\x93\x00{9\xcaX'\x01\xday1b%" +
+	"-S\x80B\x007\x05i\x91\xd1\xcbY\xab\x0f\x85%" +
+	"\xf2\x96Q\xc8\x03\x8cI\x8f\xaf\xce\xd9\xa4\xb4\x92\xb4t" +
+	"\xcb(YF\xaa\x04\xe4\xfa,\xd7\xf5\xcf~\x09\xa0}" +
+	"\xceQ\xbbT\x93\xa1\xaf\x1e\x07\xd0.qL\x86\x90\x9c" +
+	"g\xd2y\xd5\x8f}\x00I\x1frLF\x90!\xf2\x18" +
+	"r\x00\xb5\x15M\x80d\x98\xc4\xd3\x08\xee\xc3\x18\xfa\xa8" +
+	"u\xe3F\x80d\x8c\xe4\xd7\x90\xdc\xcfc\xe8'\x9e\x97" +
+	"\xf8\xe9$\xbf\x96\xe4\x01_\x0c\x03\x00\xeal\xbc\x0b " +
+	"9\x8b\xe4\xf3\x91\xe1\x82\xe0r\x8c\xc9B\x9d\x87\x03\x00" +
+	"\xc9\xb9\xf4\xe1&\xfaA\x88\xc50\x04\xa0.\x96\x8a\x16" +
+	"\x91|92\xb43\x86Y\xb2n1M4\xb6\xe8\xd9" +
+	"~#'\x12%K\xcf\x15\xdd\x0ceu\xf9\xd9@\xe7" +
+	"sI\xb9\xe2s\xbe\x9c\xbb]\xcf\x96\x05-G\x130" +
+	"l\x02\xb4\x8d\xbc%\xcc-z\x16\x12\x94\xc0\x12F\xbc" +
+	"V\x0d\x88\x11@{\x0b\xfd$i\xe9\xc0\x1b~\xce\x97" +
+	"s\xeb\xcb\xd6\xfa\x0c$\xd6\x9bia\xba\x8a\xf3\xe5\xdc" +
+	"J\xa3\x94\xd2A1\xd3\"\xed\x8a\x87\xf4\x12y\xb8\x05" +
+	"\x82z\xb6\x84\x08\x0c\x11\xd0\x1e\xd0K\"k\xe4\xc5\xf8" +
+	"^\x8c\xb7\xf8\xd2\xb6\xb4\xdc[\xc8\x1a\xa9Q\xb9\xf8\x95" +
+	"\xad\xb8\xb8\xc2bT\xcf\xc8\xa2\xb3;%\x8b\xcd\xe8\x91" +
+	",6Y\xd2WV\xcf\x15\xbbMq\x97HYJ\xda" +
+	",\x14\xb7\x9b\xa2@\xaa\x94L!\x9b\x1e\xb7\x1c\x93\xc6" +
+	"`N\xa7J\x04\xa8\xab\xc5%\x8dj\x91*g.G" +
+	"\xed&\x86\xdd%\xfai\xc9-\xbf\x9c\x91\x1f\xb3 \xe3" +
+	"Y]-\xf4\"\xd1%\xd4\x15&\x95\xfar\x8e\xdaZ" +
+	"\xb2\xc9*6\xd7\xdc\x0a\xa0\xad\xe6\xa8\xf5\xd3\xe6\xe6\x95" +
+	"th\x84\\\xcbQ\xfbO\x86\x09\xb9\xa85\x95e\x14" +
+	"L\xc3\x1a\x05\xca\x0d0\xf4\x01&\x8c|Z\x8cT\xdf" +
+	"\xc6%\x8a\x0d\xdd\"\x9b)\x98\xe9os\xcaI\xc4\x9a" +
+	"N\x87Bz\xbd\x8a\x8b\xae\x8b{\x9e:d\xeb$B" +
+	"\xc9\x09=_\xcd\x14\xcf-\x9c\x98\xb3\x14\x91\xd2G\xe5" +
+	"\x8aH\xd5\xd1\xca\xf27\xb9\x8dJ\xb1\x8c\x9c\x18\x7fU" +
+	"\xad\x82)V\x14\xf2\x19n\x0c\xd6E\xb3\xb5\xc6\xf1j" +
+	"4\xeb\xfa\xbcl\xba\xd1\xdcv\x0f\x80\xd6_aI[" +
+	"\xe8\xa9\xa1\x9er&\x03\xdd\xc2L\x1a[Em\x19\x92" +
+	"\\\x00\x9a\xaelX\xb6\xa8R/\x0aS\xf6G\x98\xb0" +
+	")\xf4\x12\xadSg\xe3\x95\x16\x13q\xfd\xd5\xdbjx" +
+	"\xb9\xea\xaf \xe1\x9d\x1c\xb5l\x8d\xbf\x06\x09\xd3\x1c\xb5" +
+	"\"\xc3(\xaf\xb0]4\xd7\xe618\xba\xe4\x81\x9b\xdd" +
+	"'\xd7k,M\xe8e\x85\x9b\xaf\x1f\xae\xb4B-\xc6" +
+	"}\xd3m\xdbi\x15\xdbf\x02h#\x1c\xb5\xffg8" +
+	"\x03\xbf\xb1\xb1b\x7f'm\x9e\xff\xe5\xa8\xdd\xcbp\x06" +
+	"\xbbL\xe2\x00@tW\x0f\x80\xb6\x83\xa3\xb6\x9f\xe1\x0c" +
+	"~\xc9\xaePgt\xefF:\x1er\xd4~\xc8p\x86" +
+	"\xefk\x12\x87\x00\xa2\x07H\xc9~\x8e\xdaO\x18\x06\xc5" +
+	"H\x11#\xde(]a\x94DQ\xf6\xc5\x88w0\xa8" +
+	"\xc8\xb7\x97+\xdd\x1b#\xdeq\xaf\xcaA\x85\xech\xbe" +
+	"\x903\x80\xebY\x8cxs\x9a\xa3\xd0\xd2\x07\xb2\x02#" +
+	"\xde\x1cYG]\xf5\xb9Y\xab\xe7\xd39\xdd\xfc\xef\xbe" +
+	"\xa0\xd3\xd5\xc3\xdc\x17\xb6\xed+\xfbm/\xc3V\xfc\xc6" +
+	"v6\xddB\xafZZ\xd9e\xdb)l\xea\xc3\xbd\x1c" +
+	"\xb5M\x0cm\xcb\x99\x15\x00\xd3\x18\xf1\xce\xa0\x8e\x8b\x92" +
+	"\x7f0\xe2\x1d\xf4\x1c\x17M\xdd\x12\xeb3+\x86@\xd1" +
+	"\xf3\x83\x14\x83{\x94\x98 \x86\x95\xba\xa5\xf7\xeb\x03U" +
+	".\x9c\x88\x02f6\xa2\x80\x99c(\xc0)u\x9a%" +
+	"\xdd\xe7R97\x86\x02\xea\xbdY5R\xac\xccz\x00" +
+	"uSW\xa77u)\xd4l\xc6\xa8\xaa\xef+}N" +
+	"F(\x1f\x92\xeb\xeb\x14\xf6x\x0a\xb7\xe7\xf4\x11\x82O" +
+	"\xa8\xb3\x97\xe4\xd5U\xaf)\xdd\xe9\xae\xda\x83D\x87O" +
+	"s\xd4\x9e\xaf\xc9\xda\x11\x12>\xcbQ;\xce\x10\x9d\xa4" +
+	"\x1d\xa5m\xff<G\xedm\xaa\\\xa7r\xde\xa2\xfes" +
+	"\x86\xa3\xf6)\x0d);\xe4\x90\x12=G\xbd\xe0,G" +
+	"\xed\xa27\xa1D\xcf\x93\xcaO9j\x7f\xf7\xc6\x93\xe8" +
+	"\x17qoP\xe2\x96[\xdb\xdc\xf2\xa8\x8b\x18\x94F\x0e" +
+	"\xe0\xc5\xfa\x89\xae[\xb6\x14W\xdaR\x91R.r\x05" +
+	"K\xa4\x01\xa0\xda\xedy\xca\xd3\x9d\x12c(\xa4\x9e\xd7" +
+	"\x93\x09\xc9!\x94\xa8\xe5\xd5D\xa9\xcf`\x1c \xf9\x04" +
+	"MF\x87\xd1\x1dI\xd5\x83r\x80{\x96\xc4gH\xcc" +
+	"\xb0\xe6,\xaf\x9e\xc6%\xc0\xa2\x9cU\xa6\xb7\x83\xb8\xb1" +
+	"\x8a=.\xa7:\x7fez;\x8a\x0b\x01\x92\x87I\xfe" +
+	"Bu\xaak\xa2\xa3\x1cn\x05H\x1e'\xf9\xbb\xd5\xa9" +
+	"\xae\x19@\xfd#\xee\x03H\xbeK\xf2\xb3$\x0f\xb2\x18" +
+	"\xb6\xd0\x99\x0b\xbf\x0f\x90<K\xf2\x8br\xa8\x0b\xc40" +
+	"\x0c\xa0\x9e\x97\xf8\x8b\xc8\xb1\x8f1\x8c6\x05b\xd8\x0a" +
+	"\xa0^\x96\xb3\xde%\x82\x87H\xde\xec\x8f\xe1$\x9aI" +
+	"\xd9\x12\x80>\xc619\x9d\xc4-\x81\x18*t{\xc5" +
+	":i\xf6$\xf95$\x0f\x07c\x18\xa1\xd9\x93\xe0\xc9" +
+	"i$_D\xf2V_\x0c\xa3\x00\xea\x02F\x99\x99O" +
+	"\xf2\xa5$\x9f\x14\x8aa\x1b\x80z\xb3\xc4/\"\xf9r" +
+	"\x92+\xfe\x18\xaa\x00\xea2F\xee,%\xf9j\xc6\x90" +
+	"{g.\xbbP\x14\xa6n\x15\xcc\x9a\xa9^\xf1n\xe7" +
+	"\x00\xe5n\xa844\xbb\xe4L\xe5@\x8b\x1e\xf1n\xae" +
+	"\x1c>JS\xcbF\xc5\xbb\xf2\x01D\xa5\xd6Dw)" +
+	"i\xe4S\xa2j'\xe2\x9d\xbb+v\xec\xacSN\xd8" +
+	"o\x1a\x83\x83\xc2,\x01`\xc4\xbb$p\xe8\xcbE\xf5" +
+	"9\xe7\x0a\xcc{\xe18\x13$\xba#$\xa0\xe2]\x9a" +
+	"9\x1e9\x93\xe1\x06H\xc8\xc00\x04\x0cC\x80\xdd\x19" +
+	"Cd\xd3%\xcf?\xf7\xc2\xa1\xe2\x9f\x92\xd7s\x02\xc3" +
+	"\xc00L\xe7G}@dk\xc0\xee\xbd\x87\x13\x8c\xe9" +
+	"x\x07\x9e{\xdd\xa9B>c\x0cb\xc4\xbb\xedrb" +
+	"rS\x9bH\x95\xe8\x14\\?@6d\x9fdE(" +
+	"Y\x12\xbf+\xf9\xb8\x94}\x94\x84\x879j/\xd4\xcc" +
+	"\x0d'\xe2\x0e%\xbd\xcc\x10}\x15\xf2y\x91\x18\xe98" +
+	"G\xedU*$\xac\x90\xcfI\x12\xbe\xc0Q{\x9fa" +
+	"G\xc0i\xef\xef\x91\xf0m\x8e\xda\x9f\xc7#\x9f\xc6\xb4" +
+	"\xd1](R\x0f\xc2\x88w\xafV\xc9\xcf\x98\x95\xa9\xfd" +
+	"N;\x94f|\x91v\x8f \xe3\xd1\xcfZ\x85\x16\xed" +
+	"j.)j\x0e\xddW,\xbb3l;o\xe3\xaeO" +
+	"\xb51\xb8\x1by\x9c\x83\xfer\xef\x04\xbel\x00@[" +
+	"\xcaQ[\xcd0a\x96\xb3\xa2&\\\xf7V\xd2\xd9[" +
+	"\x9b\xcb\x86\xb0z\x85\x09A\xa3\x90\x9e\x90t\xff]\xa1" +
+	"\xec]M\xd4\xd5\xe3\xcd\xea\xba\xa8\xaf\x8a&\xc6\xbf\xf2" +
+	"\xa0\x99\xa2\xa6E\xd6t\xde\x85N\xe7\x9d\xc50a\x19" +
+	"\xc2\xac\x89\xd9\xbd\xcc\x9f@\xfbz\xc7\xb5\xa4B\xe4R" +
+	"\x17e|\x82C\x1c/\x14\xeb\x02Qj;\xa372" +
+	"O|\x03\xc6\xc5\xc8w\\d\xc3\x12\xb9\xda\x80\xab\xff" +
+	"\xeap\x02\x96\x17\x04+\x92\x16\x04u\xd3\x9a\xf0\xe2\xa4" +
+	"\xd7\x99g\xf5\xac35\xd5e\x9a\xe6\xca0G\xedZ" +
+	"\x86v\xaa 2\x19#e\x80\"\xf2\xd6\x98\xdb\x9c\xf1" +
+	"\xb6\xd1\xea\xa0\xd0\x8b\xdf\xb2~u\xe1\xb8\xffR\xa8\x84" +
+	"\xf3\xcf\x01\x00\xed(F]"
 
 func init() {
 	schemas.Register(schema_91f0805429cab961,
 		0x86bf862b548c351a,
 		0x875c7ec6203987d8,
+		0x8f2db9b73d1480d8,
+		0x8ff9e1b03450d14e,
+		0x9365d0d364718c56,
 		0xa008ac86fde19106,
+		0xa6cd454ce816484c,
+		0xa9749bda067da804,
+		0xb0739abbaf647dce,
 		0xb37ab58ad73179ce,
+		0xb7c075e7aacf15a0,
+		0xb8826ecab9ed49f1,
+		0xbf832f54f5c887ca,
 		0xc06345e07edc6c60,
 		0xc3f2db24c28abb1b,
+		0xc6f07f0e071f2c9a,
 		0xcb0c4f3a25bf3079,
 		0xccb7f73c66a69be1,
+		0xcd7789d4f6786809,
 		0xcdf64d2c4abfe20f,
 		0xcf7581f95c7adbb1,
 		0xd03e3591895dbdfb,
+		0xe24478bb6405cb74,
+		0xe6ad72d296041770,
+		0xe8034329ad3d142d,
+		0xe997293d86d4ca21,
+		0xefae2bdb491429a2,
 		0xf1223767bd770235,
+		0xf264bc0a8b38d591,
 		0xf47bb59dbae61204)
 }
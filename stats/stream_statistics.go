@@ -1,25 +1,49 @@
 package stats
 
+import "summarydb/protos"
+
 type StreamStatistics struct {
+	// Whether a value has arrived, the arrival timestamps are unset until
+	// then.
+	HasArrivals           bool
 	FirstArrivalTimestamp int64
 	LastArrivalTimestamp  int64
 	NumValues             uint64
 	IntervalStats         *Welford
 	ValueStats            *Welford
+	// Statistics of the values appended outside landmarks, which the
+	// landmark triggers compare new values against.
+	BaselineStats *Welford
+	// Values arriving with a timestamp before the latest one, and those of
+	// them which were dropped or rejected, left out of NumValues.
+	NumOutOfOrder uint64
+	NumDiscarded  uint64
 }
 
 func NewStreamStatistics() *StreamStatistics {
 	return &StreamStatistics{
+		HasArrivals:           false,
 		FirstArrivalTimestamp: -1,
 		LastArrivalTimestamp:  -1,
 		NumValues:             0,
 		IntervalStats:         NewWelford(),
 		ValueStats:            NewWelford(),
+		BaselineStats:         NewWelford(),
+		NumOutOfOrder:         0,
+		NumDiscarded:          0,
 	}
 }
 
 func (stream *StreamStatistics) Append(timestamp int64, value float64) {
-	if stream.FirstArrivalTimestamp == -1 {
+	stream.BaselineStats.Update(value)
+	stream.AppendLandmark(timestamp, value)
+}
+
+// AppendLandmark counts a value kept by a landmark, which leaves the
+// baseline as it was.
+func (stream *StreamStatistics) AppendLandmark(timestamp int64, value float64) {
+	if !stream.HasArrivals {
+		stream.HasArrivals = true
 		stream.FirstArrivalTimestamp = timestamp
 	} else {
 		interval := timestamp - stream.LastArrivalTimestamp
@@ -31,3 +55,112 @@ func (stream *StreamStatistics) Append(timestamp int64, value float64) {
 	stream.NumValues++
 	stream.LastArrivalTimestamp = timestamp
 }
+
+// AppendLate counts a value folded into the past, which leaves the arrival
+// intervals as they were.
+func (stream *StreamStatistics) AppendLate(value float64) {
+	stream.BaselineStats.Update(value)
+	stream.ValueStats.Update(value)
+	stream.NumValues++
+}
+
+func (stream *StreamStatistics) CountOutOfOrder(discarded bool) {
+	stream.NumOutOfOrder++
+	if discarded {
+		stream.NumDiscarded++
+	}
+}
+
+// OutOfOrderRate is the fraction of the values which arrived out of order.
+func (stream *StreamStatistics) OutOfOrderRate() float64 {
+	arrived := stream.NumValues + stream.NumDiscarded
+	if arrived == 0 {
+		return 0
+	}
+	return float64(stream.NumOutOfOrder) / float64(arrived)
+}
+
+// Copy returns a deep copy, which is safe to read while the original
+// continues to be updated.
+func (stream *StreamStatistics) Copy() *StreamStatistics {
+	return &StreamStatistics{
+		HasArrivals:           stream.HasArrivals,
+		FirstArrivalTimestamp: stream.FirstArrivalTimestamp,
+		LastArrivalTimestamp:  stream.LastArrivalTimestamp,
+		NumValues:             stream.NumValues,
+		IntervalStats:         stream.IntervalStats.Copy(),
+		ValueStats:            stream.ValueStats.Copy(),
+		BaselineStats:         stream.BaselineStats.Copy(),
+		NumOutOfOrder:         stream.NumOutOfOrder,
+		NumDiscarded:          stream.NumDiscarded,
+	}
+}
+
+func serializeWelford(welford *Welford, proto protos.Welford) {
+	proto.SetCount(welford.count)
+	proto.SetMean(welford.mean)
+	proto.SetM2(welford.m2)
+}
+
+func deserializeWelford(proto protos.Welford) *Welford {
+	return NewWelfordFromMoments(proto.Count(), proto.Mean(), proto.M2())
+}
+
+func (stream *StreamStatistics) Serialize(proto *protos.StreamStatistics) error {
+	proto.SetHasArrivals(stream.HasArrivals)
+	proto.SetFirstArrivalTimestamp(stream.FirstArrivalTimestamp)
+	proto.SetLastArrivalTimestamp(stream.LastArrivalTimestamp)
+	proto.SetNumValues(stream.NumValues)
+	proto.SetNumOutOfOrder(stream.NumOutOfOrder)
+	proto.SetNumDiscarded(stream.NumDiscarded)
+
+	intervalProto, err := proto.NewIntervalStats()
+	if err != nil {
+		return err
+	}
+	serializeWelford(stream.IntervalStats, intervalProto)
+
+	valueProto, err := proto.NewValueStats()
+	if err != nil {
+		return err
+	}
+	serializeWelford(stream.ValueStats, valueProto)
+
+	baselineProto, err := proto.NewBaselineStats()
+	if err != nil {
+		return err
+	}
+	serializeWelford(stream.BaselineStats, baselineProto)
+	return nil
+}
+
+func (stream *StreamStatistics) Deserialize(proto *protos.StreamStatistics) error {
+	intervalProto, err := proto.IntervalStats()
+	if err != nil {
+		return err
+	}
+	valueProto, err := proto.ValueStats()
+	if err != nil {
+		return err
+	}
+	// Statistics written without the flag left the timestamp at -1 until
+	// the first arrival.
+	stream.HasArrivals = proto.HasArrivals() || proto.FirstArrivalTimestamp() != -1
+	stream.FirstArrivalTimestamp = proto.FirstArrivalTimestamp()
+	stream.LastArrivalTimestamp = proto.LastArrivalTimestamp()
+	stream.NumValues = proto.NumValues()
+	stream.NumOutOfOrder = proto.NumOutOfOrder()
+	stream.NumDiscarded = proto.NumDiscarded()
+	stream.IntervalStats = deserializeWelford(intervalProto)
+	stream.ValueStats = deserializeWelford(valueProto)
+	// Written before landmark values were left out of the baseline.
+	stream.BaselineStats = stream.ValueStats.Copy()
+	if proto.HasBaselineStats() {
+		baselineProto, err := proto.BaselineStats()
+		if err != nil {
+			return err
+		}
+		stream.BaselineStats = deserializeWelford(baselineProto)
+	}
+	return nil
+}
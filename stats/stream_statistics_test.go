@@ -0,0 +1,75 @@
+package stats
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStreamStatistics_Append(t *testing.T) {
+	statistics := NewStreamStatistics()
+	assert.False(t, statistics.HasArrivals)
+
+	// -1 is a timestamp like any other.
+	statistics.Append(-1, 0)
+	statistics.Append(1, 0)
+	assert.True(t, statistics.HasArrivals)
+	assert.Equal(t, int64(-1), statistics.FirstArrivalTimestamp)
+	assert.Equal(t, 2.0, statistics.IntervalStats.GetMean())
+
+	statistics = NewStreamStatistics()
+	for i := int64(0); i < 10; i++ {
+		statistics.Append(2*i, float64(i))
+	}
+
+	assert.Equal(t, int64(0), statistics.FirstArrivalTimestamp)
+	assert.Equal(t, int64(18), statistics.LastArrivalTimestamp)
+	assert.Equal(t, uint64(10), statistics.NumValues)
+	assert.Equal(t, 2.0, statistics.IntervalStats.GetMean())
+	assert.Equal(t, 0.0, statistics.IntervalStats.GetVariance())
+	assert.InEpsilon(t, 4.5, statistics.ValueStats.GetMean(), 1e-4)
+
+	snapshot := statistics.Copy()
+	statistics.Append(100, 100)
+	assert.Equal(t, uint64(10), snapshot.NumValues)
+	assert.InEpsilon(t, 4.5, snapshot.ValueStats.GetMean(), 1e-4)
+}
+
+func TestStreamStatistics_OutOfOrder(t *testing.T) {
+	statistics := NewStreamStatistics()
+	assert.Equal(t, 0.0, statistics.OutOfOrderRate())
+
+	for i := int64(0); i < 8; i++ {
+		statistics.Append(i, float64(i))
+	}
+	statistics.CountOutOfOrder(false)
+	statistics.CountOutOfOrder(true)
+	statistics.CountOutOfOrder(true)
+	assert.Equal(t, uint64(3), statistics.NumOutOfOrder)
+	assert.Equal(t, uint64(2), statistics.NumDiscarded)
+	assert.Equal(t, 0.3, statistics.OutOfOrderRate())
+	assert.Equal(t, statistics.NumDiscarded, statistics.Copy().NumDiscarded)
+}
+
+func TestStreamStatistics_AppendLate(t *testing.T) {
+	statistics := NewStreamStatistics()
+	statistics.Append(0, 1)
+	statistics.Append(2, 1)
+	statistics.AppendLate(4)
+	assert.Equal(t, uint64(3), statistics.NumValues)
+	assert.Equal(t, int64(2), statistics.LastArrivalTimestamp)
+	assert.Equal(t, 2.0, statistics.IntervalStats.GetMean())
+	assert.Equal(t, 2.0, statistics.ValueStats.GetMean())
+}
+
+func TestStreamStatistics_AppendLandmark(t *testing.T) {
+	statistics := NewStreamStatistics()
+	statistics.Append(0, 1)
+	statistics.Append(1, 3)
+	statistics.AppendLandmark(2, 101)
+	assert.Equal(t, uint64(3), statistics.NumValues)
+	assert.Equal(t, int64(2), statistics.LastArrivalTimestamp)
+	assert.Equal(t, 35.0, statistics.ValueStats.GetMean())
+	assert.Equal(t, uint64(2), statistics.BaselineStats.GetCount())
+	assert.Equal(t, 2.0, statistics.BaselineStats.GetMean())
+	assert.Equal(t, 2.0, statistics.Copy().BaselineStats.GetMean())
+}
@@ -0,0 +1,134 @@
+package stats
+
+import "math"
+
+// StudentTDist is a Student's t-distribution with DF degrees of freedom.
+type StudentTDist struct {
+	DF float64
+}
+
+func (t StudentTDist) PDF(x float64) float64 {
+	v := t.DF
+	lg1, _ := math.Lgamma((v + 1) / 2)
+	lg2, _ := math.Lgamma(v / 2)
+	return math.Exp(lg1-lg2-(v+1)/2*math.Log1p(x*x/v)) / math.Sqrt(v*math.Pi)
+}
+
+func (t StudentTDist) CDF(x float64) float64 {
+	if math.IsInf(t.DF, 1) {
+		return StdNormal.CDF(x)
+	}
+	x2 := x * x
+	if x2 < t.DF {
+		// Near the centre I_{x^2/(v+x^2)}(1/2, v/2) avoids the cancellation
+		// in 1 - I_{v/(v+x^2)}(v/2, 1/2).
+		half := RegularizedIncBeta(0.5, t.DF/2, x2/(t.DF+x2)) / 2
+		if x >= 0 {
+			return 0.5 + half
+		}
+		return 0.5 - half
+	}
+	tail := RegularizedIncBeta(t.DF/2, 0.5, t.DF/(t.DF+x2)) / 2
+	if x >= 0 {
+		return 1 - tail
+	}
+	return tail
+}
+
+func (t StudentTDist) InvCDF(p float64) float64 {
+	if p < 0 || p > 1 {
+		return math.NaN()
+	} else if p == 0 {
+		return math.Inf(-1)
+	} else if p == 1 {
+		return math.Inf(1)
+	} else if math.IsInf(t.DF, 1) {
+		return StdNormal.InvCDF(p)
+	}
+
+	// The CDF is monotonic, so bracket the quantile and bisect.
+	lo, hi := -1.0, 1.0
+	for t.CDF(lo) > p {
+		lo *= 2
+	}
+	for t.CDF(hi) < p {
+		hi *= 2
+	}
+	for i := 0; i < 200 && hi-lo > 1e-12*math.Max(1, math.Abs(lo)); i++ {
+		mid := (lo + hi) / 2
+		if t.CDF(mid) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// RegularizedIncBeta computes I_x(a, b) using the continued fraction
+// expansion from Numerical Recipes (betacf).
+func RegularizedIncBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	} else if x >= 1 {
+		return 1
+	}
+	lga, _ := math.Lgamma(a)
+	lgb, _ := math.Lgamma(b)
+	lgab, _ := math.Lgamma(a + b)
+	front := math.Exp(lgab - lga - lgb + a*math.Log(x) + b*math.Log1p(-x))
+
+	if x < (a+1)/(a+b+2) {
+		return front * incBetaCF(a, b, x) / a
+	}
+	return 1 - front*incBetaCF(b, a, 1-x)/b
+}
+
+func incBetaCF(a, b, x float64) float64 {
+	const (
+		maxIterations = 300
+		eps           = 1e-15
+		tiny          = 1e-300
+	)
+	qab := a + b
+	qap := a + 1
+	qam := a - 1
+	c := 1.0
+	d := 1 - qab*x/qap
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+		m2 := 2 * fm
+		aa := fm * (b - fm) * x / ((qam + m2) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		aa = -(a + fm) * (qab + fm) * x / ((a + m2) * (qap + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < eps {
+			break
+		}
+	}
+	return h
+}
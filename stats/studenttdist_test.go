@@ -0,0 +1,23 @@
+package stats
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestStudentTDist_InvCDF(t *testing.T) {
+	assert.InEpsilon(t, 12.7062, StudentTDist{DF: 1}.InvCDF(0.975), 1e-4)
+	assert.InEpsilon(t, 4.3027, StudentTDist{DF: 2}.InvCDF(0.975), 1e-4)
+	assert.InEpsilon(t, 2.2281, StudentTDist{DF: 10}.InvCDF(0.975), 1e-4)
+	assert.InEpsilon(t, -1.8125, StudentTDist{DF: 10}.InvCDF(0.05), 1e-4)
+	assert.InEpsilon(t, 1.9600, StudentTDist{DF: math.Inf(1)}.InvCDF(0.975), 1e-4)
+	assert.InDelta(t, 0, StudentTDist{DF: 5}.InvCDF(0.5), 1e-9)
+}
+
+func TestStudentTDist_CDF(t *testing.T) {
+	dist := StudentTDist{DF: 4}
+	for _, p := range []float64{0.01, 0.2, 0.5, 0.9, 0.999} {
+		assert.InDelta(t, p, dist.CDF(dist.InvCDF(p)), 1e-9)
+	}
+}
@@ -29,8 +29,37 @@ type Bounds struct {
 type Stats struct {
 	Mean float64
 	Var  float64
+	// Sum of Var_i^2 / DF_i over the independent variance components, the
+	// denominator of the Welch-Satterthwaite equation.
+	VarSquaresOverDF float64
+	// Sum of the squared ranges of the independent components, used by
+	// Hoeffding's inequality.
+	RangeSquares float64
 }
 
+// Effective degrees of freedom of Var using the Welch-Satterthwaite
+// approximation. Infinite when no component carried a finite DF.
+func (stats *Stats) EffectiveDF() float64 {
+	if stats.VarSquaresOverDF <= 0 {
+		return math.Inf(1)
+	}
+	return math.Max(stats.Var*stats.Var/stats.VarSquaresOverDF, 1)
+}
+
+type CIMethod int
+
+const (
+	// Normal approximation, z-score from StdNormal.
+	NormalCI CIMethod = iota
+	// Student-t with the effective degrees of freedom of the estimate.
+	StudentTCI
+	// Distribution-free bound from Chebyshev's inequality.
+	ChebyshevCI
+	// Distribution-free bound from Hoeffding's inequality, using the ranges
+	// of the partially overlapping windows instead of the variance.
+	HoeffdingCI
+)
+
 type CI struct {
 	Mean    float64
 	LowerCI float64
@@ -38,19 +67,39 @@ type CI struct {
 }
 
 func ConvertStatsBoundsToCI(bounds *Bounds, stats *Stats, sdMultiplier, confidenceLevel float64) *CI {
+	return ConvertStatsBoundsToCIWithMethod(bounds, stats, sdMultiplier, confidenceLevel, NormalCI)
+}
+
+func ConvertStatsBoundsToCIWithMethod(bounds *Bounds, stats *Stats,
+	sdMultiplier, confidenceLevel float64, method CIMethod) *CI {
 	ci := &CI{
 		Mean: stats.Mean,
 	}
 	probability := (1 + confidenceLevel) / 2
-	z := StdNormal.InvCDF(probability)
 
-	if math.IsInf(z, 0) {
+	// The half-width of the interval is k * spread.
+	var k, spread float64
+	switch method {
+	case StudentTCI:
+		k = StudentTDist{DF: stats.EffectiveDF()}.InvCDF(probability)
+		spread = sdMultiplier * math.Sqrt(stats.Var)
+	case ChebyshevCI:
+		k = 1 / math.Sqrt(1-confidenceLevel)
+		spread = sdMultiplier * math.Sqrt(stats.Var)
+	case HoeffdingCI:
+		k = math.Sqrt(math.Log(2/(1-confidenceLevel)) / 2)
+		spread = math.Sqrt(stats.RangeSquares)
+	default:
+		k = StdNormal.InvCDF(probability)
+		spread = sdMultiplier * math.Sqrt(stats.Var)
+	}
+
+	if math.IsInf(k, 0) || math.IsNaN(k) {
 		ci.LowerCI = bounds.Lower
 		ci.UpperCI = bounds.Upper
 	} else {
-		sd := sdMultiplier * math.Sqrt(stats.Var)
-		ci.LowerCI = math.Max(ci.Mean-z*sd, bounds.Lower)
-		ci.UpperCI = math.Min(ci.Mean+z*sd, bounds.Upper)
+		ci.LowerCI = math.Max(ci.Mean-k*spread, bounds.Lower)
+		ci.UpperCI = math.Min(ci.Mean+k*spread, bounds.Upper)
 	}
 	return ci
 }
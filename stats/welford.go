@@ -16,6 +16,16 @@ func NewWelford() *Welford {
 	}
 }
 
+// NewWelfordFromMoments rebuilds the running statistics from previously
+// persisted moments.
+func NewWelfordFromMoments(count uint64, mean, m2 float64) *Welford {
+	return &Welford{
+		count: count,
+		mean:  mean,
+		m2:    m2,
+	}
+}
+
 func (welford *Welford) Update(value float64) {
 	welford.count++
 	delta := value - welford.mean
@@ -24,6 +34,14 @@ func (welford *Welford) Update(value float64) {
 	welford.m2 += delta * delta2
 }
 
+func (welford *Welford) GetCount() uint64 {
+	return welford.count
+}
+
+func (welford *Welford) GetM2() float64 {
+	return welford.m2
+}
+
 func (welford *Welford) GetMean() float64 {
 	return welford.mean
 }
@@ -52,3 +70,7 @@ func (welford *Welford) GetCV() float64 {
 	}
 	return welford.GetSD() / welford.GetMean()
 }
+
+func (welford *Welford) Copy() *Welford {
+	return NewWelfordFromMoments(welford.count, welford.mean, welford.m2)
+}
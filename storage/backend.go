@@ -13,6 +13,8 @@ const (
 	Pipeline CompType = iota
 	Writer   CompType = iota
 	Merger   CompType = iota
+	// Count and time at which the latest landmark window ended.
+	Landmark CompType = iota
 )
 
 func TwoInt64ToByte128(a int64, b int64) []byte {
@@ -88,6 +90,15 @@ type Backend interface {
 	MergerBrew(streamID int64, count int64, timestamp int64,
 		pendingMerges []*PendingMergeBuffer,
 		heap []byte, index []byte) error
+	LandmarkBrew(streamID int64, landmarkID int64, landmark []byte,
+		windows map[int64][]byte) error
+	LandmarkFoldBrew(streamID int64, landmarkID int64,
+		windows map[int64][]byte) error
+	TruncateBrew(streamID int64, windowIDs []int64, landmarkIDs []int64,
+		heap []byte, index []byte) error
+
+	// Drops every window and entry of the stream.
+	DeleteStream(streamID int64) error
 }
 
 type InMemoryBackend struct {
@@ -284,3 +295,78 @@ func (backend *InMemoryBackend) MergerBrew(
 	}
 	return backend.PutMergerIndex(streamID, index)
 }
+
+func (backend *InMemoryBackend) LandmarkBrew(
+	streamID int64, landmarkID int64, landmark []byte,
+	windows map[int64][]byte) error {
+	for windowID, window := range windows {
+		err := backend.Put(streamID, windowID, window)
+		if err != nil {
+			return err
+		}
+	}
+	return backend.PutLandmark(streamID, landmarkID, landmark)
+}
+
+func (backend *InMemoryBackend) LandmarkFoldBrew(
+	streamID int64, landmarkID int64,
+	windows map[int64][]byte) error {
+	for windowID, window := range windows {
+		err := backend.Put(streamID, windowID, window)
+		if err != nil {
+			return err
+		}
+	}
+	return backend.DeleteLandmark(streamID, landmarkID)
+}
+
+func (backend *InMemoryBackend) TruncateBrew(
+	streamID int64, windowIDs []int64, landmarkIDs []int64,
+	heap []byte, index []byte) error {
+	for _, windowID := range windowIDs {
+		err := backend.Delete(streamID, windowID)
+		if err != nil {
+			return err
+		}
+	}
+	for _, landmarkID := range landmarkIDs {
+		err := backend.DeleteLandmark(streamID, landmarkID)
+		if err != nil {
+			return err
+		}
+	}
+	err := backend.PutHeap(streamID, heap)
+	if err != nil {
+		return err
+	}
+	return backend.PutMergerIndex(streamID, index)
+}
+
+func (backend *InMemoryBackend) DeleteStream(streamID int64) error {
+	backend.summaryMapMutex.Lock()
+	for k := range backend.summaryMap {
+		if GetStreamIDFromKey([]byte(k)) == streamID {
+			delete(backend.summaryMap, k)
+		}
+	}
+	backend.summaryMapMutex.Unlock()
+
+	backend.landmarkMapMutex.Lock()
+	for k := range backend.landmarkMap {
+		if GetStreamIDFromKey([]byte(k)) == streamID {
+			delete(backend.landmarkMap, k)
+		}
+	}
+	backend.landmarkMapMutex.Unlock()
+
+	delete(backend.heapMap, streamID)
+	delete(backend.mergerIndexMap, streamID)
+
+	backend.countAndTimeMapMutex.Lock()
+	defer backend.countAndTimeMapMutex.Unlock()
+	for compType := Pipeline; compType <= Landmark; compType++ {
+		delete(backend.countAndTimeMap,
+			strconv.Itoa(int(streamID))+strconv.Itoa(int(compType)))
+	}
+	return nil
+}
@@ -261,3 +261,86 @@ func (backend *BadgerBackend) MergerBrew(
 		return nil
 	})
 }
+
+func (backend *BadgerBackend) LandmarkBrew(
+	streamID int64, landmarkID int64, landmark []byte,
+	windows map[int64][]byte) error {
+	lKey := GetKey(true, streamID, landmarkID)
+	return backend.db.Update(func(txn *badger.Txn) error {
+		for windowID, window := range windows {
+			err := txn.Set(GetKey(false, streamID, windowID), window)
+			if err != nil {
+				return err
+			}
+		}
+		return txn.Set(lKey, landmark)
+	})
+}
+
+func (backend *BadgerBackend) LandmarkFoldBrew(
+	streamID int64, landmarkID int64,
+	windows map[int64][]byte) error {
+	lKey := GetKey(true, streamID, landmarkID)
+	return backend.db.Update(func(txn *badger.Txn) error {
+		for windowID, window := range windows {
+			err := txn.Set(GetKey(false, streamID, windowID), window)
+			if err != nil {
+				return err
+			}
+		}
+		return txn.Delete(lKey)
+	})
+}
+
+func (backend *BadgerBackend) TruncateBrew(
+	streamID int64, windowIDs []int64, landmarkIDs []int64,
+	heap []byte, index []byte) error {
+	mKey := GetKey(false, streamID, math.MinInt64+MergerIndexOffset)
+	hKey := GetKey(false, streamID, math.MinInt64+HeapOffset)
+	return backend.db.Update(func(txn *badger.Txn) error {
+		for _, windowID := range windowIDs {
+			err := txn.Delete(GetKey(false, streamID, windowID))
+			if err != nil {
+				return err
+			}
+		}
+		for _, landmarkID := range landmarkIDs {
+			err := txn.Delete(GetKey(true, streamID, landmarkID))
+			if err != nil {
+				return err
+			}
+		}
+		err := txn.Set(mKey, index)
+		if err != nil {
+			return err
+		}
+		return txn.Set(hKey, heap)
+	})
+}
+
+func (backend *BadgerBackend) DeleteStream(streamID int64) error {
+	return backend.db.Update(func(txn *badger.Txn) error {
+		return deleteStreamTxnFunc(txn, streamID)
+	})
+}
+
+// Deletes the summary and landmark windows of the stream, along with its
+// heap, merger index and counts and times, which all share the key prefix.
+func deleteStreamTxnFunc(txn *badger.Txn, streamID int64) error {
+	for _, landmark := range []bool{false, true} {
+		iterOpts := badger.IteratorOptions{Prefix: GetKeyPrefix(landmark, streamID)}
+		iter := txn.NewIterator(iterOpts)
+		keys := make([][]byte, 0)
+		for iter.Seek(nil); iter.Valid(); iter.Next() {
+			keys = append(keys, iter.Item().KeyCopy(nil))
+		}
+		iter.Close()
+		for _, key := range keys {
+			err := txn.Delete(key)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -51,3 +51,77 @@ func TestBadgerBackend_IterateIndex(t *testing.T) {
 	badger := NewBadgerBacked(testConfig)
 	testIterateIndex(t, badger)
 }
+
+func TestBadgerBackend_DeleteStream(t *testing.T) {
+	testConfig := TestBadgerDB()
+	backend := NewBadgerBacked(testConfig)
+	metadataStore := NewBadgerMetadataStore(testConfig)
+
+	for _, streamID := range []int64{1, 2} {
+		assert.NoError(t, backend.Put(streamID, 10, []byte{1}))
+		assert.NoError(t, backend.PutLandmark(streamID, 20, []byte{2}))
+		assert.NoError(t, backend.PutHeap(streamID, []byte{3}))
+		assert.NoError(t, backend.PutCountAndTime(streamID, Writer, 4, 5))
+		assert.NoError(t, metadataStore.PutDBAndStream([]byte{6}, streamID, []byte{7}))
+	}
+
+	err := metadataStore.DeleteDBAndStream([]byte{8}, 1)
+	assert.NoError(t, err)
+	_, err = backend.Get(1, 10)
+	assert.Error(t, err)
+	_, err = backend.GetLandmark(1, 20)
+	assert.Error(t, err)
+	_, err = backend.GetHeap(1)
+	assert.Error(t, err)
+	_, _, err = backend.GetCountAndTime(1, Writer)
+	assert.Error(t, err)
+	_, err = metadataStore.GetStream(1)
+	assert.Error(t, err)
+	dbBuf, err := metadataStore.GetDB()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{8}, dbBuf)
+
+	window, err := backend.Get(2, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1}, window)
+	streamBuf, err := metadataStore.GetStream(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{7}, streamBuf)
+
+	err = backend.DeleteStream(2)
+	assert.NoError(t, err)
+	_, err = backend.Get(2, 10)
+	assert.Error(t, err)
+}
+
+func TestBadgerBackend_Snapshot(t *testing.T) {
+	backend := NewBadgerBacked(TestBadgerDB())
+	assert.NoError(t, backend.Put(1, 10, []byte{1}))
+	assert.NoError(t, backend.PutHeap(1, []byte{2}))
+
+	snapshot := backend.Snapshot()
+	defer snapshot.Discard()
+	// Not seen by the snapshot.
+	assert.NoError(t, backend.Put(1, 11, []byte{3}))
+	assert.NoError(t, backend.Delete(1, 10))
+
+	restored := NewBadgerBacked(TestBadgerDB())
+	loader := restored.NewLoader()
+	numKeys := 0
+	err := snapshot.Iterate(func(key, value []byte) error {
+		numKeys++
+		return loader.Set(key, value)
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, loader.Flush())
+	assert.Equal(t, 2, numKeys)
+
+	window, err := restored.Get(1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1}, window)
+	heap, err := restored.GetHeap(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{2}, heap)
+	_, err = restored.Get(1, 11)
+	assert.Error(t, err)
+}
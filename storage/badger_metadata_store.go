@@ -3,6 +3,7 @@ package storage
 import (
 	"encoding/binary"
 	"github.com/dgraph-io/badger/v2"
+	"math"
 )
 
 const DbKey = "DBKEY"
@@ -26,6 +27,58 @@ func (bms *BadgerMetadataStore) PutDBAndStream(
 	})
 }
 
+func (bms *BadgerMetadataStore) PutStream(streamId int64, streamBuf []byte) error {
+	return bms.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(GetByteKey(streamId), streamBuf)
+	})
+}
+
+func (bms *BadgerMetadataStore) PutDB(dbBuf []byte) error {
+	return bms.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(DbKey), dbBuf)
+	})
+}
+
+func (bms *BadgerMetadataStore) PutStreamMigration(
+	streamId int64, streamBuf []byte,
+	windows map[int64][]byte, heap []byte, index []byte) error {
+	return bms.db.Update(func(txn *badger.Txn) error {
+		for swid, buf := range windows {
+			err := txn.Set(GetKey(false, streamId, swid), buf)
+			if err != nil {
+				return err
+			}
+		}
+		if heap != nil {
+			err := txn.Set(GetKey(false, streamId, math.MinInt64+HeapOffset), heap)
+			if err != nil {
+				return err
+			}
+		}
+		if index != nil {
+			err := txn.Set(GetKey(false, streamId, math.MinInt64+MergerIndexOffset), index)
+			if err != nil {
+				return err
+			}
+		}
+		return txn.Set(GetByteKey(streamId), streamBuf)
+	})
+}
+
+func (bms *BadgerMetadataStore) DeleteDBAndStream(dbBuf []byte, streamId int64) error {
+	return bms.db.Update(func(txn *badger.Txn) error {
+		err := deleteStreamTxnFunc(txn, streamId)
+		if err != nil {
+			return err
+		}
+		err = txn.Delete(GetByteKey(streamId))
+		if err != nil {
+			return err
+		}
+		return txn.Set([]byte(DbKey), dbBuf)
+	})
+}
+
 func (bms *BadgerMetadataStore) GetDB() ([]byte, error) {
 	var dbBytes []byte
 	err := bms.db.View(func(txn *badger.Txn) error {
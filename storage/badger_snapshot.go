@@ -0,0 +1,62 @@
+package storage
+
+import "github.com/dgraph-io/badger/v2"
+
+// BadgerSnapshot is a view of a Badger DB as it was when taken, which can be
+// read while the DB keeps being written to.
+type BadgerSnapshot struct {
+	txn *badger.Txn
+}
+
+func (backend *BadgerBackend) Snapshot() *BadgerSnapshot {
+	return &BadgerSnapshot{txn: backend.db.NewTransaction(false)}
+}
+
+// Iterate calls fn with every key and value of the snapshot, in key order.
+func (snapshot *BadgerSnapshot) Iterate(fn func(key, value []byte) error) error {
+	it := snapshot.txn.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+	for it.Rewind(); it.Valid(); it.Next() {
+		item := it.Item()
+		value, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		err = fn(item.KeyCopy(nil), value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Discard releases the snapshot, once done with it.
+func (snapshot *BadgerSnapshot) Discard() {
+	snapshot.txn.Discard()
+}
+
+// BadgerLoader writes keys and values in batches, e.g. those of a snapshot.
+// It is done with by either Flush or Cancel, not both.
+type BadgerLoader struct {
+	batch *badger.WriteBatch
+}
+
+func (backend *BadgerBackend) NewLoader() *BadgerLoader {
+	return &BadgerLoader{batch: backend.db.NewWriteBatch()}
+}
+
+// Set queues a key and value, which must not be modified afterwards.
+func (loader *BadgerLoader) Set(key, value []byte) error {
+	return loader.batch.Set(key, value)
+}
+
+// Flush writes the queued keys and values, and waits for them to be
+// committed.
+func (loader *BadgerLoader) Flush() error {
+	return loader.batch.Flush()
+}
+
+// Cancel drops the keys and values which are not yet written.
+func (loader *BadgerLoader) Cancel() {
+	loader.batch.Cancel()
+}
@@ -4,22 +4,41 @@ import "errors"
 
 type MetadataStore interface {
 	PutDBAndStream([]byte, int64, []byte) error
+	PutStream(int64, []byte) error
+	PutDB([]byte) error
 	GetDB() ([]byte, error)
 	GetStream(int64) ([]byte, error)
+
+	// Writes the stream along with its re-summarized windows, and the heap
+	// and merger index when not nil, in a single commit.
+	PutStreamMigration(streamID int64, streamBuf []byte,
+		windows map[int64][]byte, heap []byte, index []byte) error
+
+	// Writes the DB and drops the stream, along with all its windows, in a
+	// single commit.
+	DeleteDBAndStream(dbBuf []byte, streamID int64) error
 }
 
 type SimpleMetadataStore struct {
 	db      []byte
 	streams map[int64][]byte
+	backend Backend
 }
 
 func NewSimpleMetadataStore() *SimpleMetadataStore {
 	return &SimpleMetadataStore{
 		db:      nil,
 		streams: make(map[int64][]byte, 0),
+		backend: nil,
 	}
 }
 
+// Backend holding the windows of the streams, written to by migrations.
+func (smm *SimpleMetadataStore) SetBackend(backend Backend) *SimpleMetadataStore {
+	smm.backend = backend
+	return smm
+}
+
 func (smm *SimpleMetadataStore) PutDBAndStream(
 	dbBuf []byte, id int64, streamBuf []byte) error {
 	smm.db = dbBuf
@@ -27,6 +46,56 @@ func (smm *SimpleMetadataStore) PutDBAndStream(
 	return nil
 }
 
+func (smm *SimpleMetadataStore) PutStream(id int64, streamBuf []byte) error {
+	smm.streams[id] = streamBuf
+	return nil
+}
+
+func (smm *SimpleMetadataStore) PutDB(dbBuf []byte) error {
+	smm.db = dbBuf
+	return nil
+}
+
+func (smm *SimpleMetadataStore) PutStreamMigration(
+	id int64, streamBuf []byte,
+	windows map[int64][]byte, heap []byte, index []byte) error {
+	if smm.backend == nil && (len(windows) > 0 || heap != nil || index != nil) {
+		return errors.New("backend not set")
+	}
+	for swid, buf := range windows {
+		err := smm.backend.Put(id, swid, buf)
+		if err != nil {
+			return err
+		}
+	}
+	if heap != nil {
+		err := smm.backend.PutHeap(id, heap)
+		if err != nil {
+			return err
+		}
+	}
+	if index != nil {
+		err := smm.backend.PutMergerIndex(id, index)
+		if err != nil {
+			return err
+		}
+	}
+	smm.streams[id] = streamBuf
+	return nil
+}
+
+func (smm *SimpleMetadataStore) DeleteDBAndStream(dbBuf []byte, id int64) error {
+	if smm.backend != nil {
+		err := smm.backend.DeleteStream(id)
+		if err != nil {
+			return err
+		}
+	}
+	smm.db = dbBuf
+	delete(smm.streams, id)
+	return nil
+}
+
 func (smm *SimpleMetadataStore) GetDB() ([]byte, error) {
 	if smm.db == nil {
 		return nil, errors.New("DB not found")
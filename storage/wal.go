@@ -66,6 +66,10 @@ var (
 	// may be returned when the caller is attempting to remove *all* entries;
 	// The log requires that at least one entry exists following a truncate.
 	ErrOutOfRange = errors.New("out of range")
+
+	// ErrReadOnly is returned when writing to, or truncating, a log opened
+	// with the ReadOnly option.
+	ErrReadOnly = errors.New("log is read-only")
 )
 
 // LogFormat is the format of the log files.
@@ -99,6 +103,16 @@ type Options struct {
 	// option is set, do not modify the returned data because it may affect
 	// other Read calls. Default false
 	NoCopy bool
+	// ReadOnly opens the log for reads only, leaving its files as they are.
+	// Default false
+	ReadOnly bool
+}
+
+// SetNoSync changes the NoSync option of an open log.
+func (l *Log) SetNoSync(noSync bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.opts.NoSync = noSync
 }
 
 // DefaultOptions for Open().
@@ -156,8 +170,10 @@ func OpenLog(path string, opts *Options) (*Log, error) {
 	}
 	l := &Log{path: path, opts: *opts}
 	l.scache.Resize(l.opts.SegmentCacheSize)
-	if err := os.MkdirAll(path, 0777); err != nil {
-		return nil, err
+	if !l.opts.ReadOnly {
+		if err := os.MkdirAll(path, 0777); err != nil {
+			return nil, err
+		}
 	}
 	if err := l.load(); err != nil {
 		return nil, err
@@ -221,6 +237,9 @@ func (l *Log) load() error {
 		})
 		l.firstIndex = 1
 		l.lastIndex = 0
+		if l.opts.ReadOnly {
+			return nil
+		}
 		l.sfile, err = os.Create(l.segments[0].path)
 		return err
 	}
@@ -231,24 +250,26 @@ func (l *Log) load() error {
 			return ErrCorrupt
 		}
 		// Delete all files leading up to START
-		for i := 0; i < startIdx; i++ {
+		for i := 0; i < startIdx && !l.opts.ReadOnly; i++ {
 			if err := os.Remove(l.segments[i].path); err != nil {
 				return err
 			}
 		}
 		l.segments = append([]*segment{}, l.segments[startIdx:]...)
 		// Rename the START segment
-		orgPath := l.segments[0].path
-		finalPath := orgPath[:len(orgPath)-len(".START")]
-		err := os.Rename(orgPath, finalPath)
-		if err != nil {
-			return err
+		if !l.opts.ReadOnly {
+			orgPath := l.segments[0].path
+			finalPath := orgPath[:len(orgPath)-len(".START")]
+			err := os.Rename(orgPath, finalPath)
+			if err != nil {
+				return err
+			}
+			l.segments[0].path = finalPath
 		}
-		l.segments[0].path = finalPath
 	}
 	if endIdx != -1 {
 		// Delete all files following END
-		for i := len(l.segments) - 1; i > endIdx; i-- {
+		for i := len(l.segments) - 1; i > endIdx && !l.opts.ReadOnly; i-- {
 			if err := os.Remove(l.segments[i].path); err != nil {
 				return err
 			}
@@ -262,23 +283,27 @@ func (l *Log) load() error {
 			l.segments = l.segments[:len(l.segments)-1]
 		}
 		// Rename the END segment
-		orgPath := l.segments[len(l.segments)-1].path
-		finalPath := orgPath[:len(orgPath)-len(".END")]
-		err := os.Rename(orgPath, finalPath)
-		if err != nil {
-			return err
+		if !l.opts.ReadOnly {
+			orgPath := l.segments[len(l.segments)-1].path
+			finalPath := orgPath[:len(orgPath)-len(".END")]
+			err := os.Rename(orgPath, finalPath)
+			if err != nil {
+				return err
+			}
+			l.segments[len(l.segments)-1].path = finalPath
 		}
-		l.segments[len(l.segments)-1].path = finalPath
 	}
 	l.firstIndex = l.segments[0].index
 	// Open the last segment for appending
 	lseg := l.segments[len(l.segments)-1]
-	l.sfile, err = os.OpenFile(lseg.path, os.O_WRONLY, 0666)
-	if err != nil {
-		return err
-	}
-	if _, err := l.sfile.Seek(0, 2); err != nil {
-		return err
+	if !l.opts.ReadOnly {
+		l.sfile, err = os.OpenFile(lseg.path, os.O_WRONLY, 0666)
+		if err != nil {
+			return err
+		}
+		if _, err := l.sfile.Seek(0, 2); err != nil {
+			return err
+		}
 	}
 	// Load the last segment entries
 	if err := l.loadSegmentEntries(lseg); err != nil {
@@ -304,11 +329,13 @@ func (l *Log) Close() error {
 		}
 		return ErrClosed
 	}
-	if err := l.sfile.Sync(); err != nil {
-		return err
-	}
-	if err := l.sfile.Close(); err != nil {
-		return err
+	if l.sfile != nil {
+		if err := l.sfile.Sync(); err != nil {
+			return err
+		}
+		if err := l.sfile.Close(); err != nil {
+			return err
+		}
 	}
 	l.closed = true
 	if l.corrupt {
@@ -325,6 +352,8 @@ func (l *Log) Write(index uint64, data []byte) error {
 		return ErrCorrupt
 	} else if l.closed {
 		return ErrClosed
+	} else if l.opts.ReadOnly {
+		return ErrReadOnly
 	}
 	l.wbatch.Clear()
 	l.wbatch.Write(index, data)
@@ -432,6 +461,8 @@ func (l *Log) WriteBatch(b *Batch) error {
 		return ErrCorrupt
 	} else if l.closed {
 		return ErrClosed
+	} else if l.opts.ReadOnly {
+		return ErrReadOnly
 	}
 	if len(b.entries) == 0 {
 		return nil
@@ -717,6 +748,8 @@ func (l *Log) TruncateFront(index uint64) error {
 		return ErrCorrupt
 	} else if l.closed {
 		return ErrClosed
+	} else if l.opts.ReadOnly {
+		return ErrReadOnly
 	}
 	return l.truncateFront(index)
 }
@@ -822,6 +855,8 @@ func (l *Log) TruncateBack(index uint64) error {
 		return ErrCorrupt
 	} else if l.closed {
 		return ErrClosed
+	} else if l.opts.ReadOnly {
+		return ErrReadOnly
 	}
 	return l.truncateBack(index)
 }
@@ -923,6 +958,8 @@ func (l *Log) Sync() error {
 		return ErrCorrupt
 	} else if l.closed {
 		return ErrClosed
+	} else if l.opts.ReadOnly {
+		return nil
 	}
 	return l.sfile.Sync()
 }
@@ -847,3 +847,70 @@ func TestConcurrency(t *testing.T) {
 		t.Fatalf("expected %d reads, but god %d", exp, numReads)
 	}
 }
+
+func TestReadOnly(t *testing.T) {
+	os.RemoveAll("testlog")
+	defer os.RemoveAll("testlog")
+
+	l, err := OpenLog("testlog", &Options{NoSync: true, SegmentSize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(1); i <= 50; i++ {
+		if err := l.Write(i, []byte(fmt.Sprintf("data-%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.TruncateFront(10); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err = OpenLog("testlog", &Options{ReadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := l.FirstIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	last, err := l.LastIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != 10 || last != 50 {
+		t.Fatalf("expected 10-50, got %v-%v", first, last)
+	}
+	for i := first; i <= last; i++ {
+		data, err := l.Read(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != fmt.Sprintf("data-%d", i) {
+			t.Fatalf("expected 'data-%d', got '%s'", i, data)
+		}
+	}
+	if err := l.Write(51, []byte("data-51")); err != ErrReadOnly {
+		t.Fatalf("expected %v, got %v", ErrReadOnly, err)
+	}
+	if err := l.TruncateFront(20); err != ErrReadOnly {
+		t.Fatalf("expected %v, got %v", ErrReadOnly, err)
+	}
+	if err := l.TruncateBack(20); err != ErrReadOnly {
+		t.Fatalf("expected %v, got %v", ErrReadOnly, err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A missing log is not created.
+	os.RemoveAll("testlog")
+	if _, err := OpenLog("testlog", &Options{ReadOnly: true}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, err := os.Stat("testlog"); !os.IsNotExist(err) {
+		t.Fatalf("expected no log, got %v", err)
+	}
+}
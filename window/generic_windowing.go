@@ -103,13 +103,14 @@ func (gwin *GenericWindowing) GetFirstContainingTime(Tl, Tr, T int64) (int64, bo
 	// already in the same window or will be once we move into the next window
 	gwin.addWindowsPastMarker(l)
 	currWindowL, _ := gwin.windowStartMarkersSet.Floor(l)
-	currWindowR, _ := gwin.windowStartMarkersSet.Higher(l)
+	nextWindowL, _ := gwin.windowStartMarkersSet.Higher(l)
+	currWindowR := nextWindowL - 1
 
 	if r <= currWindowR {
 		// already in the same window
 		return T, true
 	} else {
-		// TODO(squadrick): Find example that hits this case
+		// [l, r] straddles the end of the window containing l
 		if currWindowR-currWindowL+1 < length {
 			return 0, false
 		}
@@ -20,8 +20,6 @@ import (
 	"summarydb/tree"
 )
 
-// TODO: This is broken. Fix it.
-
 // S is the fixed size of the starting window
 // Initially, we have R windows each of size S
 // Each consequent step will increase both the size of the window, and number of windows
@@ -43,13 +41,17 @@ import (
 // less performant.
 
 type PowerWindowing struct {
-	p          int64
-	q          int64
-	R          int64
-	S          int64
-	k          int64
+	p int64
+	q int64
+	R int64
+	S int64
+	// Next iteration step to be added
+	k int64
+	// Length and first marker of the windows of the last added step
 	lastLength int64
 	lastMarker int64
+	// Marker past the windows of the last added step
+	nextMarker int64
 	// For each distinct length l = S * k^q, k = 1, 2, 3, ... store
 	// both l-> left marker of the first window of size l and inverse mapping
 	lengthToFirstMarker *tree.RbTree
@@ -62,9 +64,10 @@ func NewPowerWindowing(p, q, R, S int64) *PowerWindowing {
 		q:                   q,
 		R:                   R,
 		S:                   S,
-		k:                   0,
+		k:                   1,
 		lastLength:          0,
 		lastMarker:          0,
+		nextMarker:          0,
 		lengthToFirstMarker: tree.NewRbTree(),
 		firstMarkerToLength: tree.NewRbTree(),
 	}
@@ -76,9 +79,11 @@ func int64Pow(a, b int64) int64 {
 	return int64(math.Pow(float64(a), float64(b)))
 }
 
+// Adds the R * k^(p-1) windows of size S * k^q of step k.
 func (pwin *PowerWindowing) addOne() {
-	pwin.lastLength = pwin.S * int64Pow(pwin.k+1, pwin.q)
-	pwin.lastMarker += pwin.R * int64Pow(pwin.k, pwin.p+pwin.q-1)
+	pwin.lastLength = pwin.S * int64Pow(pwin.k, pwin.q)
+	pwin.lastMarker = pwin.nextMarker
+	pwin.nextMarker += pwin.R * int64Pow(pwin.k, pwin.p-1) * pwin.lastLength
 	pwin.k++
 	pwin.lengthToFirstMarker.Insert(pwin.lastLength, pwin.lastMarker)
 	pwin.firstMarkerToLength.Insert(pwin.lastMarker, pwin.lastLength)
@@ -96,6 +101,8 @@ func (pwin *PowerWindowing) addUntilLength(targetLength int64) {
 	}
 }
 
+// Add steps until the windows of the last step start past the marker. With
+// q = 0 every window has size S, so the first step describes all of them.
 func (pwin *PowerWindowing) addPastMarker(targetMarker int64) {
 	if pwin.q != 0 {
 		for pwin.lastMarker <= targetMarker {
@@ -117,28 +124,27 @@ func (pwin *PowerWindowing) GetFirstContainingTime(Tl, Tr, T int64) (int64, bool
 	}
 
 	pwin.addUntilLength(length)
-	lengthMarker, _ := pwin.lengthToFirstMarker.Ceiling(length)
+	_, firstMarker := pwin.lengthToFirstMarker.Ceiling(length)
+	lengthMarker := firstMarker.(int64)
 	if lengthMarker >= l {
+		// wait until l' == lengthMarker, where l' = T' - 1 - Tr
 		return T + lengthMarker - l, true
 	}
+
 	// We have already hit the target length, so [l, r] is either
 	// already in the same window or will be once we move into next window
 	pwin.addPastMarker(l)
-	targetLength, lengthMarkerValue := pwin.firstMarkerToLength.Floor(l)
-	lengthMarker = lengthMarkerValue.(int64)
+	stepMarker, stepLength := pwin.firstMarkerToLength.Floor(l)
+	stepMarkerValue := int64(stepMarker)
+	stepLengthValue := stepLength.(int64)
 
 	// [Wl, Wr] is the window containing l
-	if targetLength == 0 {
-		// Explicitly handle the case for the first window when
-		// targetLength = 0, to prevent divide by zero error.
-		targetLength = 1
-	}
-	Wl := lengthMarker + (l-lengthMarker)/targetLength
-	Wr := Wl + targetLength - 1
+	Wl := stepMarkerValue + (l-stepMarkerValue)/stepLengthValue*stepLengthValue
+	Wr := Wl + stepLengthValue - 1
 	if r <= Wr {
 		return T, true
 	} else {
-		// TODO(squadrick): Find case that hits this.
+		// need to wait until next window, i.e l' == Wr + 1
 		return T + Wr + 1 - l, true
 	}
 }
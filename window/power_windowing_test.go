@@ -1,7 +1,11 @@
 package window
 
 import (
+	"fmt"
 	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"sort"
+	"summarydb/stats"
 	"testing"
 )
 
@@ -16,7 +20,7 @@ func TestFirstWindow(t *testing.T) {
 		return value
 	}
 
-	assert.Equal(t, int64(131), getTime(119, 123, 130))
+	assert.Equal(t, int64(224), getTime(119, 123, 130))
 }
 
 func TestPowerWindowing_GetFirstContainingTime(t *testing.T) {
@@ -30,11 +34,11 @@ func TestPowerWindowing_GetFirstContainingTime(t *testing.T) {
 		return value
 	}
 
-	assert.Equal(t, int64(104), getTime(98, 99, 100))
-	assert.Equal(t, int64(104), getTime(98, 99, 100))
-	assert.Equal(t, int64(104), getTime(96, 99, 100))
-	assert.Equal(t, int64(109), getTime(92, 99, 100))
-	assert.Equal(t, int64(116), getTime(84, 99, 100))
+	assert.Equal(t, int64(101), getTime(98, 99, 100))
+	assert.Equal(t, int64(101), getTime(98, 99, 100))
+	assert.Equal(t, int64(101), getTime(96, 99, 100))
+	assert.Equal(t, int64(105), getTime(92, 99, 100))
+	assert.Equal(t, int64(114), getTime(84, 99, 100))
 	assert.Equal(t, int64(200), getTime(80, 100, 200))
 }
 
@@ -49,3 +53,69 @@ func TestPowerWindowing_GetWindowsCoveringUpto(t *testing.T) {
 	assert.Equal(t, window.GetWindowsCoveringUpto(62), []int64{3, 3, 12, 12, 12, 12})
 	assert.Equal(t, window.GetWindowsCoveringUpto(100), []int64{3, 3, 12, 12, 12, 12, 27})
 }
+
+// Brute force reference: slide T' forward, one window at a time, until the
+// ages of Tr and Tl fall into the same window of seq.
+func bruteForceFirstContainingTime(seq LengthsSequence, Tl, Tr, T int64) (int64, bool) {
+	if Tr-Tl+1 > seq.MaxWindowSize() {
+		return 0, false
+	}
+	markers := []int64{0}
+	windowOf := func(age int64) int {
+		for markers[len(markers)-1] <= age {
+			markers = append(markers, markers[len(markers)-1]+seq.NextWindowLength())
+		}
+		return sort.Search(len(markers), func(i int) bool {
+			return markers[i] > age
+		})
+	}
+	for Tp := T; ; {
+		// markers[w] is where the window following the one of age l' starts
+		w := windowOf(Tp - 1 - Tr)
+		if w == windowOf(Tp-1-Tl) {
+			return Tp, true
+		}
+		Tp = markers[w] + Tr + 1
+	}
+}
+
+func TestPowerWindowing_MatchesGenericWindowing(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	for trial := 0; trial < 40; trial++ {
+		p := 1 + rng.Int63n(3)
+		q := rng.Int63n(3)
+		R := 1 + rng.Int63n(4)
+		S := 1 + rng.Int63n(4)
+		power := NewPowerWindowing(p, q, R, S)
+		generic := NewGenericWindowing(NewPowerLengthsSequence(p, q, R, S))
+
+		for query := 0; query < 50; query++ {
+			T := 1 + rng.Int63n(2000)
+			Tr := rng.Int63n(T)
+			maxLength := int64(300)
+			if q == 0 {
+				maxLength = 2 * S
+			}
+			Tl := Tr - rng.Int63n(stats.Int64Min(Tr+1, maxLength))
+
+			powerTime, powerOk := power.GetFirstContainingTime(Tl, Tr, T)
+			genericTime, genericOk := generic.GetFirstContainingTime(Tl, Tr, T)
+			bruteTime, bruteOk := bruteForceFirstContainingTime(
+				NewPowerLengthsSequence(p, q, R, S), Tl, Tr, T)
+
+			msg := fmt.Sprintf("p=%d q=%d R=%d S=%d [%d, %d] T=%d", p, q, R, S, Tl, Tr, T)
+			assert.Equal(t, bruteOk, powerOk, msg)
+			assert.Equal(t, bruteOk, genericOk, msg)
+			if bruteOk {
+				assert.Equal(t, bruteTime, powerTime, msg)
+				assert.Equal(t, bruteTime, genericTime, msg)
+			}
+		}
+
+		for query := 0; query < 10; query++ {
+			n := rng.Int63n(5000)
+			assert.Equal(t, generic.GetWindowsCoveringUpto(n),
+				power.GetWindowsCoveringUpto(n))
+		}
+	}
+}
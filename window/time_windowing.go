@@ -0,0 +1,35 @@
+package window
+
+// TimeWindowing decays windows by their age in timestamp units, instead of
+// the number of elements appended after them. The lengths of the wrapped
+// windowing are read as spans of time, which suits streams with irregular
+// arrivals: a burst of elements does not age older windows any faster.
+//
+// Merges are decided on timestamps, so every element starts out in its own
+// window regardless of how it is buffered.
+type TimeWindowing struct {
+	Windowing
+}
+
+func NewTimeWindowing(windowing Windowing) *TimeWindowing {
+	return &TimeWindowing{
+		Windowing: windowing,
+	}
+}
+
+func (twin *TimeWindowing) GetWindowsCoveringUpto(n int64) []int64 {
+	if n <= 0 {
+		return make([]int64, 0)
+	}
+	windows := make([]int64, n)
+	for i := range windows {
+		windows[i] = 1
+	}
+	return windows
+}
+
+// Checks if the windowing decays by time.
+func IsTimeDecayed(windowing Windowing) bool {
+	_, ok := windowing.(*TimeWindowing)
+	return ok
+}
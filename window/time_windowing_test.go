@@ -0,0 +1,22 @@
+package window
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTimeWindowing(t *testing.T) {
+	windowing := NewTimeWindowing(
+		NewGenericWindowing(NewExponentialLengthsSequence(2)))
+
+	assert.True(t, IsTimeDecayed(windowing))
+	assert.False(t, IsTimeDecayed(
+		NewGenericWindowing(NewExponentialLengthsSequence(2))))
+	assert.Equal(t, []int64{1, 1, 1, 1}, windowing.GetWindowsCoveringUpto(4))
+	assert.Empty(t, windowing.GetWindowsCoveringUpto(0))
+
+	// Containment is decided by the wrapped windowing.
+	value, ok := windowing.GetFirstContainingTime(92, 99, 100)
+	assert.True(t, ok)
+	assert.Equal(t, int64(107), value)
+}
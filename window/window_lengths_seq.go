@@ -35,6 +35,12 @@ func DeserializeLengthsSequence(window *protos.Stream_window) (LengthsSequence,
 		seq = NewExponentialLengthsSequence(0)
 	} else if window.Which() == protos.Stream_window_Which_power {
 		seq = NewPowerLengthsSequence(0, 0, 0, 0)
+	} else if window.Which() == protos.Stream_window_Which_uniform {
+		seq = &UniformLengthsSequence{}
+	} else if window.Which() == protos.Stream_window_Which_polynomial {
+		seq = &PolynomialLengthsSequence{k: 1, last: 1}
+	} else if window.Which() == protos.Stream_window_Which_table {
+		seq = &TableLengthsSequence{}
 	} else {
 		return nil, errors.New("unknown length sequence")
 	}
@@ -128,6 +134,10 @@ func (seq *PowerLengthsSequence) NextWindowLength() int64 {
 }
 
 func (seq *PowerLengthsSequence) MaxWindowSize() int64 {
+	if seq.q == 0 {
+		// every window has size S
+		return seq.S
+	}
 	return math.MaxUint32
 }
 
@@ -166,3 +176,292 @@ func (seq *PowerLengthsSequence) Equals(other LengthsSequence) bool {
 		return false
 	}
 }
+
+// length, length, length, ...
+// Windows never decay, for short-retention streams which keep data at a
+// fixed resolution.
+type UniformLengthsSequence struct {
+	length int64
+}
+
+// NewUniformLengthsSequence fails unless length is positive.
+func NewUniformLengthsSequence(length int64) (*UniformLengthsSequence, error) {
+	if length <= 0 {
+		return nil, errors.New("window length must be positive")
+	}
+	return &UniformLengthsSequence{
+		length: length,
+	}, nil
+}
+
+func (seq *UniformLengthsSequence) NextWindowLength() int64 {
+	return seq.length
+}
+
+func (seq *UniformLengthsSequence) MaxWindowSize() int64 {
+	return seq.length
+}
+
+func (seq *UniformLengthsSequence) Serialize(windowProto *protos.Stream_window) error {
+	proto, err := windowProto.NewUniform()
+	if err != nil {
+		return err
+	}
+	proto.SetLength(seq.length)
+	return nil
+}
+
+func (seq *UniformLengthsSequence) Deserialize(windowProto *protos.Stream_window) error {
+	uniformProto, err := windowProto.Uniform()
+	if err != nil {
+		return err
+	}
+	seq.length = uniformProto.Length()
+	return nil
+}
+
+func (seq *UniformLengthsSequence) Equals(other LengthsSequence) bool {
+	switch uniform := other.(type) {
+	case *UniformLengthsSequence:
+		return seq.length == uniform.length
+	default:
+		return false
+	}
+}
+
+// P(1), P(2), ..., P(k), ...
+// where P(k) = c_0 + c_1 * k + ... + c_d * k^d, with the coefficients given
+// lowest order first. Lengths are clamped to be at least 1, and to never
+// decrease.
+type PolynomialLengthsSequence struct {
+	coefficients []int64
+	k            int64
+	last         int64
+}
+
+// NewPolynomialLengthsSequence fails unless there is at least one
+// coefficient, none is negative and not all are 0, i.e. unless every length
+// is positive. The lengths of a non-constant polynomial grow without bound.
+func NewPolynomialLengthsSequence(coefficients []int64) (*PolynomialLengthsSequence, error) {
+	if len(coefficients) == 0 {
+		return nil, errors.New("no polynomial coefficients")
+	}
+	nonZero := false
+	for _, coefficient := range coefficients {
+		if coefficient < 0 {
+			return nil, errors.New("polynomial coefficients must not be negative")
+		}
+		if coefficient != 0 {
+			nonZero = true
+		}
+	}
+	if !nonZero {
+		return nil, errors.New("polynomial coefficients must not all be 0")
+	}
+	return &PolynomialLengthsSequence{
+		coefficients: coefficients,
+		k:            1,
+		last:         1,
+	}, nil
+}
+
+func (seq *PolynomialLengthsSequence) NextWindowLength() int64 {
+	// Horner's method
+	length := int64(0)
+	for i := len(seq.coefficients) - 1; i >= 0; i-- {
+		length = length*seq.k + seq.coefficients[i]
+	}
+	seq.k++
+	if length > seq.last {
+		seq.last = length
+	}
+	return seq.last
+}
+
+func (seq *PolynomialLengthsSequence) MaxWindowSize() int64 {
+	for i := len(seq.coefficients) - 1; i > 0; i-- {
+		if seq.coefficients[i] != 0 {
+			return math.MaxUint32
+		}
+	}
+	// constant polynomial
+	if len(seq.coefficients) == 0 || seq.coefficients[0] < 1 {
+		return 1
+	}
+	return seq.coefficients[0]
+}
+
+func (seq *PolynomialLengthsSequence) Serialize(windowProto *protos.Stream_window) error {
+	proto, err := windowProto.NewPolynomial()
+	if err != nil {
+		return err
+	}
+	coefficients, err := proto.NewCoefficients(int32(len(seq.coefficients)))
+	if err != nil {
+		return err
+	}
+	for i, coefficient := range seq.coefficients {
+		coefficients.Set(i, coefficient)
+	}
+	return nil
+}
+
+func (seq *PolynomialLengthsSequence) Deserialize(windowProto *protos.Stream_window) error {
+	polynomialProto, err := windowProto.Polynomial()
+	if err != nil {
+		return err
+	}
+	coefficients, err := polynomialProto.Coefficients()
+	if err != nil {
+		return err
+	}
+	seq.coefficients = make([]int64, coefficients.Len())
+	for i := range seq.coefficients {
+		seq.coefficients[i] = coefficients.At(i)
+	}
+	return nil
+}
+
+func (seq *PolynomialLengthsSequence) Equals(other LengthsSequence) bool {
+	switch polynomial := other.(type) {
+	case *PolynomialLengthsSequence:
+		if len(seq.coefficients) != len(polynomial.coefficients) {
+			return false
+		}
+		for i := range seq.coefficients {
+			if seq.coefficients[i] != polynomial.coefficients[i] {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// Count windows of the same Length. A Count of 0 repeats the tier forever.
+type LengthsTier struct {
+	Count  int64
+	Length int64
+}
+
+// An explicit table of tiers, e.g. 1000 windows of 1, then 500 windows of
+// 10, then windows of 1000 forever:
+//
+//	{{1000, 1}, {500, 10}, {0, 1000}}
+//
+// Once every tier is used up, the last length repeats.
+type TableLengthsSequence struct {
+	tiers []LengthsTier
+	tier  int
+	curr  int64
+}
+
+// NewTableLengthsSequence fails unless there is at least one tier, every
+// length is positive and doesn't decrease from one tier to the next, and no
+// count is negative.
+func NewTableLengthsSequence(tiers []LengthsTier) (*TableLengthsSequence, error) {
+	if len(tiers) == 0 {
+		return nil, errors.New("no window length tiers")
+	}
+	for i, tier := range tiers {
+		if tier.Length <= 0 {
+			return nil, errors.New("window length must be positive")
+		}
+		if tier.Count < 0 {
+			return nil, errors.New("window count must not be negative")
+		}
+		if i > 0 && tier.Length < tiers[i-1].Length {
+			return nil, errors.New("window lengths must not decrease")
+		}
+	}
+	return &TableLengthsSequence{
+		tiers: tiers,
+		tier:  0,
+		curr:  0,
+	}, nil
+}
+
+func (seq *TableLengthsSequence) NextWindowLength() int64 {
+	if len(seq.tiers) == 0 {
+		return 1
+	}
+	for seq.tier < len(seq.tiers)-1 {
+		count := seq.tiers[seq.tier].Count
+		if count == 0 || seq.curr < count {
+			break
+		}
+		seq.tier++
+		seq.curr = 0
+	}
+	seq.curr++
+	return seq.tiers[seq.tier].Length
+}
+
+func (seq *TableLengthsSequence) MaxWindowSize() int64 {
+	maxLength := int64(1)
+	for _, tier := range seq.tiers {
+		if tier.Length > maxLength {
+			maxLength = tier.Length
+		}
+		if tier.Count == 0 {
+			// tiers past a forever tier are never reached
+			break
+		}
+	}
+	return maxLength
+}
+
+func (seq *TableLengthsSequence) Serialize(windowProto *protos.Stream_window) error {
+	proto, err := windowProto.NewTable()
+	if err != nil {
+		return err
+	}
+	tiers, err := proto.NewTiers(int32(len(seq.tiers)))
+	if err != nil {
+		return err
+	}
+	for i, tier := range seq.tiers {
+		tierProto := tiers.At(i)
+		tierProto.SetCount(tier.Count)
+		tierProto.SetLength(tier.Length)
+	}
+	return nil
+}
+
+func (seq *TableLengthsSequence) Deserialize(windowProto *protos.Stream_window) error {
+	tableProto, err := windowProto.Table()
+	if err != nil {
+		return err
+	}
+	tiers, err := tableProto.Tiers()
+	if err != nil {
+		return err
+	}
+	seq.tiers = make([]LengthsTier, tiers.Len())
+	for i := range seq.tiers {
+		tierProto := tiers.At(i)
+		seq.tiers[i] = LengthsTier{
+			Count:  tierProto.Count(),
+			Length: tierProto.Length(),
+		}
+	}
+	return nil
+}
+
+func (seq *TableLengthsSequence) Equals(other LengthsSequence) bool {
+	switch table := other.(type) {
+	case *TableLengthsSequence:
+		if len(seq.tiers) != len(table.tiers) {
+			return false
+		}
+		for i := range seq.tiers {
+			if seq.tiers[i] != table.tiers[i] {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
@@ -1,7 +1,10 @@
 package window
 
 import (
+	"capnproto.org/go/capnp/v3"
 	"github.com/stretchr/testify/assert"
+	"math"
+	"summarydb/protos"
 	"testing"
 )
 
@@ -12,3 +15,167 @@ func TestPowerLengthsSequence_NextWindowLength(t *testing.T) {
 		assert.Equal(t, int64(i/10), seq.NextWindowLength()-1)
 	}
 }
+
+func TestUniformLengthsSequence_NextWindowLength(t *testing.T) {
+	seq, err := NewUniformLengthsSequence(7)
+	assert.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, int64(7), seq.NextWindowLength())
+	}
+	assert.Equal(t, int64(7), seq.MaxWindowSize())
+}
+
+func TestPolynomialLengthsSequence_NextWindowLength(t *testing.T) {
+	// 1 + k^2
+	seq, err := NewPolynomialLengthsSequence([]int64{1, 0, 1})
+	assert.NoError(t, err)
+	for k := int64(1); k < 100; k++ {
+		assert.Equal(t, 1+k*k, seq.NextWindowLength())
+	}
+	assert.Equal(t, int64(math.MaxUint32), seq.MaxWindowSize())
+
+	seq, err = NewPolynomialLengthsSequence([]int64{4})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), seq.NextWindowLength())
+	assert.Equal(t, int64(4), seq.MaxWindowSize())
+}
+
+func TestTableLengthsSequence_NextWindowLength(t *testing.T) {
+	seq, err := NewTableLengthsSequence([]LengthsTier{{1000, 1}, {500, 10}, {0, 1000}})
+	assert.NoError(t, err)
+
+	for i := 0; i < 1000; i++ {
+		assert.Equal(t, int64(1), seq.NextWindowLength())
+	}
+	for i := 0; i < 500; i++ {
+		assert.Equal(t, int64(10), seq.NextWindowLength())
+	}
+	for i := 0; i < 5000; i++ {
+		assert.Equal(t, int64(1000), seq.NextWindowLength())
+	}
+	assert.Equal(t, int64(1000), seq.MaxWindowSize())
+
+	// The last tier repeats once the table is used up.
+	seq, err = NewTableLengthsSequence([]LengthsTier{{2, 1}, {1, 3}})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 1, 3, 3, 3}, []int64{
+		seq.NextWindowLength(),
+		seq.NextWindowLength(),
+		seq.NextWindowLength(),
+		seq.NextWindowLength(),
+		seq.NextWindowLength(),
+	})
+}
+
+func TestLengthsSequence_Invalid(t *testing.T) {
+	_, err := NewUniformLengthsSequence(0)
+	assert.Error(t, err)
+	_, err = NewUniformLengthsSequence(-4)
+	assert.Error(t, err)
+
+	for _, tiers := range [][]LengthsTier{
+		nil,
+		{{10, 0}},
+		{{10, 1}, {0, -10}},
+		{{-1, 1}, {0, 10}},
+		// Decreasing lengths.
+		{{10, 10}, {0, 1}},
+	} {
+		_, err = NewTableLengthsSequence(tiers)
+		assert.Error(t, err)
+	}
+	// Repeated lengths are fine.
+	_, err = NewTableLengthsSequence([]LengthsTier{{10, 1}, {10, 1}, {0, 10}})
+	assert.NoError(t, err)
+
+	for _, coefficients := range [][]int64{
+		nil,
+		{0, 0},
+		// 5 - 2k drops below 1.
+		{5, -2},
+		{-1, 0, 1},
+	} {
+		_, err = NewPolynomialLengthsSequence(coefficients)
+		assert.Error(t, err)
+	}
+}
+
+func testLengthsSequenceSerialize(t *testing.T, seq LengthsSequence) {
+	_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	assert.NoError(t, err)
+	streamProto, err := protos.NewRootStream(seg)
+	assert.NoError(t, err)
+	windowProto := streamProto.Window()
+	err = seq.Serialize(&windowProto)
+	assert.NoError(t, err)
+
+	newSeq, err := DeserializeLengthsSequence(&windowProto)
+	assert.NoError(t, err)
+	assert.True(t, seq.Equals(newSeq))
+	assert.True(t, newSeq.Equals(seq))
+	for i := 0; i < 50; i++ {
+		assert.Equal(t, seq.NextWindowLength(), newSeq.NextWindowLength())
+	}
+}
+
+func TestLengthsSequence_Serialize(t *testing.T) {
+	uniform, err := NewUniformLengthsSequence(7)
+	assert.NoError(t, err)
+	table, err := NewTableLengthsSequence([]LengthsTier{{10, 1}, {5, 10}, {0, 100}})
+	assert.NoError(t, err)
+	testLengthsSequenceSerialize(t, uniform)
+	polynomial, err := NewPolynomialLengthsSequence([]int64{1, 2, 3})
+	assert.NoError(t, err)
+	testLengthsSequenceSerialize(t, polynomial)
+	testLengthsSequenceSerialize(t, table)
+}
+
+func TestLengthsSequence_Equals(t *testing.T) {
+	newUniform := func(length int64) *UniformLengthsSequence {
+		seq, err := NewUniformLengthsSequence(length)
+		assert.NoError(t, err)
+		return seq
+	}
+	newPolynomial := func(coefficients []int64) *PolynomialLengthsSequence {
+		seq, err := NewPolynomialLengthsSequence(coefficients)
+		assert.NoError(t, err)
+		return seq
+	}
+	newTable := func(tiers []LengthsTier) *TableLengthsSequence {
+		seq, err := NewTableLengthsSequence(tiers)
+		assert.NoError(t, err)
+		return seq
+	}
+
+	uniform := newUniform(7)
+	assert.True(t, uniform.Equals(newUniform(7)))
+	assert.False(t, uniform.Equals(newUniform(8)))
+	assert.False(t, uniform.Equals(newPolynomial([]int64{7})))
+
+	polynomial := newPolynomial([]int64{1, 2})
+	assert.True(t, polynomial.Equals(newPolynomial([]int64{1, 2})))
+	assert.False(t, polynomial.Equals(newPolynomial([]int64{1, 2, 0})))
+	assert.False(t, polynomial.Equals(newPolynomial([]int64{1, 3})))
+
+	table := newTable([]LengthsTier{{10, 1}, {0, 10}})
+	assert.True(t, table.Equals(newTable([]LengthsTier{{10, 1}, {0, 10}})))
+	assert.False(t, table.Equals(newTable([]LengthsTier{{10, 1}, {0, 11}})))
+	assert.False(t, table.Equals(newTable([]LengthsTier{{10, 1}})))
+	assert.False(t, table.Equals(uniform))
+}
+
+func TestUniformLengthsSequence_GenericWindowing(t *testing.T) {
+	seq, err := NewUniformLengthsSequence(4)
+	assert.NoError(t, err)
+	windowing := NewGenericWindowing(seq)
+
+	assert.Equal(t, []int64{4, 4, 4}, windowing.GetWindowsCoveringUpto(14))
+	// [96, 99] lines up with a window once T = 104.
+	value, ok := windowing.GetFirstContainingTime(96, 99, 102)
+	assert.True(t, ok)
+	assert.Equal(t, int64(104), value)
+	// Longer than any window, so never merged.
+	_, ok = windowing.GetFirstContainingTime(90, 99, 100)
+	assert.False(t, ok)
+}
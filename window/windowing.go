@@ -14,3 +14,14 @@ type Windowing interface {
 	// 		first K+1 windows cover > n elements
 	GetWindowsCoveringUpto(n int64) []int64
 }
+
+// Windowing for a sequence. Power sequences use the closed form
+// PowerWindowing, everything else goes through GenericWindowing.
+func NewWindowing(seq LengthsSequence) Windowing {
+	switch power := seq.(type) {
+	case *PowerLengthsSequence:
+		return NewPowerWindowing(power.p, power.q, power.R, power.S)
+	default:
+		return NewGenericWindowing(seq)
+	}
+}